@@ -0,0 +1,288 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const schemaRef = "#/components/schemas/"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// OpenAPI the OpenAPI 3 document of Routes, the schemas are built from the Go types
+func OpenAPI(version string) map[string]interface{} {
+	builder := &schemaBuilder{components: make(map[string]interface{})}
+	builder.schema(reflect.TypeOf(Response{}))
+	builder.schema(reflect.TypeOf(ErrorResponse{}))
+	builder.schema(reflect.TypeOf(AuthError{}))
+
+	paths := make(map[string]interface{})
+	for _, route := range Routes {
+		item, ok := paths[openAPIPath(route.Path)].(map[string]interface{})
+		if !ok {
+			item = make(map[string]interface{})
+			paths[openAPIPath(route.Path)] = item
+		}
+		item[strings.ToLower(route.Method)] = builder.operation(route)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Osmedeus API",
+			"version":     version,
+			"description": "The data of the responses is wrapped in the Response envelope, the failed requests return the ErrorResponse",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": builder.components,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "The JWT of /api/login or the API token",
+				},
+				"osmedeusAuth": map[string]interface{}{
+					"type":        "apiKey",
+					"in":          "header",
+					"name":        "Authorization",
+					"description": "Osmedeus <token>",
+				},
+			},
+		},
+	}
+}
+
+// openAPIPath convert the params of the path, e.g: /jobs/:id -> /jobs/{id}
+func openAPIPath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, ":") {
+			parts[i] = fmt.Sprintf("{%v}", strings.TrimPrefix(part, ":"))
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func (b *schemaBuilder) operation(route Route) map[string]interface{} {
+	operation := map[string]interface{}{
+		"operationId": route.Name,
+		"summary":     route.Summary,
+		"tags":        []string{route.Tag},
+	}
+
+	var parameters []interface{}
+	for _, name := range route.PathParams() {
+		parameters = append(parameters, map[string]interface{}{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+	if route.Query != nil {
+		for _, field := range queryFields(reflect.TypeOf(route.Query)) {
+			parameters = append(parameters, map[string]interface{}{
+				"name":   field.Tag.Get("query"),
+				"in":     "query",
+				"schema": b.fieldSchema(field),
+			})
+		}
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	if route.Request != nil {
+		contentType := "application/json"
+		if route.Form {
+			contentType = "multipart/form-data"
+		}
+		operation["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				contentType: map[string]interface{}{"schema": b.schema(reflect.TypeOf(route.Request))},
+			},
+		}
+	}
+
+	responses := map[string]interface{}{
+		"default": jsonResponse("Error", ref("ErrorResponse")),
+	}
+	switch {
+	case route.Stream == StreamSSE:
+		responses["200"] = map[string]interface{}{
+			"description": "Stream of the events, the id of the event could be sent back as Last-Event-ID",
+			"content": map[string]interface{}{
+				"text/event-stream": map[string]interface{}{"schema": b.schema(reflect.TypeOf(route.Response))},
+			},
+		}
+	case route.Stream == StreamWebSocket:
+		responses["101"] = map[string]interface{}{
+			"description": fmt.Sprintf("Switching to WebSocket, every message is the %v JSON", reflect.TypeOf(route.Response).Name()),
+		}
+	case route.File != "":
+		responses["200"] = map[string]interface{}{
+			"description": "The file",
+			"content": map[string]interface{}{
+				route.File: map[string]interface{}{"schema": map[string]interface{}{"type": "string", "format": "binary"}},
+			},
+		}
+	case route.Raw:
+		responses["200"] = jsonResponse(http.StatusText(http.StatusOK), b.schema(reflect.TypeOf(route.Response)))
+	case route.Response == nil:
+		responses["200"] = jsonResponse(http.StatusText(http.StatusOK), ref("Response"))
+	default:
+		responses["200"] = jsonResponse(http.StatusText(http.StatusOK), map[string]interface{}{
+			"allOf": []interface{}{
+				ref("Response"),
+				map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"data": b.schema(reflect.TypeOf(route.Response)),
+					},
+				},
+			},
+		})
+	}
+
+	if route.Role != "" {
+		operation["security"] = []interface{}{
+			map[string]interface{}{"bearerAuth": []string{}},
+			map[string]interface{}{"osmedeusAuth": []string{}},
+		}
+		operation["x-role"] = route.Role
+		responses["401"] = jsonResponse("Invalid or expired token", ref("AuthError"))
+		responses["403"] = jsonResponse(fmt.Sprintf("The %v role is required", route.Role), ref("ErrorResponse"))
+	}
+	operation["responses"] = responses
+	return operation
+}
+
+func ref(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": schemaRef + name}
+}
+
+func jsonResponse(description string, schema interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": schema},
+		},
+	}
+}
+
+// queryFields the fields of the struct with the query tag
+func queryFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if name := field.Tag.Get("query"); name != "" && name != "-" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// schemaBuilder the named structs are added to the components and referenced
+type schemaBuilder struct {
+	components map[string]interface{}
+}
+
+func (b *schemaBuilder) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		if _, ok := b.components[t.Name()]; !ok {
+			// added first so the recursive types end up as a reference
+			b.components[t.Name()] = map[string]interface{}{}
+			b.components[t.Name()] = b.object(t)
+		}
+		return ref(t.Name())
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return map[string]interface{}{"type": "string", "format": "byte"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return map[string]interface{}{"type": "array", "items": b.schema(t.Elem())}
+	case t.Kind() == reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case t.Kind() == reflect.Interface:
+		return map[string]interface{}{}
+	}
+	return primitiveSchema(t.Kind())
+}
+
+func primitiveSchema(kind reflect.Kind) map[string]interface{} {
+	switch kind {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+	return map[string]interface{}{"type": "string"}
+}
+
+// object the properties of the struct, the embedded structs are flattened like encoding/json does
+func (b *schemaBuilder) object(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	b.properties(t, properties)
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+func (b *schemaBuilder) properties(t reflect.Type, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				b.properties(embedded, properties)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = b.fieldSchema(field)
+	}
+}
+
+// fieldSchema the schema of the field with the enum and the format tags
+func (b *schemaBuilder) fieldSchema(field reflect.StructField) map[string]interface{} {
+	schema := b.schema(field.Type)
+	if _, isRef := schema["$ref"]; isRef {
+		return schema
+	}
+	if enum := field.Tag.Get("enum"); enum != "" {
+		schema["enum"] = strings.Split(enum, ",")
+	}
+	if format := field.Tag.Get("format"); format != "" {
+		schema["format"] = format
+	}
+	return schema
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPI(t *testing.T) {
+	doc := OpenAPI("v1")
+	if _, err := json.Marshal(doc); err != nil {
+		t.Fatalf("Error marshal the document: %v", err)
+	}
+
+	paths := doc["paths"].(map[string]interface{})
+	if len(paths) == 0 {
+		t.Fatalf("Error OpenAPI has no paths")
+	}
+	for path := range paths {
+		if strings.Contains(path, ":") {
+			t.Errorf("Error the params of %v should be converted", path)
+		}
+	}
+	detail, ok := paths["/api/osmp/workspace/{wsname}/"].(map[string]interface{})["get"].(map[string]interface{})
+	if !ok || detail["operationId"] != "getWorkspace" {
+		t.Fatalf("Error OpenAPI missing the workspace detail: %v", detail)
+	}
+	if detail["x-role"] != "viewer" || detail["security"] == nil {
+		t.Errorf("Error the authenticated route should have the security")
+	}
+
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	for _, name := range []string{"Response", "ErrorResponse", "ScanRequest", "WorkspaceDetail", "Scan", "Target", "LeasedJob"} {
+		if _, ok := schemas[name]; !ok {
+			t.Errorf("Error OpenAPI missing the schema %v", name)
+		}
+	}
+	// the embedded job is flattened and the hidden fields are skipped
+	leased := schemas["LeasedJob"].(map[string]interface{})["properties"].(map[string]interface{})
+	if _, ok := leased["inputs"]; !ok {
+		t.Errorf("Error LeasedJob missing its own field")
+	}
+	if _, ok := leased["input"]; !ok {
+		t.Errorf("Error LeasedJob should flatten the embedded job")
+	}
+	user := schemas["User"].(map[string]interface{})["properties"].(map[string]interface{})
+	if _, ok := user["PasswordHash"]; ok {
+		t.Errorf("Error the json:\"-\" fields should be skipped")
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, route := range Routes {
+		key := route.Method + " " + route.Path
+		if seen[key] || seen[route.Name] {
+			t.Errorf("Error duplicated route %v %v", key, route.Name)
+		}
+		seen[key] = true
+		seen[route.Name] = true
+	}
+	if route := FindRoute("post", "/api/osmp/scans/:id/cancel"); route == nil || route.PathParams()[0] != "id" {
+		t.Errorf("Error FindRoute the cancel route: %v", route)
+	}
+}
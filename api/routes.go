@@ -0,0 +1,161 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/whoamikiddie/vulnx/database"
+)
+
+// the kind of the streaming routes
+const (
+	StreamSSE       = "sse"
+	StreamWebSocket = "websocket"
+)
+
+// Route the route of the API server with the types of its request and response
+// the types are the zero values of the structs, they're only read with reflection
+type Route struct {
+	// operation ID of the OpenAPI document
+	Name    string
+	Method  string
+	Path    string
+	Summary string
+	Tag     string
+	// the minimum role of the user, blank means no authentication
+	Role string
+
+	// the struct with the query tags
+	Query interface{}
+	// the JSON body, or the multipart form if Form is true
+	Request interface{}
+	Form    bool
+	// the data field of the Response envelope, blank if there's no data
+	Response interface{}
+	// the response is not wrapped in the Response envelope
+	Raw bool
+	// StreamSSE or StreamWebSocket, the events are ScanEvent
+	Stream string
+	// the response is the file of this content type instead of JSON
+	File string
+}
+
+// Routes every route of /api, the server test checks it against the router
+var Routes = []Route{
+	{Name: "login", Method: "POST", Path: "/api/login", Tag: "auth",
+		Summary: "Login with the username and password, the JWT is valid for 30 days",
+		Request: LoginRequest{}, Response: LoginResponse{}, Raw: true},
+	{Name: "openapi", Method: "GET", Path: "/api/openapi.json", Tag: "mics",
+		Summary: "This document", Response: map[string]interface{}{}, Raw: true},
+
+	{Name: "health", Method: "GET", Path: "/api/osmp/health", Tag: "mics", Role: database.RoleViewer,
+		Summary: "Version of the server", Response: Version{}},
+	{Name: "help", Method: "GET", Path: "/api/osmp/help", Tag: "mics", Role: database.RoleViewer,
+		Summary: "Link to the documentation", Response: Help{}},
+	{Name: "listProcesses", Method: "GET", Path: "/api/osmp/ps", Tag: "mics", Role: database.RoleViewer,
+		Summary: "Processes of the binary running on the server", Response: []Process{}},
+	{Name: "rawFolders", Method: "GET", Path: "/api/osmp/raw", Tag: "mics", Role: database.RoleViewer,
+		Summary: "Static prefixes of the folders served by the server", Response: RawFolders{}},
+	{Name: "listFlows", Method: "GET", Path: "/api/osmp/flows", Tag: "mics", Role: database.RoleViewer,
+		Summary: "Workflows and their modules", Response: []Flow{}},
+
+	{Name: "listWorkspaces", Method: "GET", Path: "/api/osmp/workspaces", Tag: "workspaces", Role: database.RoleViewer,
+		Summary: "Latest scan of every workspace, total is the number of the matching workspaces",
+		Query:   WorkspaceQuery{}, Response: []database.Scan{}},
+	{Name: "getWorkspace", Method: "GET", Path: "/api/osmp/workspace/:wsname/", Tag: "workspaces", Role: database.RoleViewer,
+		Summary: "Latest scan of the workspace and its reports", Response: WorkspaceDetail{}},
+	{Name: "workspaceChanges", Method: "GET", Path: "/api/osmp/workspace/:wsname/changes", Tag: "workspaces", Role: database.RoleViewer,
+		Summary: "Assets appeared or vanished in the workspace, default is the changes of the latest scan",
+		Query:   ChangesQuery{}, Response: database.Changes{}},
+	{Name: "workspaceArchive", Method: "GET", Path: "/api/osmp/workspace/:wsname/archive", Tag: "workspaces", Role: database.RoleViewer,
+		Summary: "Download the workspace folder as the tar.gz archive", File: "application/gzip"},
+	{Name: "listScans", Method: "GET", Path: "/api/osmp/scans", Tag: "workspaces", Role: database.RoleViewer,
+		Summary: "Progress of the scans, the target is left out", Query: WorkspaceQuery{}, Response: []database.Scan{}},
+	{Name: "deleteWorkspace", Method: "DELETE", Path: "/api/osmp/delete/:wsname/", Tag: "workspaces", Role: database.RoleAdmin,
+		Summary: "Delete the workspace folder"},
+
+	{Name: "me", Method: "GET", Path: "/api/osmp/me", Tag: "users", Role: database.RoleViewer,
+		Summary: "User of the request", Response: AuthUser{}},
+	{Name: "listTokens", Method: "GET", Path: "/api/osmp/tokens", Tag: "users", Role: database.RoleViewer,
+		Summary: "API tokens of the user, the admin could list all of them", Query: TokenQuery{}, Response: []database.APIToken{}},
+	{Name: "createToken", Method: "POST", Path: "/api/osmp/tokens", Tag: "users", Role: database.RoleViewer,
+		Summary: "Create the API token, the raw token is only sent once", Request: TokenRequest{}, Response: TokenCreated{}},
+	{Name: "revokeToken", Method: "DELETE", Path: "/api/osmp/tokens/:id", Tag: "users", Role: database.RoleViewer,
+		Summary: "Revoke the API token, the admin could revoke any token", Response: ""},
+	{Name: "listUsers", Method: "GET", Path: "/api/osmp/users", Tag: "users", Role: database.RoleAdmin,
+		Summary: "List the users", Response: []database.User{}},
+	{Name: "addUser", Method: "POST", Path: "/api/osmp/users", Tag: "users", Role: database.RoleAdmin,
+		Summary: "Create the user", Request: UserRequest{}, Response: database.User{}},
+	{Name: "updateUser", Method: "PUT", Path: "/api/osmp/users/:username", Tag: "users", Role: database.RoleAdmin,
+		Summary: "Update the password, the role or the status of the user", Request: UserRequest{}, Response: database.User{}},
+	{Name: "deleteUser", Method: "DELETE", Path: "/api/osmp/users/:username", Tag: "users", Role: database.RoleAdmin,
+		Summary: "Delete the user and its API tokens", Response: ""},
+	{Name: "listAudit", Method: "GET", Path: "/api/osmp/audit", Tag: "users", Role: database.RoleAdmin,
+		Summary: "Audit log of the API and CLI actions, the newest first", Query: AuditQuery{}, Response: []database.AuditLog{}},
+
+	{Name: "newScan", Method: "POST", Path: "/api/osmp/execute", Tag: "scans", Role: database.RoleOperator,
+		Summary: "Add the scan to the queue, the job is validated but not queued when test is true",
+		Request: ScanRequest{}, Response: ScanQueued{}},
+	{Name: "upload", Method: "POST", Path: "/api/osmp/upload", Tag: "scans", Role: database.RoleOperator,
+		Summary: "Upload the list of targets, the file could be used as the targets_file of the scan",
+		Request: UploadRequest{}, Response: UploadResult{}},
+	{Name: "cancelScan", Method: "POST", Path: "/api/osmp/scans/:id/cancel", Tag: "scans", Role: database.RoleOperator,
+		Summary: "Cancel the running scan, the id could be the workspace or the scan ID", Response: database.Scan{}},
+	{Name: "pauseScan", Method: "POST", Path: "/api/osmp/scans/:id/pause", Tag: "scans", Role: database.RoleOperator,
+		Summary: "Pause the running scan", Response: database.Scan{}},
+	{Name: "resumeScan", Method: "POST", Path: "/api/osmp/scans/:id/resume", Tag: "scans", Role: database.RoleOperator,
+		Summary: "Resume the paused scan", Response: database.Scan{}},
+	{Name: "streamScan", Method: "GET", Path: "/api/osmp/scans/:id/stream", Tag: "scans", Role: database.RoleViewer,
+		Summary: "Live events of the scan with Server-Sent Events, the backlog is replayed first",
+		Query:   StreamQuery{}, Response: ScanEvent{}, Stream: StreamSSE},
+	{Name: "streamScanWS", Method: "GET", Path: "/api/osmp/scans/:id/ws", Tag: "scans", Role: database.RoleViewer,
+		Summary: "Live events of the scan over WebSocket, one JSON event per message",
+		Query:   StreamQuery{}, Response: ScanEvent{}, Stream: StreamWebSocket},
+
+	{Name: "listJobs", Method: "GET", Path: "/api/osmp/jobs", Tag: "jobs", Role: database.RoleViewer,
+		Summary: "Jobs of the queue", Query: JobQuery{}, Response: []database.Job{}},
+	{Name: "getJob", Method: "GET", Path: "/api/osmp/jobs/:id", Tag: "jobs", Role: database.RoleViewer,
+		Summary: "Job of the queue", Response: database.Job{}},
+	{Name: "leaseJob", Method: "POST", Path: "/api/osmp/jobs/lease", Tag: "jobs", Role: database.RoleOperator,
+		Summary: "Lease the next pending job to the remote worker, data is null if the queue is empty",
+		Request: WorkerRequest{}, Response: LeasedJob{}},
+	{Name: "heartbeatJob", Method: "POST", Path: "/api/osmp/jobs/:id/heartbeat", Tag: "jobs", Role: database.RoleOperator,
+		Summary: "Extend the lease of the job, 409 means the job is no longer leased to the worker", Request: WorkerRequest{}},
+	{Name: "finishJob", Method: "POST", Path: "/api/osmp/jobs/:id/finish", Tag: "jobs", Role: database.RoleOperator,
+		Summary: "Mark the job as done, or failed if the error is set", Request: WorkerRequest{}},
+	{Name: "uploadWorkspace", Method: "POST", Path: "/api/osmp/jobs/:id/upload", Tag: "jobs", Role: database.RoleOperator,
+		Summary: "Upload the workspace archive of the job, it's imported to the database",
+		Request: WorkspaceUploadForm{}, Form: true, Response: WorkspaceUploaded{}},
+
+	{Name: "listSchedules", Method: "GET", Path: "/api/osmp/schedules", Tag: "schedules", Role: database.RoleViewer,
+		Summary: "Cron schedules", Response: []database.Schedule{}},
+	{Name: "addSchedule", Method: "POST", Path: "/api/osmp/schedules", Tag: "schedules", Role: database.RoleOperator,
+		Summary: "Create the schedule, only its settings are read from the body",
+		Request: database.Schedule{}, Response: database.Schedule{}},
+	{Name: "pauseSchedule", Method: "POST", Path: "/api/osmp/schedules/:name/pause", Tag: "schedules", Role: database.RoleOperator,
+		Summary: "Pause the schedule", Response: database.Schedule{}},
+	{Name: "resumeSchedule", Method: "POST", Path: "/api/osmp/schedules/:name/resume", Tag: "schedules", Role: database.RoleOperator,
+		Summary: "Resume the schedule", Response: database.Schedule{}},
+	{Name: "deleteSchedule", Method: "DELETE", Path: "/api/osmp/schedules/:name", Tag: "schedules", Role: database.RoleOperator,
+		Summary: "Delete the schedule"},
+}
+
+// FindRoute the route of the method and the path, the path is the pattern like /api/osmp/jobs/:id
+func FindRoute(method string, path string) *Route {
+	for i := range Routes {
+		if Routes[i].Method == strings.ToUpper(method) && Routes[i].Path == path {
+			return &Routes[i]
+		}
+	}
+	return nil
+}
+
+// PathParams the names of the params of the path, e.g: /scans/:id/cancel -> id
+func (r Route) PathParams() []string {
+	var params []string
+	for _, part := range strings.Split(r.Path, "/") {
+		if strings.HasPrefix(part, ":") {
+			params = append(params, strings.TrimPrefix(part, ":"))
+		}
+	}
+	return params
+}
@@ -0,0 +1,287 @@
+package api
+
+import (
+	"time"
+
+	"github.com/whoamikiddie/vulnx/database"
+)
+
+// Response the envelope of the responses of /api/osmp, the type of Data depends on the route
+type Response struct {
+	Status  int         `json:"status"`
+	Data    interface{} `json:"data"`
+	Type    string      `json:"type,omitempty"`
+	Total   int         `json:"total,omitempty"`
+	Message string      `json:"message"`
+}
+
+// ErrorResponse the body of the failed request
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// AuthError the body of the request rejected by the authentication
+type AuthError struct {
+	Status  string      `json:"status"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data"`
+}
+
+// LoginRequest the credentials of the user
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// LoginResponse the JWT of the user, it's sent as "Authorization: Osmedeus <token>"
+type LoginResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Token   string `json:"token"`
+	Role    string `json:"role"`
+}
+
+// AuthUser the user of the request
+type AuthUser struct {
+	ID       uint   `json:"id,omitempty"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+// Version the version of the server
+type Version struct {
+	Version string `json:"version"`
+}
+
+// Help the link to the documentation
+type Help struct {
+	Version string `json:"version"`
+	Doc     string `json:"doc"`
+	Message string `json:"message"`
+}
+
+// RawFolders the static prefixes of the folders served by the server
+type RawFolders struct {
+	Storages   string `json:"storages"`
+	Workspaces string `json:"workspaces"`
+	Logs       string `json:"logs"`
+}
+
+// Process the process of the binary running on the server
+type Process struct {
+	PID     int    `json:"pid"`
+	Command string `json:"command"`
+}
+
+// Flow the workflow and its modules
+type Flow struct {
+	Name string `json:"name"`
+	Desc string `json:"desc"`
+	// comma separated list of the modules
+	Modules string `json:"modules"`
+}
+
+// WorkspaceQuery the filters, the sorting and the page of the workspaces and scans
+type WorkspaceQuery struct {
+	Flow   string `query:"flow" json:"flow,omitempty"`
+	Status string `query:"status" json:"status,omitempty" enum:"pending,running,paused,finished,failed,cancelled"`
+	Search string `query:"q" json:"q,omitempty"`
+	Sort   string `query:"sort" json:"sort,omitempty" enum:"date,name,status,findings"`
+	Order  string `query:"order" json:"order,omitempty" enum:"asc,desc"`
+	Page   int    `query:"page" json:"page,omitempty"`
+	Offset int    `query:"offset" json:"offset,omitempty"`
+	Limit  int    `query:"limit" json:"limit,omitempty"`
+}
+
+// WorkspaceDetail the latest scan of the workspace and its reports per module
+type WorkspaceDetail struct {
+	Workspace database.Scan       `json:"workspace"`
+	Reports   map[string][]string `json:"reports"`
+}
+
+// ChangesQuery the time range of the changes, a date or a relative time like 7d
+type ChangesQuery struct {
+	Since string `query:"since" json:"since,omitempty"`
+	Until string `query:"until" json:"until,omitempty"`
+}
+
+// ScanRequest the scan to add to the queue
+type ScanRequest struct {
+	MasterPassword string `json:"password"`
+	// override everything below, only used by the cloud scan
+	Command string `json:"command"`
+
+	// these two not be blank when run with plugins
+	WorkFlow   string `json:"workflow"`
+	PluginName string `json:"plugin"`
+	ScanID     string `json:"scan_id"`
+
+	// for select scan + task
+	Workspace   string   `json:"workspace"`
+	Target      string   `json:"target"`
+	TargetsList []string `json:"targets"`
+	TargetsFile string   `json:"targets_file"`
+
+	AliveAssets bool `json:"alive_assets"` // skip targets part and select the assets from DB
+	AllAssets   bool `json:"all_assets"`   // skip targets part and select the assets from DB
+
+	// just more mics info for custom command later
+	Params      []string `json:"params"`
+	Timeout     string   `json:"timeout"`
+	Concurrency int      `json:"concurrency"`
+
+	// enable distributed scan
+	Distributed bool `json:"distributed"`
+
+	// for chunk mode only
+	Threads      int  `json:"threads"`
+	Chunk        bool `json:"chunk"`
+	TargetAsFile bool `json:"as_file"`
+
+	// only select record not run the command
+	RawName  bool `json:"RawName"`
+	WildCard bool `json:"wildcard"`
+	ViewOnly bool `json:"view_only"`
+	Debug    bool `json:"debug"`
+	Test     bool `json:"test"`
+
+	// the summary is posted to the callback URL when the scan is finished or failed
+	CallbackURL    string `json:"callback_url"`
+	CallbackSecret string `json:"callback_secret"`
+}
+
+// ScanQueued the job of the new scan
+type ScanQueued struct {
+	JobID     uint   `json:"job_id"`
+	State     string `json:"state"`
+	Input     string `json:"input"`
+	Workspace string `json:"workspace"`
+}
+
+// UploadRequest the list of targets to upload, the lines are separated by \n
+type UploadRequest struct {
+	Data     string `json:"data"`
+	Filename string `json:"filename"`
+}
+
+// UploadResult the file on the server, it could be used as the targets_file of the scan
+type UploadResult struct {
+	FilePath string `json:"filepath"`
+}
+
+// JobQuery the filters of the jobs
+type JobQuery struct {
+	// comma separated list of the states
+	State string `query:"state" json:"state,omitempty"`
+	Limit int    `query:"limit" json:"limit,omitempty"`
+}
+
+// WorkerRequest the body sent by the remote worker
+type WorkerRequest struct {
+	Worker   string `json:"worker"`
+	Hostname string `json:"hostname"`
+	// lease in seconds
+	Lease int    `json:"lease"`
+	Error string `json:"error"`
+}
+
+// LeasedJob the job sent to the remote worker
+// the lines of the input file and the callback secret are sent along since the worker can't read them from the server
+type LeasedJob struct {
+	database.Job
+	Inputs         []string `json:"inputs,omitempty"`
+	CallbackSecret string   `json:"callback_secret,omitempty"`
+}
+
+// WorkspaceUploadForm the multipart form of the workspace archive sent by the worker
+type WorkspaceUploadForm struct {
+	Worker    string `form:"worker" json:"worker"`
+	Workspace string `form:"workspace" json:"workspace"`
+	Archive   string `form:"archive" json:"archive" format:"binary"`
+}
+
+// WorkspaceUploaded the workspace imported from the archive
+type WorkspaceUploaded struct {
+	Workspace string `json:"workspace"`
+	Imported  int    `json:"imported"`
+}
+
+// UserRequest the body to create or update the user, the empty fields are kept on update
+type UserRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Role     string `json:"role" enum:"viewer,operator,admin"`
+	Disabled *bool  `json:"disabled"`
+}
+
+// TokenRequest the API token to create
+type TokenRequest struct {
+	Name string `json:"name"`
+	// number of days before the token expires, it never expires if it's zero
+	TTL int `json:"ttl"`
+}
+
+// TokenCreated the raw token is only sent once
+type TokenCreated struct {
+	Token string            `json:"token"`
+	Info  database.APIToken `json:"info"`
+}
+
+// TokenQuery the admin could list the tokens of all the users
+type TokenQuery struct {
+	All bool `query:"all" json:"all,omitempty"`
+}
+
+// AuditQuery the filters of the audit log, the time could be RFC3339 or a duration like 24h
+type AuditQuery struct {
+	Since  string `query:"since" json:"since,omitempty"`
+	Until  string `query:"until" json:"until,omitempty"`
+	Actor  string `query:"actor" json:"actor,omitempty"`
+	Action string `query:"action" json:"action,omitempty"`
+	Limit  int    `query:"limit" json:"limit,omitempty"`
+	Offset int    `query:"offset" json:"offset,omitempty"`
+}
+
+// StreamQuery the events after this ID are sent, the Last-Event-ID header does the same
+type StreamQuery struct {
+	Since int `query:"since" json:"since,omitempty"`
+	// only the events already written are sent, then the stream is closed
+	Backlog bool `query:"backlog" json:"backlog,omitempty"`
+}
+
+// the type of the scan events
+const (
+	EventLog      = "log"
+	EventModule   = "module"
+	EventStep     = "step"
+	EventProgress = "progress"
+	EventState    = "state"
+)
+
+// the states of the scan sent with the state event
+const (
+	StateRunning   = "running"
+	StatePaused    = "paused"
+	StateResumed   = "resumed"
+	StateCancelled = "cancelled"
+	StateDone      = "done"
+	StateFailed    = "failed"
+)
+
+// ScanEvent the event of the scan, they're appended to the events file of the workspace
+type ScanEvent struct {
+	ID         int       `json:"id"`
+	Time       time.Time `json:"time"`
+	Type       string    `json:"type"`
+	Message    string    `json:"message,omitempty"`
+	Module     string    `json:"module,omitempty"`
+	Step       string    `json:"step,omitempty"`
+	State      string    `json:"state,omitempty"`
+	DoneStep   int       `json:"done_step,omitempty"`
+	TotalSteps int       `json:"total_steps,omitempty"`
+}
+
+// IsFinal the scan won't send any event after this one
+func (e ScanEvent) IsFinal() bool {
+	return e.Type == EventState && (e.State == StateDone || e.State == StateFailed || e.State == StateCancelled)
+}
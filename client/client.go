@@ -0,0 +1,501 @@
+package client
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/spf13/cast"
+	"github.com/whoamikiddie/vulnx/api"
+	"github.com/whoamikiddie/vulnx/database"
+)
+
+// defaultTimeout the timeout of the requests, the streams and the uploads don't use it
+const defaultTimeout = 60 * time.Second
+
+// Client the client of the API server, the routes and the types are in the api package
+type Client struct {
+	// e.g: https://127.0.0.1:8000
+	URL string
+	// the JWT of Login or the API token
+	Token string
+
+	HTTP *http.Client
+	// used by the streams and the uploads
+	LongHTTP *http.Client
+}
+
+// APIError the server refused the request
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%v: %v", e.StatusCode, e.Message)
+}
+
+// IsStatus the error is the APIError with the status code
+func IsStatus(err error, statusCode int) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode == statusCode
+}
+
+// New create the client of the server, insecure skips the verification of the certificate
+// the server uses the self-signed certificate by default
+func New(baseURL string, token string, insecure bool) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &Client{
+		URL:      strings.TrimSuffix(baseURL, "/"),
+		Token:    token,
+		HTTP:     &http.Client{Transport: transport, Timeout: defaultTimeout},
+		LongHTTP: &http.Client{Transport: transport},
+	}
+}
+
+// request build the request of the route, the path params are replaced in order
+func (c *Client) request(method string, route string, query interface{}, body io.Reader, params ...interface{}) (*http.Request, error) {
+	for _, param := range params {
+		idx := strings.Index(route, "/:")
+		if idx < 0 {
+			return nil, fmt.Errorf("too many params for %v", route)
+		}
+		end := strings.Index(route[idx+1:], "/")
+		rest := ""
+		if end >= 0 {
+			rest = route[idx+1+end:]
+		}
+		route = route[:idx+1] + url.PathEscape(cast.ToString(param)) + rest
+	}
+
+	u := c.URL + route
+	if values := encodeQuery(query); len(values) > 0 {
+		u += "?" + values.Encode()
+	}
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Osmedeus "+c.Token)
+	}
+	return req, nil
+}
+
+// call send the JSON body and decode the data of the response into data
+func (c *Client) call(method string, route string, query interface{}, body interface{}, data interface{}, params ...interface{}) (*api.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		raw, err := jsoniter.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req, err := c.request(method, route, query, reader, params...)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	response := &api.Response{Data: data}
+	if err := c.do(c.HTTP, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// do send the request and decode the body into out, the error body is turned into APIError
+func (c *Client) do(httpClient *http.Client, req *http.Request, out interface{}) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return responseError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := jsoniter.NewDecoder(resp.Body).Decode(out); err != nil && err != io.EOF {
+		return fmt.Errorf("error decoding the response of %v: %v", req.URL.Path, err)
+	}
+	return nil
+}
+
+// responseError the message of ErrorResponse or AuthError, the status text otherwise
+func responseError(resp *http.Response) error {
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	var body struct {
+		api.ErrorResponse
+		Message string `json:"message"`
+	}
+	message := http.StatusText(resp.StatusCode)
+	if jsoniter.Unmarshal(raw, &body) == nil {
+		if body.Error != "" {
+			message = body.Error
+		} else if body.Message != "" {
+			message = body.Message
+		}
+	}
+	return &APIError{StatusCode: resp.StatusCode, Message: message}
+}
+
+// encodeQuery the non-zero fields of the struct with the query tags
+func encodeQuery(query interface{}) url.Values {
+	values := url.Values{}
+	if query == nil {
+		return values
+	}
+	v := reflect.Indirect(reflect.ValueOf(query))
+	if v.Kind() != reflect.Struct {
+		return values
+	}
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Tag.Get("query")
+		if name == "" || name == "-" || v.Field(i).IsZero() {
+			continue
+		}
+		values.Set(name, cast.ToString(v.Field(i).Interface()))
+	}
+	return values
+}
+
+// Login get the JWT of the user, it's used by the next requests
+func (c *Client) Login(username string, password string) (*api.LoginResponse, error) {
+	raw, err := jsoniter.Marshal(api.LoginRequest{Username: username, Password: password})
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.request(http.MethodPost, "/api/login", nil, bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Del("Authorization")
+
+	var login api.LoginResponse
+	if err := c.do(c.HTTP, req, &login); err != nil {
+		return nil, err
+	}
+	c.Token = login.Token
+	return &login, nil
+}
+
+// OpenAPI the OpenAPI document of the server
+func (c *Client) OpenAPI() (map[string]interface{}, error) {
+	req, err := c.request(http.MethodGet, "/api/openapi.json", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	doc := make(map[string]interface{})
+	return doc, c.do(c.HTTP, req, &doc)
+}
+
+// Health the version of the server
+func (c *Client) Health() (*api.Version, error) {
+	var version api.Version
+	_, err := c.call(http.MethodGet, "/api/osmp/health", nil, nil, &version)
+	return &version, err
+}
+
+// Help the link to the documentation
+func (c *Client) Help() (*api.Help, error) {
+	var help api.Help
+	_, err := c.call(http.MethodGet, "/api/osmp/help", nil, nil, &help)
+	return &help, err
+}
+
+// Processes the processes of the binary running on the server
+func (c *Client) Processes() ([]api.Process, error) {
+	var processes []api.Process
+	_, err := c.call(http.MethodGet, "/api/osmp/ps", nil, nil, &processes)
+	return processes, err
+}
+
+// RawFolders the static prefixes of the folders served by the server
+func (c *Client) RawFolders() (*api.RawFolders, error) {
+	var folders api.RawFolders
+	_, err := c.call(http.MethodGet, "/api/osmp/raw", nil, nil, &folders)
+	return &folders, err
+}
+
+// Flows the workflows and their modules
+func (c *Client) Flows() ([]api.Flow, error) {
+	var flows []api.Flow
+	_, err := c.call(http.MethodGet, "/api/osmp/flows", nil, nil, &flows)
+	return flows, err
+}
+
+// Workspaces the latest scan of the workspaces matching the query, the total is counted before the pagination
+func (c *Client) Workspaces(query api.WorkspaceQuery) ([]database.Scan, int, error) {
+	var scans []database.Scan
+	response, err := c.call(http.MethodGet, "/api/osmp/workspaces", query, nil, &scans)
+	if err != nil {
+		return nil, 0, err
+	}
+	return scans, response.Total, nil
+}
+
+// Workspace the latest scan of the workspace and its reports
+func (c *Client) Workspace(name string) (*api.WorkspaceDetail, error) {
+	var detail api.WorkspaceDetail
+	_, err := c.call(http.MethodGet, "/api/osmp/workspace/:wsname/", nil, nil, &detail, name)
+	return &detail, err
+}
+
+// WorkspaceChanges the assets appeared or vanished in the workspace
+func (c *Client) WorkspaceChanges(name string, query api.ChangesQuery) (*database.Changes, error) {
+	var changes database.Changes
+	_, err := c.call(http.MethodGet, "/api/osmp/workspace/:wsname/changes", query, nil, &changes, name)
+	return &changes, err
+}
+
+// Scans the progress of the scans matching the query, the target is left out
+func (c *Client) Scans(query api.WorkspaceQuery) ([]database.Scan, int, error) {
+	var scans []database.Scan
+	response, err := c.call(http.MethodGet, "/api/osmp/scans", query, nil, &scans)
+	if err != nil {
+		return nil, 0, err
+	}
+	return scans, response.Total, nil
+}
+
+// DownloadWorkspace write the tar.gz archive of the workspace to w
+func (c *Client) DownloadWorkspace(name string, w io.Writer) (int64, error) {
+	req, err := c.request(http.MethodGet, "/api/osmp/workspace/:wsname/archive", nil, nil, name)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.LongHTTP.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return 0, responseError(resp)
+	}
+	return io.Copy(w, resp.Body)
+}
+
+// DeleteWorkspace delete the workspace folder on the server
+func (c *Client) DeleteWorkspace(name string) error {
+	_, err := c.call(http.MethodDelete, "/api/osmp/delete/:wsname/", nil, nil, nil, name)
+	return err
+}
+
+// NewScan add the scan to the queue of the server
+func (c *Client) NewScan(scan api.ScanRequest) (*api.ScanQueued, error) {
+	scan.Test = false
+	var queued api.ScanQueued
+	_, err := c.call(http.MethodPost, "/api/osmp/execute", nil, scan, &queued)
+	return &queued, err
+}
+
+// ValidateScan the job the server would queue for the scan, nothing is queued
+func (c *Client) ValidateScan(scan api.ScanRequest) (*database.Job, error) {
+	scan.Test = true
+	var job database.Job
+	_, err := c.call(http.MethodPost, "/api/osmp/execute", nil, scan, &job)
+	return &job, err
+}
+
+// Upload save the targets on the server, the file could be used as the targets file of the scan
+func (c *Client) Upload(upload api.UploadRequest) (*api.UploadResult, error) {
+	var result api.UploadResult
+	_, err := c.call(http.MethodPost, "/api/osmp/upload", nil, upload, &result)
+	return &result, err
+}
+
+// ControlScan send core.ScanCancel, core.ScanPause or core.ScanResume to the scan, the id could be the workspace
+func (c *Client) ControlScan(id string, action string) (*database.Scan, error) {
+	var scan database.Scan
+	_, err := c.call(http.MethodPost, "/api/osmp/scans/:id/"+action, nil, nil, &scan, id)
+	return &scan, err
+}
+
+// Jobs the jobs of the queue
+func (c *Client) Jobs(query api.JobQuery) ([]database.Job, error) {
+	var jobs []database.Job
+	_, err := c.call(http.MethodGet, "/api/osmp/jobs", query, nil, &jobs)
+	return jobs, err
+}
+
+// Job the job of the queue by its ID
+func (c *Client) Job(id uint) (*database.Job, error) {
+	var job database.Job
+	_, err := c.call(http.MethodGet, "/api/osmp/jobs/:id", nil, nil, &job, id)
+	return &job, err
+}
+
+// LeaseJob lease the next pending job, nil if the queue is empty
+func (c *Client) LeaseJob(worker api.WorkerRequest) (*api.LeasedJob, error) {
+	var leased *api.LeasedJob
+	if _, err := c.call(http.MethodPost, "/api/osmp/jobs/lease", nil, worker, &leased); err != nil {
+		return nil, err
+	}
+	if leased == nil || leased.ID == 0 {
+		return nil, nil
+	}
+	return leased, nil
+}
+
+// HeartbeatJob extend the lease of the job, the 409 APIError means the job is no longer leased to the worker
+func (c *Client) HeartbeatJob(id uint, worker api.WorkerRequest) error {
+	_, err := c.call(http.MethodPost, "/api/osmp/jobs/:id/heartbeat", nil, worker, nil, id)
+	return err
+}
+
+// FinishJob mark the job as done, or failed if the error of the request is set
+func (c *Client) FinishJob(id uint, worker api.WorkerRequest) error {
+	_, err := c.call(http.MethodPost, "/api/osmp/jobs/:id/finish", nil, worker, nil, id)
+	return err
+}
+
+// UploadWorkspace upload the workspace archive of the job, the archive is streamed instead of loaded into memory
+func (c *Client) UploadWorkspace(id uint, worker string, workspace string, archive string) (*api.WorkspaceUploaded, error) {
+	file, err := os.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	go func() {
+		writer.WriteField("worker", worker)
+		writer.WriteField("workspace", workspace)
+		part, err := writer.CreateFormFile("archive", path.Base(archive))
+		if err == nil {
+			_, err = io.Copy(part, file)
+		}
+		if err == nil {
+			err = writer.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	req, err := c.request(http.MethodPost, "/api/osmp/jobs/:id/upload", nil, pr, id)
+	if err != nil {
+		pr.Close()
+		return nil, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	var uploaded api.WorkspaceUploaded
+	err = c.do(c.LongHTTP, req, &api.Response{Data: &uploaded})
+	// stop the writer if the request failed before reading the whole body
+	pr.Close()
+	if err != nil {
+		return nil, err
+	}
+	return &uploaded, nil
+}
+
+func (c *Client) Schedules() ([]database.Schedule, error) {
+	var schedules []database.Schedule
+	_, err := c.call(http.MethodGet, "/api/osmp/schedules", nil, nil, &schedules)
+	return schedules, err
+}
+
+// AddSchedule create the schedule
+func (c *Client) AddSchedule(schedule database.Schedule) (*database.Schedule, error) {
+	var created database.Schedule
+	_, err := c.call(http.MethodPost, "/api/osmp/schedules", nil, schedule, &created)
+	return &created, err
+}
+
+// PauseSchedule pause or resume the schedule
+func (c *Client) PauseSchedule(name string, paused bool) (*database.Schedule, error) {
+	route := "/api/osmp/schedules/:name/resume"
+	if paused {
+		route = "/api/osmp/schedules/:name/pause"
+	}
+	var schedule database.Schedule
+	_, err := c.call(http.MethodPost, route, nil, nil, &schedule, name)
+	return &schedule, err
+}
+
+// DeleteSchedule delete the schedule
+func (c *Client) DeleteSchedule(name string) error {
+	_, err := c.call(http.MethodDelete, "/api/osmp/schedules/:name", nil, nil, nil, name)
+	return err
+}
+
+// Me the user of the token
+func (c *Client) Me() (*api.AuthUser, error) {
+	var user api.AuthUser
+	_, err := c.call(http.MethodGet, "/api/osmp/me", nil, nil, &user)
+	return &user, err
+}
+
+// Tokens the API tokens of the user, all the tokens if all is true and the user is admin
+func (c *Client) Tokens(all bool) ([]database.APIToken, error) {
+	var tokens []database.APIToken
+	_, err := c.call(http.MethodGet, "/api/osmp/tokens", api.TokenQuery{All: all}, nil, &tokens)
+	return tokens, err
+}
+
+// CreateToken create the API token, the raw token is only sent once
+func (c *Client) CreateToken(token api.TokenRequest) (*api.TokenCreated, error) {
+	var created api.TokenCreated
+	_, err := c.call(http.MethodPost, "/api/osmp/tokens", nil, token, &created)
+	return &created, err
+}
+
+// RevokeToken revoke the API token
+func (c *Client) RevokeToken(id uint) error {
+	_, err := c.call(http.MethodDelete, "/api/osmp/tokens/:id", nil, nil, nil, id)
+	return err
+}
+
+// Users the users of the server
+func (c *Client) Users() ([]database.User, error) {
+	var users []database.User
+	_, err := c.call(http.MethodGet, "/api/osmp/users", nil, nil, &users)
+	return users, err
+}
+
+// AddUser create the user
+func (c *Client) AddUser(user api.UserRequest) (*database.User, error) {
+	var created database.User
+	_, err := c.call(http.MethodPost, "/api/osmp/users", nil, user, &created)
+	return &created, err
+}
+
+// UpdateUser update the user, the empty fields are kept
+func (c *Client) UpdateUser(username string, user api.UserRequest) (*database.User, error) {
+	var updated database.User
+	_, err := c.call(http.MethodPut, "/api/osmp/users/:username", nil, user, &updated, username)
+	return &updated, err
+}
+
+// DeleteUser delete the user and its API tokens
+func (c *Client) DeleteUser(username string) error {
+	_, err := c.call(http.MethodDelete, "/api/osmp/users/:username", nil, nil, nil, username)
+	return err
+}
+
+// Audit the audit log matching the query, the newest first
+func (c *Client) Audit(query api.AuditQuery) ([]database.AuditLog, int, error) {
+	var entries []database.AuditLog
+	response, err := c.call(http.MethodGet, "/api/osmp/audit", query, nil, &entries)
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, response.Total, nil
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/whoamikiddie/vulnx/api"
+	"github.com/whoamikiddie/vulnx/database"
+)
+
+func TestClient(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/osmp/workspaces", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Osmedeus secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"status":"error","message":"Invalid or expired JWT","data":null}`)
+			return
+		}
+		if r.URL.Query().Get("status") != "running" || r.URL.Query().Get("limit") != "2" || r.URL.Query().Has("flow") {
+			t.Errorf("Error encoding the query: %v", r.URL.RawQuery)
+		}
+		scans := []database.Scan{{TaskName: "general", InputName: "example.com"}}
+		jsoniter.NewEncoder(w).Encode(api.Response{Status: 200, Data: scans, Total: 5})
+	})
+	mux.HandleFunc("/api/osmp/scans/example.com/cancel", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":"scan example.com not found"}`)
+	})
+	mux.HandleFunc("/api/osmp/scans/example.com/stream", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("since") != "1" {
+			t.Errorf("Error sending the since query: %v", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, "id: 2\nevent: log\ndata: {\"id\":2,\"type\":\"log\",\"message\":\"hello\"}\n\n")
+		fmt.Fprint(w, "id: 3\nevent: state\ndata: {\"id\":3,\"type\":\"state\",\"state\":\"done\"}\n\n")
+		fmt.Fprint(w, "id: 4\nevent: log\ndata: {\"id\":4,\"type\":\"log\"}\n\n")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := New(server.URL, "secret", false)
+	scans, total, err := c.Workspaces(api.WorkspaceQuery{Status: "running", Limit: 2})
+	if err != nil || total != 5 || len(scans) != 1 || scans[0].InputName != "example.com" {
+		t.Fatalf("Error decoding the workspaces: %v %v %v", scans, total, err)
+	}
+
+	_, err = c.ControlScan("example.com", "cancel")
+	if !IsStatus(err, http.StatusNotFound) || err.(*APIError).Message != "scan example.com not found" {
+		t.Errorf("Error the error body should be returned: %v", err)
+	}
+
+	var events []api.ScanEvent
+	err = c.StreamScan(context.Background(), "example.com", api.StreamQuery{Since: 1}, func(event api.ScanEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil || len(events) != 2 || events[0].Message != "hello" || !events[1].IsFinal() {
+		t.Errorf("Error streaming the events until the final one: %v %v", events, err)
+	}
+
+	c.Token = "wrong"
+	if _, _, err := c.Workspaces(api.WorkspaceQuery{}); !IsStatus(err, http.StatusUnauthorized) {
+		t.Errorf("Error the auth error should be returned: %v", err)
+	}
+}
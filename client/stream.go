@@ -0,0 +1,59 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/whoamikiddie/vulnx/api"
+)
+
+// StreamScan follow the events of the scan with Server-Sent Events until the final event, the error of handle or the context is done
+// the backlog is replayed first, the stream ends after it if the Backlog of the query is set
+func (c *Client) StreamScan(ctx context.Context, id string, query api.StreamQuery, handle func(api.ScanEvent) error) error {
+	req, err := c.request(http.MethodGet, "/api/osmp/scans/:id/stream", query, nil, id)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := c.LongHTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return responseError(resp)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		case line == "" && len(data) > 0:
+			// the blank line ends the event, the comments and the other fields are ignored
+			var event api.ScanEvent
+			err := jsoniter.UnmarshalFromString(strings.Join(data, "\n"), &event)
+			data = nil
+			if err != nil {
+				continue
+			}
+			if err := handle(event); err != nil {
+				return err
+			}
+			if event.IsFinal() {
+				return nil
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return scanner.Err()
+}
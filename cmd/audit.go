@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/whoamikiddie/vulnx/core"
+	"github.com/whoamikiddie/vulnx/database"
+)
+
+func init() {
+	var auditCmd = &cobra.Command{
+		Use:   "audit",
+		Short: "Query the audit log of the API and CLI actions",
+		Long:  core.Banner(),
+		RunE:  runAudit,
+	}
+	auditCmd.Flags().StringVar(&options.Audit.Since, "since", "", "Only the entries after this time (RFC3339 or duration e.g: 24h)")
+	auditCmd.Flags().StringVar(&options.Audit.Until, "until", "", "Only the entries before this time (RFC3339 or duration e.g: 1h)")
+	auditCmd.Flags().StringVar(&options.Audit.Actor, "actor", "", "Only the entries of this user")
+	auditCmd.Flags().StringVar(&options.Audit.Action, "action", "", "Only the entries of this action (e.g: 'cli scan', 'POST /api/osmp/execute')")
+	auditCmd.Flags().IntVar(&options.Audit.Limit, "limit", 50, "Maximum number of the entries")
+	auditCmd.Flags().BoolVar(&options.JsonOutput, "json", false, "Output as JSON")
+	RootCmd.AddCommand(auditCmd)
+}
+
+// withAudit record the command with its flags to the audit log before it's run, then record its result
+// so the command which never returns, e.g. killed or crashed, is still in the audit log
+func withAudit(action string, run func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		params := make(map[string]interface{})
+		cmd.Flags().Visit(func(flag *pflag.Flag) {
+			params[flag.Name] = flag.Value.String()
+		})
+		if len(args) > 0 {
+			params["args"] = strings.Join(args, " ")
+		}
+		target := strings.Join(options.Scan.Inputs, ",")
+		if target == "" {
+			target = options.Scan.InputList
+		}
+		hostname, _ := os.Hostname()
+		entry := database.AuditLog{
+			Source:    database.AuditSourceCLI,
+			Actor:     core.LocalActor(),
+			SourceIP:  hostname,
+			Action:    action,
+			Target:    target,
+			Workspace: options.Scan.CustomWorkspace,
+			Params:    core.AuditParams(params),
+			Result:    "started",
+		}
+		if entry.Workspace == "" {
+			entry.Workspace = options.Cloud.Workspace
+		}
+		core.Audit(entry, options)
+
+		err := run(cmd, args)
+		entry.Result = "success"
+		if err != nil {
+			entry.Result = "failed"
+			entry.Error = err.Error()
+		}
+		core.Audit(entry, options)
+		return err
+	}
+}
+
+func runAudit(_ *cobra.Command, _ []string) error {
+	filter := database.AuditFilter{
+		Actor:  options.Audit.Actor,
+		Action: options.Audit.Action,
+		Limit:  options.Audit.Limit,
+	}
+	var err error
+	if filter.Since, err = core.ParseAuditTime(options.Audit.Since); err != nil {
+		return err
+	}
+	if filter.Until, err = core.ParseAuditTime(options.Audit.Until); err != nil {
+		return err
+	}
+	entries, _, err := core.ReadAudit(filter, options)
+	if err != nil {
+		return err
+	}
+	if options.JsonOutput {
+		for _, entry := range entries {
+			if data, err := jsoniter.MarshalToString(entry); err == nil {
+				fmt.Println(data)
+			}
+		}
+		return nil
+	}
+
+	var content [][]string
+	for _, entry := range entries {
+		result := color.HiGreenString(entry.Result)
+		switch entry.Result {
+		case "success":
+		case "started":
+			result = color.HiYellowString(entry.Result)
+		default:
+			result = color.HiRedString("%v %v", entry.Result, entry.Error)
+		}
+		target := entry.Target
+		if entry.Workspace != "" && entry.Workspace != target {
+			target = strings.TrimPrefix(fmt.Sprintf("%v %v", target, entry.Workspace), " ")
+		}
+		content = append(content, []string{entry.CreatedAt.Format(time.RFC3339), entry.Actor, entry.SourceIP, entry.Action, target, result})
+	}
+	table := tablewriter.NewWriter(os.Stderr)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeader([]string{"Time", "Actor", "Source", "Action", "Target", "Result"})
+	table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+	table.SetColWidth(60)
+	table.AppendBulk(content)
+	table.Render()
+	return nil
+}
@@ -21,7 +21,7 @@ func init() {
 		Use:   "cloud",
 		Short: "Perform a scan using the distributed cloud mode",
 		Long:  core.Banner(),
-		RunE:  runCloud,
+		RunE:  withAudit("cli cloud", runCloud),
 	}
 
 	// core options
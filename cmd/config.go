@@ -19,7 +19,7 @@ func init() {
 		Use:   "config",
 		Short: "Do some configuration from CLI",
 		Long:  core.Banner(),
-		RunE:  runConfig,
+		RunE:  withAudit("cli config", runConfig),
 	}
 
 	configCmd.Flags().StringP("action", "a", "", "Action")
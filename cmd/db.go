@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/whoamikiddie/vulnx/core"
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+func init() {
+	var dbCmd = &cobra.Command{
+		Use:   "db",
+		Short: "Manage the embedded database",
+		Long:  core.Banner(),
+		RunE:  runDB,
+	}
+
+	var importCmd = &cobra.Command{
+		Use:     "import",
+		Aliases: []string{"migrate", "imp"},
+		Short:   "Import existing workspaces to the database",
+		Long:    core.Banner(),
+		RunE:    runDBImport,
+	}
+	dbCmd.AddCommand(importCmd)
+
+	dbCmd.SetHelpFunc(DBHelp)
+	RootCmd.AddCommand(dbCmd)
+	dbCmd.PreRun = func(cmd *cobra.Command, args []string) {
+		if options.FullHelp {
+			cmd.Help()
+			os.Exit(0)
+		}
+	}
+}
+
+func runDB(_ *cobra.Command, _ []string) error {
+	fmt.Println(DBUsage())
+	return nil
+}
+
+func runDBImport(_ *cobra.Command, _ []string) error {
+	if database.DB == nil {
+		return fmt.Errorf("database is disabled, check the 'Database' section in %v", options.ConfigFile)
+	}
+
+	// osmedeus db import -t workspace-name
+	var workspaces []string
+	for _, target := range options.Scan.Inputs {
+		workspaces = append(workspaces, path.Base(target))
+	}
+
+	imported := database.ImportWorkspaces(options, workspaces...)
+	utils.GoodF("Imported %v workspaces to the database at %v", color.HiMagentaString("%v", imported), color.HiCyanString(options.Server.DBPath))
+	return nil
+}
@@ -3,13 +3,16 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	jsoniter "github.com/json-iterator/go"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
 	"github.com/whoamikiddie/vulnx/core"
+	"github.com/whoamikiddie/vulnx/database"
 	"github.com/whoamikiddie/vulnx/libs"
 	"github.com/whoamikiddie/vulnx/utils"
 )
@@ -17,15 +20,56 @@ import (
 func init() {
 	var queueCmd = &cobra.Command{
 		Use:     "queue",
-		Short:   "Running the scan with input from queue file",
+		Short:   "Running the scan with input from the queue",
 		Aliases: []string{"queq", "quee", "queu", "que"},
 		Long:    core.Banner(),
 		RunE:    runQueue,
 	}
-	queueCmd.PersistentFlags().StringVarP(&options.Queue.QueueFile, "queue-file", "Q", fmt.Sprintf("~/.%s/queue/queue-mimic.txt", libs.BINARY), "File contain list of target to simulate the queue")
-	queueCmd.PersistentFlags().BoolVar(&options.Queue.Add, "add", false, "Add new input to the queue file")
+	queueCmd.PersistentFlags().StringVarP(&options.Queue.QueueFile, "queue-file", "Q", fmt.Sprintf("~/.%s/queue/queue-mimic.txt", libs.BINARY), "Legacy queue file, its inputs are imported into the queue")
+	queueCmd.PersistentFlags().BoolVar(&options.Queue.Add, "add", false, "Add new input to the queue (same as 'queue add')")
 	queueCmd.PersistentFlags().BoolVarP(&options.Queue.InputAsFile, "as-file", "F", false, "treat input as a file")
 	queueCmd.PersistentFlags().StringVar(&options.Queue.RawCommand, "cmd", "", "Raw Command to run")
+	queueCmd.PersistentFlags().IntVar(&options.Queue.Priority, "priority", 0, "Priority of the job, higher priority is picked first")
+	queueCmd.PersistentFlags().IntVar(&options.Queue.MaxAttempts, "attempts", database.DefaultJobAttempts, "Max attempts before the job is marked as failed")
+	queueCmd.Flags().IntVar(&options.Queue.PollingTime, "poll", 5, "Number of seconds to wait before checking the queue again")
+
+	var addCmd = &cobra.Command{
+		Use:   "add",
+		Short: "Add the targets to the queue",
+		Long:  core.Banner(),
+		RunE:  runQueueAdd,
+	}
+	queueCmd.AddCommand(addCmd)
+
+	var listCmd = &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the jobs in the queue",
+		Long:    core.Banner(),
+		RunE:    runQueueList,
+	}
+	listCmd.Flags().StringSliceVar(&options.Queue.States, "state", []string{}, "Only show the jobs in the states (pending, running, done, failed, cancelled)")
+	listCmd.Flags().IntVar(&options.Queue.Limit, "limit", 0, "Limit the number of jobs")
+	queueCmd.AddCommand(listCmd)
+
+	var cancelCmd = &cobra.Command{
+		Use:   "cancel",
+		Short: "Cancel the pending or running jobs",
+		Long:  core.Banner(),
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runQueueCancel,
+	}
+	queueCmd.AddCommand(cancelCmd)
+
+	var retryCmd = &cobra.Command{
+		Use:   "retry",
+		Short: "Put the failed or cancelled jobs back to the queue",
+		Long:  core.Banner(),
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runQueueRetry,
+	}
+	queueCmd.AddCommand(retryCmd)
+
 	queueCmd.SetHelpFunc(QueueHelp)
 	RootCmd.AddCommand(queueCmd)
 	queueCmd.PreRun = func(cmd *cobra.Command, args []string) {
@@ -37,51 +81,133 @@ func init() {
 }
 
 func runQueue(_ *cobra.Command, _ []string) error {
-	options.Queue.QueueFile = utils.NormalizePath(options.Queue.QueueFile)
-	options.Queue.QueueFolder = path.Dir(options.Queue.QueueFile)
-	if !utils.FolderExists(options.Queue.QueueFolder) {
-		utils.MakeDir(options.Queue.QueueFolder)
-	}
-
 	if options.Queue.Add {
-		addInput()
-		return nil
+		return addInput()
 	}
-
-	if !utils.FileExists(options.Queue.QueueFile) {
-		utils.WriteToFile(options.Queue.QueueFile, "")
+	counts, err := database.CountJobs()
+	if err != nil {
+		return err
 	}
-
-	content := utils.ReadingFileUnique(options.Queue.QueueFile)
-	if len(content) == 0 {
-		utils.WarnF("Queue file is empty: %v", options.Queue.QueueFile)
-		utils.WarnF("Consider to add a input to it:" + color.HiGreenString(" osmedeus queue --add -t example.com"))
+	if counts[database.JobPending] == 0 {
+		utils.WarnF("Queue is empty, consider to add a input to it:" + color.HiGreenString(" osmedeus queue add -t example.com"))
 	} else {
-		utils.InforF("Queue file is not empty: %v", color.HiCyanString(options.Queue.QueueFile))
-		utils.InforF("Consider to delete it if you want a fresh scan")
+		utils.InforF("There are %v pending jobs in the queue", color.HiMagentaString("%v", counts[database.JobPending]))
 	}
 
 	core.QueueWatcher(options)
 	return nil
 }
 
-func addInput() {
-	utils.InforF("Adding new input to the queue file: %v", color.HiCyanString(options.Queue.QueueFile))
-	// osmedeus queue --add -t example.com
-	if options.Queue.RawCommand == "" {
-		utils.WriteToFile(options.Queue.QueueFile, strings.Join(options.Scan.Inputs, "\n"))
-		return
-	}
+func runQueueAdd(_ *cobra.Command, _ []string) error {
+	return addInput()
+}
 
-	// osmedeus queue --add -t /tmp/cidr --cmd "osmedeus -t {{Input}} -m recon -w"
+func addInput() error {
+	if len(options.Scan.Inputs) == 0 {
+		return fmt.Errorf("no input provided, please use the -t or -T flag")
+	}
+	// osmedeus queue add -t example.com -f general
+	// osmedeus queue add -t /tmp/cidr --cmd "osmedeus scan -t {{.input}} -m recon"
 	for _, target := range options.Scan.Inputs {
-		queueInput := libs.InputFormat{
-			Input:       target,
-			Command:     options.Queue.RawCommand,
-			InputAsFile: options.Queue.InputAsFile,
+		job := core.NewJob(libs.InputFormat{
+			Input:          target,
+			Flow:           options.Scan.Flow,
+			Modules:        options.Scan.Modules,
+			Params:         options.Scan.Params,
+			Workspaces:     options.Scan.CustomWorkspace,
+			Command:        options.Queue.RawCommand,
+			InputAsFile:    options.Queue.InputAsFile,
+			CallbackURL:    options.Scan.CallbackURL,
+			CallbackSecret: options.Scan.CallbackSecret,
+		})
+		if len(job.Modules) > 0 {
+			job.Flow = ""
+		}
+		job.Priority = options.Queue.Priority
+		job.MaxAttempts = options.Queue.MaxAttempts
+
+		created, err := database.EnqueueJob(&job)
+		if err != nil {
+			return err
+		}
+		if !created {
+			utils.WarnF("The same target and flow is already %v as job %v: %v", job.State, color.HiMagentaString("#%v", job.ID), color.HiCyanString(target))
+			continue
+		}
+		utils.InforF("Added %v to the queue as job %v", color.HiCyanString(target), color.HiMagentaString("#%v", job.ID))
+	}
+	return nil
+}
+
+func runQueueList(_ *cobra.Command, _ []string) error {
+	jobs, err := database.ListJobs(options.Queue.States, options.Queue.Limit)
+	if err != nil {
+		return err
+	}
+	if options.JsonOutput {
+		for _, job := range jobs {
+			if data, err := jsoniter.MarshalToString(job); err == nil {
+				fmt.Println(data)
+			}
+		}
+		return nil
+	}
+
+	var content [][]string
+	for _, job := range jobs {
+		routine := job.Flow
+		if len(job.Modules) > 0 {
+			routine = strings.Join(job.Modules, ",")
+		}
+		if job.Command != "" {
+			routine = "command"
+		}
+		state := job.State
+		switch job.State {
+		case database.JobRunning:
+			state = color.HiBlueString(state)
+		case database.JobDone:
+			state = color.HiGreenString(state)
+		case database.JobFailed:
+			state = color.HiRedString(state)
 		}
-		if line, ok := jsoniter.MarshalToString(queueInput); ok == nil {
-			utils.AppendToContent(options.Queue.QueueFile, line)
+		content = append(content, []string{fmt.Sprintf("%v", job.ID), job.Input, routine, state, fmt.Sprintf("%v", job.Priority),
+			fmt.Sprintf("%v/%v", job.Attempts, job.MaxAttempts), job.Worker, job.CreatedAt.Format(time.RFC3339), job.LastError})
+	}
+	table := tablewriter.NewWriter(os.Stderr)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeader([]string{"ID", "Input", "Flow/Module", "State", "Priority", "Attempts", "Worker", "Created", "Last Error"})
+	table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+	table.SetColWidth(60)
+	table.AppendBulk(content)
+	table.Render()
+
+	counts, _ := database.CountJobs()
+	fmt.Println(color.HiGreenString("📦 Jobs: ") + color.HiMagentaString("%v pending, %v running, %v done, %v failed",
+		counts[database.JobPending], counts[database.JobRunning], counts[database.JobDone], counts[database.JobFailed]))
+	return nil
+}
+
+func runQueueCancel(_ *cobra.Command, args []string) error {
+	for _, arg := range args {
+		job, err := database.CancelJob(cast.ToUint(arg))
+		if err != nil {
+			utils.ErrorF("%v", err)
+			continue
 		}
+		utils.InforF("Cancelled the job %v: %v", color.HiMagentaString("#%v", job.ID), color.HiCyanString(job.Input))
 	}
+	return nil
+}
+
+func runQueueRetry(_ *cobra.Command, args []string) error {
+	for _, arg := range args {
+		job, err := database.RetryJob(cast.ToUint(arg))
+		if err != nil {
+			utils.ErrorF("%v", err)
+			continue
+		}
+		utils.InforF("Put the job %v back to the queue: %v", color.HiMagentaString("#%v", job.ID), color.HiCyanString(job.Input))
+	}
+	return nil
 }
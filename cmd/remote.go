@@ -0,0 +1,468 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path"
+	"strings"
+
+	"github.com/fatih/color"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/whoamikiddie/vulnx/api"
+	"github.com/whoamikiddie/vulnx/client"
+	"github.com/whoamikiddie/vulnx/core"
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/execution"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+func init() {
+	var remoteCmd = &cobra.Command{
+		Use:   "remote",
+		Short: "Run and follow the scans on a remote API server",
+		Long:  core.Banner(),
+		RunE:  runRemoteStatus,
+	}
+
+	var loginCmd = &cobra.Command{
+		Use:   "login <url>",
+		Short: "Login to the API server, the token is saved for the other remote commands",
+		Long:  core.Banner(),
+		Args:  cobra.ExactArgs(1),
+		RunE:  runRemoteLogin,
+	}
+	loginCmd.Flags().StringVar(&options.Remote.Username, "username", "", "Username to login to the server (default will get from the config file)")
+	loginCmd.Flags().StringVar(&options.Remote.Password, "password", "", "Password to login to the server (default will get from the config file)")
+	loginCmd.Flags().StringVar(&options.Remote.APIToken, "api-token", "", "Use the API token instead of login with the username and password")
+	loginCmd.Flags().BoolVar(&options.Remote.Insecure, "insecure", false, "Skip the verification of the certificate, the server uses a self-signed one by default")
+	remoteCmd.AddCommand(loginCmd)
+
+	remoteCmd.AddCommand(&cobra.Command{
+		Use:   "logout",
+		Short: "Remove the saved token of the server",
+		Long:  core.Banner(),
+		RunE:  runRemoteLogout,
+	})
+
+	remoteCmd.AddCommand(&cobra.Command{
+		Use:   "scan",
+		Short: "Add the scans of the targets to the queue of the server",
+		Long:  core.Banner(),
+		RunE:  runRemoteScan,
+	})
+
+	var lsCmd = &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List the workspaces of the server",
+		Long:    core.Banner(),
+		RunE:    runRemoteList,
+	}
+	lsCmd.Flags().StringVar(&options.Remote.Status, "status", "", "Only the workspaces of this status (pending, running, paused, finished, failed or cancelled)")
+	lsCmd.Flags().StringVar(&options.Remote.Search, "search", "", "Only the workspaces matching this text")
+	lsCmd.Flags().StringVar(&options.Remote.Sort, "sort", "date", "Sort by date, name, status or findings")
+	lsCmd.Flags().IntVar(&options.Remote.Limit, "limit", 50, "Maximum number of the workspaces")
+	lsCmd.Flags().BoolVar(&options.JsonOutput, "json", false, "Output as JSON")
+	remoteCmd.AddCommand(lsCmd)
+
+	var statusCmd = &cobra.Command{
+		Use:   "status [workspace]",
+		Short: "Show the progress of the workspace, or of all the running scans",
+		Long:  core.Banner(),
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runRemoteStatus,
+	}
+	statusCmd.Flags().BoolVar(&options.JsonOutput, "json", false, "Output as JSON")
+	remoteCmd.AddCommand(statusCmd)
+
+	var logsCmd = &cobra.Command{
+		Use:   "logs <workspace|scan-id>",
+		Short: "Print the events of the scan",
+		Long:  core.Banner(),
+		Args:  cobra.ExactArgs(1),
+		RunE:  runRemoteLogs,
+	}
+	// the local flow flag hides the global one so -f could be the shorthand of follow like tail -f
+	var flow string
+	logsCmd.Flags().StringVar(&flow, "flow", "", "")
+	logsCmd.Flags().MarkHidden("flow")
+	logsCmd.Flags().BoolVarP(&options.Remote.Follow, "follow", "f", false, "Keep following the new events until the scan is finished")
+	logsCmd.Flags().BoolVar(&options.JsonOutput, "json", false, "Output as JSON")
+	remoteCmd.AddCommand(logsCmd)
+
+	remoteCmd.AddCommand(&cobra.Command{
+		Use:   "cancel <workspace|scan-id>...",
+		Short: "Cancel the running scans",
+		Long:  core.Banner(),
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runRemoteCancel,
+	})
+
+	remoteCmd.AddCommand(&cobra.Command{
+		Use:   "pull <workspace>",
+		Short: "Download the workspace to the local workspaces folder",
+		Long:  core.Banner(),
+		Args:  cobra.ExactArgs(1),
+		RunE:  runRemotePull,
+	})
+
+	var flowsCmd = &cobra.Command{
+		Use:   "flows",
+		Short: "List the workflows of the server",
+		Long:  core.Banner(),
+		RunE:  runRemoteFlows,
+	}
+	flowsCmd.Flags().BoolVar(&options.JsonOutput, "json", false, "Output as JSON")
+	remoteCmd.AddCommand(flowsCmd)
+
+	remoteCmd.SetHelpFunc(RemoteHelp)
+	RootCmd.AddCommand(remoteCmd)
+}
+
+// remoteSession the server and the token saved by remote login
+type remoteSession struct {
+	URL      string `json:"url"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	Token    string `json:"token"`
+	Insecure bool   `json:"insecure"`
+}
+
+// remoteSessionFile the file of the session, it's only readable by the user since it holds the token
+func remoteSessionFile() string {
+	return path.Join(utils.NormalizePath(options.Env.RootFolder), "remote.json")
+}
+
+// remoteClient the client of the saved session
+func remoteClient() (*client.Client, error) {
+	var session remoteSession
+	raw, err := os.ReadFile(remoteSessionFile())
+	if err == nil {
+		err = jsoniter.Unmarshal(raw, &session)
+	}
+	if err != nil || session.URL == "" || session.Token == "" {
+		return nil, fmt.Errorf("not logged in, please run '%v remote login <url>' first", libs.BINARY)
+	}
+	return client.New(session.URL, session.Token, session.Insecure), nil
+}
+
+func runRemoteLogin(_ *cobra.Command, args []string) error {
+	session := remoteSession{
+		URL:      strings.TrimSuffix(args[0], "/"),
+		Insecure: options.Remote.Insecure,
+	}
+	c := client.New(session.URL, options.Remote.APIToken, session.Insecure)
+	if options.Remote.APIToken == "" {
+		username, password := options.Remote.Username, options.Remote.Password
+		if username == "" && password == "" {
+			username, password = options.Client.Username, options.Client.Password
+		}
+		if _, err := c.Login(username, password); err != nil {
+			return fmt.Errorf("login failed at %v: %v", session.URL, err)
+		}
+	}
+	// the API token is checked here too
+	user, err := c.Me()
+	if err != nil {
+		return fmt.Errorf("login failed at %v: %v", session.URL, err)
+	}
+	session.Token = c.Token
+	session.Username = user.Username
+	session.Role = user.Role
+
+	data, err := jsoniter.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+	utils.MakeDir(path.Dir(remoteSessionFile()))
+	if err := os.WriteFile(remoteSessionFile(), data, 0600); err != nil {
+		return err
+	}
+	utils.GoodF("Logged in to %v as %v with the %v role", color.HiCyanString(session.URL), color.HiMagentaString(user.Username), color.HiYellowString(user.Role))
+	return nil
+}
+
+func runRemoteLogout(_ *cobra.Command, _ []string) error {
+	if err := os.Remove(remoteSessionFile()); err != nil {
+		if os.IsNotExist(err) {
+			utils.InforF("Not logged in")
+			return nil
+		}
+		return err
+	}
+	utils.GoodF("Logged out, the saved token is removed")
+	return nil
+}
+
+func runRemoteScan(_ *cobra.Command, _ []string) error {
+	c, err := remoteClient()
+	if err != nil {
+		return err
+	}
+	base := api.ScanRequest{
+		WorkFlow:       options.Scan.Flow,
+		Workspace:      options.Scan.CustomWorkspace,
+		Params:         options.Scan.Params,
+		CallbackURL:    options.Scan.CallbackURL,
+		CallbackSecret: options.Scan.CallbackSecret,
+	}
+	if len(options.Scan.Modules) > 0 {
+		base.PluginName = strings.Join(options.Scan.Modules, ",")
+	}
+
+	// every target is a scan, the list of targets is a single scan like the local one
+	var scans []api.ScanRequest
+	for _, target := range options.Scan.Inputs {
+		scan := base
+		scan.Target = target
+		scans = append(scans, scan)
+	}
+	if options.Scan.InputList != "" {
+		targets := utils.ReadingFileUnique(utils.NormalizePath(options.Scan.InputList))
+		if len(targets) == 0 {
+			return fmt.Errorf("no target in %v", options.Scan.InputList)
+		}
+		scan := base
+		scan.TargetsList = targets
+		scans = append(scans, scan)
+	}
+	if len(scans) == 0 {
+		return fmt.Errorf("no target, please use -t or -T")
+	}
+
+	var content [][]string
+	var failed int
+	for _, scan := range scans {
+		input := scan.Target
+		if input == "" {
+			input = options.Scan.InputList
+		}
+		queued, err := c.NewScan(scan)
+		if err != nil {
+			failed++
+			content = append(content, []string{"", color.HiRedString("failed"), input, err.Error()})
+			continue
+		}
+		content = append(content, []string{fmt.Sprintf("#%v", queued.JobID), color.HiGreenString(queued.State), input, queued.Workspace})
+	}
+	table := tablewriter.NewWriter(os.Stderr)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeader([]string{"Job", "State", "Input", "Workspace"})
+	table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+	table.SetColWidth(60)
+	table.AppendBulk(content)
+	table.Render()
+	if failed > 0 {
+		return fmt.Errorf("%v of %v scans could not be queued", failed, len(scans))
+	}
+	return nil
+}
+
+func runRemoteList(cmd *cobra.Command, _ []string) error {
+	c, err := remoteClient()
+	if err != nil {
+		return err
+	}
+	query := api.WorkspaceQuery{
+		Status: options.Remote.Status,
+		Search: options.Remote.Search,
+		Sort:   options.Remote.Sort,
+		Limit:  options.Remote.Limit,
+	}
+	// the flow flag is global and always has a default value
+	if cmd.Flags().Changed("flow") {
+		query.Flow = options.Scan.Flow
+	}
+	scans, total, err := c.Workspaces(query)
+	if err != nil {
+		return err
+	}
+	printRemoteScans(scans)
+	if !options.JsonOutput {
+		utils.InforF("Showing %v of %v workspaces", len(scans), total)
+	}
+	return nil
+}
+
+func runRemoteStatus(_ *cobra.Command, args []string) error {
+	c, err := remoteClient()
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		scans, _, err := c.Workspaces(api.WorkspaceQuery{Status: database.ScanStatusRunning})
+		if err != nil {
+			return err
+		}
+		if len(scans) == 0 && !options.JsonOutput {
+			utils.InforF("No running scan")
+			return nil
+		}
+		printRemoteScans(scans)
+		return nil
+	}
+
+	detail, err := c.Workspace(args[0])
+	if err != nil {
+		return err
+	}
+	if detail.Workspace.InputName == "" {
+		return fmt.Errorf("workspace %v not found", args[0])
+	}
+	if options.JsonOutput {
+		data, err := jsoniter.MarshalToString(detail)
+		if err == nil {
+			fmt.Println(data)
+		}
+		return err
+	}
+	printRemoteScans([]database.Scan{detail.Workspace})
+	for module, reports := range detail.Reports {
+		for _, report := range reports {
+			fmt.Printf("%v %v%v\n", color.HiCyanString(module), c.URL, report)
+		}
+	}
+	return nil
+}
+
+// printRemoteScans print the latest scan of the workspaces, the raw JSON is printed with the --json flag
+func printRemoteScans(scans []database.Scan) {
+	if options.JsonOutput {
+		for _, scan := range scans {
+			if data, err := jsoniter.MarshalToString(scan); err == nil {
+				fmt.Println(data)
+			}
+		}
+		return
+	}
+
+	var content [][]string
+	for _, scan := range scans {
+		workspace := scan.Target.Workspace
+		if workspace == "" {
+			workspace = utils.CleanPath(scan.InputName)
+		}
+		status := database.ScanStatus(scan)
+		switch status {
+		case database.ScanStatusFinished:
+			status = color.HiGreenString(status)
+		case database.ScanStatusRunning:
+			status = color.HiCyanString(status)
+		case database.ScanStatusPaused, database.ScanStatusPending:
+			status = color.HiYellowString(status)
+		default:
+			status = color.HiRedString(status)
+		}
+		content = append(content, []string{
+			workspace, scan.TaskName, status,
+			fmt.Sprintf("%v/%v", scan.DoneStep, scan.TotalSteps), scan.CurrentModule,
+			fmt.Sprintf("%v", scan.Target.TotalVulnerability), scan.UpdatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	table := tablewriter.NewWriter(os.Stderr)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeader([]string{"Workspace", "Flow", "Status", "Progress", "Module", "Findings", "Updated"})
+	table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+	table.SetColWidth(60)
+	table.AppendBulk(content)
+	table.Render()
+}
+
+func runRemoteLogs(_ *cobra.Command, args []string) error {
+	c, err := remoteClient()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err = c.StreamScan(ctx, args[0], api.StreamQuery{Backlog: !options.Remote.Follow}, printScanEvent)
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+func runRemoteCancel(_ *cobra.Command, args []string) error {
+	c, err := remoteClient()
+	if err != nil {
+		return err
+	}
+	for _, id := range args {
+		if _, err := c.ControlScan(id, core.ScanCancel); err != nil {
+			return fmt.Errorf("error cancelling the scan %v: %v", id, err)
+		}
+		utils.GoodF("Sent the %v action to the scan %v", core.ScanCancel, color.HiCyanString(id))
+	}
+	return nil
+}
+
+func runRemotePull(_ *cobra.Command, args []string) error {
+	c, err := remoteClient()
+	if err != nil {
+		return err
+	}
+	workspace := path.Base(args[0])
+	if !utils.IsSafeName(workspace) {
+		return fmt.Errorf("invalid workspace %v", args[0])
+	}
+
+	f, err := os.CreateTemp("", fmt.Sprintf("%v-pull-*.tar.gz", libs.BINARY))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	size, err := c.DownloadWorkspace(workspace, f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("error downloading the workspace %v: %v", workspace, err)
+	}
+
+	dest := path.Join(utils.NormalizePath(options.Env.WorkspacesFolder), workspace)
+	// the archive comes from the server, the entries outside of the workspace are rejected
+	if err := execution.ExtractArchive(dest, f.Name()); err != nil {
+		return fmt.Errorf("error extracting the workspace %v: %v", workspace, err)
+	}
+	if database.DB != nil {
+		database.ImportWorkspaces(options, workspace)
+	}
+	utils.GoodF("Pulled the workspace %v (%v bytes) to %v", color.HiCyanString(workspace), size, color.HiMagentaString(dest))
+	return nil
+}
+
+func runRemoteFlows(_ *cobra.Command, _ []string) error {
+	c, err := remoteClient()
+	if err != nil {
+		return err
+	}
+	flows, err := c.Flows()
+	if err != nil {
+		return err
+	}
+	if options.JsonOutput {
+		for _, flow := range flows {
+			if data, err := jsoniter.MarshalToString(flow); err == nil {
+				fmt.Println(data)
+			}
+		}
+		return nil
+	}
+
+	var content [][]string
+	for _, flow := range flows {
+		content = append(content, []string{flow.Name, flow.Desc, strings.ReplaceAll(flow.Modules, ",", ", ")})
+	}
+	table := tablewriter.NewWriter(os.Stderr)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeader([]string{"Flow", "Description", "Modules"})
+	table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+	table.SetColWidth(60)
+	table.AppendBulk(content)
+	table.Render()
+	return nil
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"fmt"
 	"io"
 	"net/http"
 	"os"
@@ -55,10 +56,55 @@ func init() {
 		Aliases: []string{"com", "compr", "compres", "c"},
 		Short:   "Create a backup of the selected workspace",
 		Long:    core.Banner(),
-		RunE:    runReportCompress,
+		RunE:    withAudit("cli report compress", runReportCompress),
 	}
 	reportCmd.AddCommand(compressCmd)
 
+	var changesCmd = &cobra.Command{
+		Use:     "changes",
+		Aliases: []string{"change", "ch"},
+		Short:   "Show the assets appeared or vanished between the scans",
+		Long:    core.Banner(),
+		RunE:    runReportChanges,
+	}
+	changesCmd.Flags().StringVar(&options.Report.Since, "since", "", "Show changes since the date (e.g: 2023-01-30, 7d, 12h), default is the latest scan")
+	reportFormatFlag(changesCmd, "table", "Output format (table, json)")
+	reportCmd.AddCommand(changesCmd)
+
+	var queryCmd = &cobra.Command{
+		Use:     "query",
+		Aliases: []string{"q", "search"},
+		Short:   "Query the assets and findings of the workspaces",
+		Long:    core.Banner(),
+		Args:    cobra.ExactArgs(1),
+		RunE:    runReportQuery,
+	}
+	reportFormatFlag(queryCmd, "table", "Output format (table, json, csv)")
+	reportCmd.AddCommand(queryCmd)
+
+	var exportCmd = &cobra.Command{
+		Use:     "export",
+		Aliases: []string{"exp"},
+		Short:   "Export the findings of the workspace",
+		Long:    core.Banner(),
+		RunE:    runReportExport,
+	}
+	reportFormatFlag(exportCmd, "json", "Output format (sarif, json, csv)")
+	exportCmd.Flags().StringVarP(&options.Report.Output, "output", "o", "", "Output file (default is stdout)")
+	reportCmd.AddCommand(exportCmd)
+
+	var compareCmd = &cobra.Command{
+		Use:     "compare",
+		Aliases: []string{"cmp", "diff"},
+		Short:   "Compare the report files of two workspaces",
+		Long:    core.Banner(),
+		Args:    cobra.ExactArgs(2),
+		RunE:    runReportCompare,
+	}
+	reportFormatFlag(compareCmd, "table", "Output format (table, markdown, json), markdown and json include the full diff")
+	compareCmd.Flags().StringVarP(&options.Report.Output, "output", "o", "", "Output file (default is stdout)")
+	reportCmd.AddCommand(compareCmd)
+
 	reportCmd.PersistentFlags().BoolVar(&options.Report.Raw, "raw", false, "Show all the file in the workspace")
 	reportCmd.PersistentFlags().StringVar(&options.Report.PublicIP, "ip", "", "Show downloadable file with the given IP address")
 	reportCmd.PersistentFlags().BoolVar(&options.Report.Static, "static", false, "Show report file with Prefix Static")
@@ -72,6 +118,17 @@ func init() {
 	}
 }
 
+// reportFormatFlag add the --format flag with its own default to the report command
+// the commands share options.Report.Format so it's only set once the command is picked
+func reportFormatFlag(cmd *cobra.Command, defaultFormat string, usage string) {
+	cmd.Flags().String("format", defaultFormat, usage)
+	cmd.PreRunE = func(cmd *cobra.Command, _ []string) error {
+		format, err := cmd.Flags().GetString("format")
+		options.Report.Format = format
+		return err
+	}
+}
+
 func runReportList(_ *cobra.Command, _ []string) error {
 	core.ListWorkspaces(options)
 	return nil
@@ -128,6 +185,57 @@ func runReportCompress(_ *cobra.Command, _ []string) error {
 	return nil
 }
 
+func runReportChanges(_ *cobra.Command, _ []string) error {
+	if len(options.Scan.Inputs) == 0 {
+		utils.InforF("Please select workspace to view the changes. Try %s", color.HiCyanString(`'osmedeus report changes -t target.com --since 7d'`))
+		return nil
+	}
+
+	for _, target := range options.Scan.Inputs {
+		if _, err := core.ListChanges(options, target); err != nil {
+			utils.ErrorF("Error getting changes of %v: %v", target, err)
+		}
+	}
+	return nil
+}
+
+func runReportQuery(_ *cobra.Command, args []string) error {
+	_, err := core.QueryReport(options, args[0])
+	return err
+}
+
+func runReportExport(_ *cobra.Command, _ []string) error {
+	if len(options.Scan.Inputs) == 0 {
+		utils.InforF("Please select workspace to export. Try %s", color.HiCyanString(`'osmedeus report export -t target.com --format sarif'`))
+		return nil
+	}
+
+	for _, target := range options.Scan.Inputs {
+		if options.Report.Output == "" {
+			if err := core.ExportWorkspace(target, options.Report.Format, os.Stdout); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// each workspace has its own file if there are multiple workspaces
+		output := options.Report.Output
+		if len(options.Scan.Inputs) > 1 {
+			output = path.Join(path.Dir(output), fmt.Sprintf("%s-%s", target, path.Base(output)))
+		}
+		if err := core.ExportWorkspaceToFile(target, options.Report.Format, output); err != nil {
+			return err
+		}
+		utils.InforF("Exported the findings of %v to: %v", target, color.HiGreenString(output))
+	}
+	return nil
+}
+
+func runReportCompare(_ *cobra.Command, args []string) error {
+	_, err := core.CompareReport(options, args[0], args[1])
+	return err
+}
+
 func runReport(_ *cobra.Command, args []string) error {
 	if options.Report.PublicIP == "" {
 		if utils.GetOSEnv("IPAddress", "127.0.0.1") == "127.0.0.1" {
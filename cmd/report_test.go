@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+func TestReportFormatDefault(t *testing.T) {
+	var opt libs.Options
+	opt.Server.DBPath = path.Join(t.TempDir(), "sqlite.db")
+	if _, err := database.InitDB(opt); err != nil {
+		t.Fatalf("Error InitDB: %v", err)
+	}
+	t.Cleanup(database.CloseDB)
+	target := database.Target{InputName: "example.com", Workspace: "example.com"}
+	database.SaveTarget(&target)
+	database.ImportFindings([]database.Finding{{CheckID: "git-config", MatchedAt: "https://example.com/.git/config", Severity: "medium", TargetRefer: target.ID}})
+
+	saved := options
+	t.Cleanup(func() { options = saved })
+
+	defaults := map[string]string{"changes": "table", "query": "table", "export": "json", "compare": "table"}
+	for name, format := range defaults {
+		cmd, _, err := RootCmd.Find([]string{"report", name})
+		if err != nil || cmd.Name() != name {
+			t.Fatalf("Error finding the report %v command: %v", name, err)
+		}
+		if err := cmd.PreRunE(cmd, nil); err != nil || options.Report.Format != format {
+			t.Errorf("Error the default format of the report %v command: %v %v", name, options.Report.Format, err)
+		}
+	}
+
+	// run the export without the --format flag
+	cmd, _, _ := RootCmd.Find([]string{"report", "export"})
+	output := path.Join(t.TempDir(), "report.json")
+	options.Scan.Inputs = []string{"example.com"}
+	options.Report.Output = output
+	if err := cmd.ParseFlags([]string{"-o", output}); err != nil {
+		t.Fatalf("Error parsing the flags: %v", err)
+	}
+	if err := cmd.PreRunE(cmd, nil); err != nil {
+		t.Fatalf("Error PreRunE: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("Error the export without --format: %v", err)
+	}
+	if content := utils.GetFileContent(output); !strings.Contains(content, `"git-config"`) {
+		t.Errorf("Error the json export: %v", content)
+	}
+}
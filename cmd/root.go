@@ -8,6 +8,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/whoamikiddie/vulnx/core"
+	"github.com/whoamikiddie/vulnx/database"
 	"github.com/whoamikiddie/vulnx/libs"
 	"github.com/whoamikiddie/vulnx/utils"
 )
@@ -51,6 +52,8 @@ func init() {
 	RootCmd.PersistentFlags().IntVarP(&options.Threads, "threads-hold", "B", 0, "Threads hold for each module (default: number of CPUs)")
 	RootCmd.PersistentFlags().StringVar(&options.Tactics, "tactic", "default", "Choosing the tactic for running workflow from [default aggressive gently]")
 	RootCmd.PersistentFlags().StringVar(&options.Scan.ParamsFile, "params-file", "", "Custom file params --params-file=params.yaml")
+	RootCmd.PersistentFlags().StringVar(&options.Scan.CallbackURL, "callback-url", "", "URL to post the scan summary to when the scan is finished or failed (default: $VULNX_CALLBACK_URL)")
+	RootCmd.PersistentFlags().StringVar(&options.Scan.CallbackSecret, "callback-secret", "", "Secret to sign the callback body with HMAC-SHA256 (default: $VULNX_CALLBACK_SECRET)")
 
 	// cloud flags
 	RootCmd.PersistentFlags().BoolVar(&options.Cloud.EnableChunk, "chunk", false, "Enable chunk mode")
@@ -82,6 +85,7 @@ func init() {
 	// disable options
 	RootCmd.PersistentFlags().BoolVar(&options.NoNoti, "nn", false, "No notification")
 	RootCmd.PersistentFlags().BoolVar(&options.NoBanner, "nb", false, "No banner")
+	RootCmd.PersistentFlags().BoolVar(&options.NoDB, "no-db", false, "Only store the scan records in the runtime files instead of the database")
 	RootCmd.PersistentFlags().BoolVarP(&options.NoGit, "no-git", "N", false, "No git storage")
 	RootCmd.PersistentFlags().BoolVarP(&options.NoClean, "no-clean", "C", false, "No clean junk output")
 	RootCmd.PersistentFlags().BoolVar(&options.NoPreRun, "no-prerun", false, "Disable pre run scripts")
@@ -127,6 +131,17 @@ func initConfig() {
 		utils.BlockF("fatal", "Make sure you are login as 'root user' if your installation done via root user")
 	}
 	core.ParsingConfig(&options)
+	if _, err := database.InitDB(options); err != nil {
+		utils.WarnF("Unable to open the database, the scan records will only be stored in the runtime files")
+	}
+
+	// the callback of the scan started by the queue or the server comes from the environment
+	if options.Scan.CallbackURL == "" {
+		options.Scan.CallbackURL = os.Getenv(libs.CallbackURLEnv)
+	}
+	if options.Scan.CallbackSecret == "" {
+		options.Scan.CallbackSecret = os.Getenv(libs.CallbackSecretEnv)
+	}
 
 	// parse inputs
 	if options.Scan.InputList != "" {
@@ -1,14 +1,18 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"path"
 	"strings"
 	"sync"
 
 	"github.com/fatih/color"
+	jsoniter "github.com/json-iterator/go"
 	"github.com/panjf2000/ants"
 	"github.com/spf13/cobra"
 	"github.com/whoamikiddie/vulnx/core"
+	"github.com/whoamikiddie/vulnx/execution"
 	"github.com/whoamikiddie/vulnx/libs"
 	"github.com/whoamikiddie/vulnx/utils"
 	"golang.org/x/text/cases"
@@ -20,9 +24,28 @@ func init() {
 		Use:   "scan",
 		Short: "Conduct a scan following a predetermined flow/module",
 		Long:  core.Banner(),
-		RunE:  runScan,
+		RunE:  withAudit("cli scan", runScan),
 	}
 
+	// control the running scans, the argument could be the workspace or the scan ID
+	for _, action := range []string{core.ScanCancel, core.ScanPause, core.ScanResume} {
+		scanCmd.AddCommand(&cobra.Command{
+			Use:   fmt.Sprintf("%v <workspace|scan-id>...", action),
+			Short: fmt.Sprintf("%v the running scans", cases.Title(language.Und).String(action)),
+			Long:  core.Banner(),
+			Args:  cobra.MinimumNArgs(1),
+			RunE:  withAudit("cli scan "+action, runScanControl(action)),
+		})
+	}
+
+	scanCmd.AddCommand(&cobra.Command{
+		Use:   "attach <workspace|scan-id>",
+		Short: "Follow the live events of the scan",
+		Long:  core.Banner(),
+		Args:  cobra.ExactArgs(1),
+		RunE:  runScanAttach,
+	})
+
 	scanCmd.SetHelpFunc(ScanHelp)
 	RootCmd.AddCommand(scanCmd)
 	scanCmd.PreRun = func(cmd *cobra.Command, args []string) {
@@ -33,10 +56,59 @@ func init() {
 	}
 }
 
+func runScanControl(action string) func(*cobra.Command, []string) error {
+	return func(_ *cobra.Command, args []string) error {
+		for _, target := range args {
+			if _, err := core.ControlScan(target, action, options); err != nil {
+				utils.ErrorF("%v", err)
+			}
+		}
+		return nil
+	}
+}
+
+func runScanAttach(_ *cobra.Command, args []string) error {
+	workspace, _, err := core.ResolveScan(args[0])
+	if err != nil {
+		return err
+	}
+	workspaceFolder := path.Join(utils.NormalizePath(options.Env.WorkspacesFolder), workspace)
+	if !utils.FolderExists(workspaceFolder) {
+		return fmt.Errorf("workspace %v not found", workspace)
+	}
+	utils.InforF("Attaching to the scan of the workspace %v", color.HiCyanString(workspace))
+	return core.FollowEvents(workspaceFolder, 0, nil, printScanEvent, nil)
+}
+
+// printScanEvent print the event of the scan, the raw JSON is printed with the --json flag
+func printScanEvent(event core.ScanEvent) error {
+	if options.JsonOutput {
+		data, err := jsoniter.MarshalToString(event)
+		if err == nil {
+			fmt.Println(data)
+		}
+		return err
+	}
+
+	progress := color.HiMagentaString("[%v/%v]", event.DoneStep, event.TotalSteps)
+	switch event.Type {
+	case core.EventProgress:
+		fmt.Printf("%v %v %v %v\n", event.Time.Format("15:04:05"), progress, color.HiBlueString("progress"), color.HiGreenString(event.Module))
+	case core.EventState:
+		fmt.Printf("%v %v %v %v\n", event.Time.Format("15:04:05"), progress, color.HiYellowString(event.State), event.Message)
+	default:
+		fmt.Printf("%v %v %v %v\n", event.Time.Format("15:04:05"), progress, color.HiCyanString(event.Type), event.Message)
+	}
+	return nil
+}
+
 func runScan(_ *cobra.Command, _ []string) error {
 	utils.GoodF("Using the %v Engine %v by %v", cases.Title(language.Und, cases.NoLower).String(libs.BINARY), color.HiCyanString(libs.VERSION), color.HiMagentaString(libs.AUTHOR))
 	utils.InforF("Storing the log file to: %v", color.CyanString(options.LogFile))
 
+	// retry the notifications in the background so a network blip doesn't drop them
+	execution.StartOutboxSender(options)
+
 	var wg sync.WaitGroup
 	p, _ := ants.NewPoolWithFunc(options.Concurrency, func(i interface{}) {
 		// really start to scan
@@ -61,6 +133,11 @@ func runScan(_ *cobra.Command, _ []string) error {
 	}
 
 	wg.Wait()
+	// send the batched notifications before exiting
+	execution.FlushNotifications()
+	if !execution.WaitCallbacks(execution.CallbackExitWait) {
+		utils.WarnF("Some callbacks could not be delivered within %v", execution.CallbackExitWait)
+	}
 	return nil
 }
 
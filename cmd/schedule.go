@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/whoamikiddie/vulnx/core"
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+func init() {
+	var scheduleCmd = &cobra.Command{
+		Use:     "schedule",
+		Aliases: []string{"sched", "schedules"},
+		Short:   "Manage the cron schedules which add the scans to the queue",
+		Long:    core.Banner(),
+		RunE:    runScheduleList,
+	}
+
+	var addCmd = &cobra.Command{
+		Use:   "add",
+		Short: "Add a new schedule",
+		Long:  core.Banner(),
+		RunE:  runScheduleAdd,
+	}
+	addCmd.Flags().StringVar(&options.Schedule.Name, "name", "", "Name of the schedule")
+	addCmd.Flags().StringVar(&options.Schedule.Expression, "cron", "", "Standard cron expression (e.g: '0 3 * * *', '@daily')")
+	addCmd.Flags().StringVar(&options.Schedule.Timezone, "tz", "", "Time zone of the cron expression (e.g: Europe/Paris), default is the local time zone")
+	addCmd.Flags().IntVar(&options.Schedule.Jitter, "jitter", 0, "Random delay in seconds added to every run")
+	addCmd.Flags().IntVar(&options.Queue.Priority, "priority", 0, "Priority of the jobs of the schedule")
+	addCmd.Flags().BoolVarP(&options.Queue.InputAsFile, "as-file", "F", false, "treat input as a file")
+	addCmd.MarkFlagRequired("name")
+	addCmd.MarkFlagRequired("cron")
+	scheduleCmd.AddCommand(addCmd)
+
+	var listCmd = &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the schedules",
+		Long:    core.Banner(),
+		RunE:    runScheduleList,
+	}
+	scheduleCmd.AddCommand(listCmd)
+
+	var pauseCmd = &cobra.Command{
+		Use:   "pause",
+		Short: "Pause the schedules",
+		Long:  core.Banner(),
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runSchedulePause,
+	}
+	scheduleCmd.AddCommand(pauseCmd)
+
+	var resumeCmd = &cobra.Command{
+		Use:   "resume",
+		Short: "Resume the paused schedules",
+		Long:  core.Banner(),
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runScheduleResume,
+	}
+	scheduleCmd.AddCommand(resumeCmd)
+
+	var deleteCmd = &cobra.Command{
+		Use:     "delete",
+		Aliases: []string{"del", "rm"},
+		Short:   "Delete the schedules",
+		Long:    core.Banner(),
+		Args:    cobra.MinimumNArgs(1),
+		RunE:    runScheduleDelete,
+	}
+	scheduleCmd.AddCommand(deleteCmd)
+	RootCmd.AddCommand(scheduleCmd)
+
+	var schedulerCmd = &cobra.Command{
+		Use:   "scheduler",
+		Short: "Run the scheduler daemon which adds the jobs of the due schedules to the queue",
+		Long:  core.Banner(),
+		RunE:  runScheduler,
+	}
+	RootCmd.AddCommand(schedulerCmd)
+}
+
+func runScheduleAdd(_ *cobra.Command, _ []string) error {
+	// the list of targets is copied to the input folder, the copy is read again on every run
+	var input string
+	if options.Queue.InputAsFile && options.Scan.InputList != "" {
+		inputList := utils.NormalizePath(options.Scan.InputList)
+		if !utils.FileExists(inputList) {
+			return fmt.Errorf("the list of targets %v not found", inputList)
+		}
+		if input = core.SaveTargets(utils.ReadingFileUnique(inputList)); input == "" {
+			return fmt.Errorf("failed to copy %v to the input folder", inputList)
+		}
+	} else {
+		if len(options.Scan.Inputs) != 1 {
+			return fmt.Errorf("the schedule requires exactly one input, please use the -t flag or -T with --as-file")
+		}
+		input = options.Scan.Inputs[0]
+	}
+	schedule := database.Schedule{
+		Name:        options.Schedule.Name,
+		CronExpr:    options.Schedule.Expression,
+		Timezone:    options.Schedule.Timezone,
+		Jitter:      options.Schedule.Jitter,
+		Input:       input,
+		InputAsFile: options.Queue.InputAsFile,
+		Flow:        options.Scan.Flow,
+		Modules:     options.Scan.Modules,
+		Params:      options.Scan.Params,
+		Workspace:   options.Scan.CustomWorkspace,
+		Priority:    options.Queue.Priority,
+	}
+	if err := core.AddSchedule(&schedule, options); err != nil {
+		return err
+	}
+	utils.InforF("Added the schedule %v, the first run is at %v", color.HiCyanString(schedule.Name),
+		color.HiMagentaString(schedule.NextRun.Format(time.RFC3339)))
+	return nil
+}
+
+func runScheduleList(_ *cobra.Command, _ []string) error {
+	schedules, err := database.ListSchedules()
+	if err != nil {
+		return err
+	}
+	if options.JsonOutput {
+		for _, schedule := range schedules {
+			if data, err := jsoniter.MarshalToString(schedule); err == nil {
+				fmt.Println(data)
+			}
+		}
+		return nil
+	}
+
+	var content [][]string
+	for _, schedule := range schedules {
+		routine := schedule.Flow
+		if len(schedule.Modules) > 0 {
+			routine = strings.Join(schedule.Modules, ",")
+		}
+		state := color.HiGreenString("active")
+		if schedule.Paused {
+			state = color.HiYellowString("paused")
+		}
+		var nextRun, lastRun string
+		if schedule.NextRun != nil && !schedule.Paused {
+			nextRun = schedule.NextRun.Format(time.RFC3339)
+		}
+		if schedule.LastRun != nil {
+			lastRun = fmt.Sprintf("%v (%v)", schedule.LastRun.Format(time.RFC3339), schedule.LastStatus)
+		}
+		expr := schedule.CronExpr
+		if schedule.Timezone != "" {
+			expr += " " + schedule.Timezone
+		}
+		content = append(content, []string{schedule.Name, expr, schedule.Input, routine, state, nextRun, lastRun})
+	}
+	table := tablewriter.NewWriter(os.Stderr)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeader([]string{"Name", "Cron", "Input", "Flow/Module", "State", "Next Run", "Last Run"})
+	table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+	table.SetColWidth(60)
+	table.AppendBulk(content)
+	table.Render()
+	return nil
+}
+
+func runSchedulePause(_ *cobra.Command, args []string) error {
+	for _, name := range args {
+		if _, err := core.PauseSchedule(name, true); err != nil {
+			utils.ErrorF("%v", err)
+			continue
+		}
+		utils.InforF("Paused the schedule %v", color.HiCyanString(name))
+	}
+	return nil
+}
+
+func runScheduleResume(_ *cobra.Command, args []string) error {
+	for _, name := range args {
+		schedule, err := core.PauseSchedule(name, false)
+		if err != nil {
+			utils.ErrorF("%v", err)
+			continue
+		}
+		utils.InforF("Resumed the schedule %v, the next run is at %v", color.HiCyanString(name),
+			color.HiMagentaString(schedule.NextRun.Format(time.RFC3339)))
+	}
+	return nil
+}
+
+func runScheduleDelete(_ *cobra.Command, args []string) error {
+	for _, name := range args {
+		if err := database.DeleteSchedule(name); err != nil {
+			utils.ErrorF("%v", err)
+			continue
+		}
+		utils.InforF("Deleted the schedule %v", color.HiCyanString(name))
+	}
+	return nil
+}
+
+func runScheduler(_ *cobra.Command, _ []string) error {
+	if database.DB == nil {
+		return fmt.Errorf("the scheduler requires the database")
+	}
+	core.StartScheduler(options)
+	// Block main goroutine forever.
+	<-make(chan struct{})
+	return nil
+}
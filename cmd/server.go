@@ -7,6 +7,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/whoamikiddie/vulnx/core"
+	"github.com/whoamikiddie/vulnx/execution"
 	"github.com/whoamikiddie/vulnx/libs"
 	"github.com/whoamikiddie/vulnx/server"
 	"github.com/whoamikiddie/vulnx/utils"
@@ -24,6 +25,8 @@ func init() {
 	serverCmd.Flags().String("host", "0.0.0.0", "IP address to bind the server")
 	serverCmd.Flags().String("port", "8000", "Port")
 	serverCmd.Flags().IntVar(&options.Server.PollingTime, "poll-time", 60, "Polling time to check next task")
+	serverCmd.Flags().StringVar(&options.Server.MetricsBind, "metrics-bind", "", "Serve the Prometheus metrics without the rest of the API on this address (e.g: 127.0.0.1:9110)")
+	serverCmd.Flags().IntVar(&options.Server.Executors, "executors", 2, "Number of the queued scans run by the server at the same time (0 leaves them to the remote workers)")
 	serverCmd.Flags().BoolVar(&options.Server.DisableSSL, "disable-ssl", false, "Disable workspaces directory listing")
 	serverCmd.Flags().BoolVar(&options.Server.DisableWorkspaceListing, "disable-listing", false, "Disable workspaces directtory listing")
 	serverCmd.Flags().BoolVar(&options.Server.PreFork, "prefork", false, "Enable Prefork mode for the api server")
@@ -44,6 +47,8 @@ func runServer(cmd *cobra.Command, _ []string) error {
 	port, _ := cmd.Flags().GetString("port")
 	options.Server.Bind = fmt.Sprintf("%v:%v", host, port)
 	utils.GoodF("Using the %v Engine %v by %v", cases.Title(language.Und, cases.NoLower).String(libs.BINARY), color.HiCyanString(libs.VERSION), color.HiMagentaString(libs.AUTHOR))
+	// deliver the notifications left in the outbox while the server is running
+	execution.StartOutboxSender(options)
 	server.StartServer(options)
 	return nil
 }
@@ -105,6 +105,13 @@ func ScanUsage() string {
 	h += "  osmedeus scan -m ~/.osmedeus/core/workflow/test/dirbscan.yaml -t list_of_urls.txt\n"
 	h += "  osmedeus scan --wfFolder ~/custom-workflow/ -f your-custom-workflow -t list_of_urls.txt\n"
 	h += "  osmedeus scan --chunk --chunk-part 40 -c 2 -f cidr -t list-of-cidr.txt\n"
+	h += "  osmedeus scan -f general -t sample.com --callback-url https://ci.example.com/hook --callback-secret s3cret\n"
+
+	h += color.HiCyanString("\nScan Control Usage:\n")
+	h += "  osmedeus scan pause sample.com\n"
+	h += "  osmedeus scan resume sample.com\n"
+	h += "  osmedeus scan cancel sample.com 42\n"
+	h += "  osmedeus scan attach sample.com\n"
 	return h
 }
 
@@ -128,6 +135,13 @@ func UtilsUsage() string {
 	h += "  osmedeus update --force --update-url https://very-long-url/premium.sh\n"
 	h += "\n"
 
+	h += color.HiBlueString("  ## Notification outbox utilities\n")
+	h += "  osmedeus utils outbox list\n"
+	h += "  osmedeus utils outbox retry\n"
+	h += "  osmedeus utils outbox retry 1700000000000000000-abcdef\n"
+	h += "  osmedeus utils outbox purge\n"
+	h += "\n"
+
 	h += color.HiBlueString("  ## Workflow utilities\n")
 	h += "  osmedeus workflow list \n"
 	h += "  osmedeus workflow view -f general\n"
@@ -173,8 +187,26 @@ func ConfigUsage() string {
 
 func QueueUsage() string {
 	h := color.HiCyanString("\nQueue Usage:\n")
-	h += "  osmedeus queue -Q /tmp/queue-file.txt -c 2\n"
-	h += "  osmedeus queue --add -t example.com -Q /tmp/queue-file.txt \n"
+	h += "  osmedeus queue -c 2\n"
+	h += "  osmedeus queue add -t example.com -f general --priority 10\n"
+	h += "  osmedeus queue add -T list_of_targets.txt -m content-discovery\n"
+	h += "  osmedeus queue add -t example.com --cmd 'osmedeus scan -t {{.input}} -f vuln'\n"
+	h += "  osmedeus queue list --state pending --state failed\n"
+	h += "  osmedeus queue cancel 12 13\n"
+	h += "  osmedeus queue retry 12\n"
+
+	h += color.HiCyanString("\nWorker Usage:\n")
+	h += "  osmedeus worker --server https://master:8000 --username osmedeus --password xxx -c 2\n"
+	h += "  osmedeus worker --server https://master:8000 --worker-token xxx --name onprem-1 --keep\n"
+
+	h += color.HiCyanString("\nSchedule Usage:\n")
+	h += "  osmedeus schedule add --name nightly -t example.com -f general --cron '0 2 * * *' --tz Europe/Paris\n"
+	h += "  osmedeus schedule add --name weekly-list -T list_of_targets.txt -F -m content-discovery --cron '@weekly' --jitter 600\n"
+	h += "  osmedeus schedule list\n"
+	h += "  osmedeus schedule pause nightly\n"
+	h += "  osmedeus schedule resume nightly\n"
+	h += "  osmedeus schedule delete nightly\n"
+	h += "  osmedeus scheduler\n"
 	return h
 }
 
@@ -210,6 +242,23 @@ func ReportUsage() string {
 	h += "  osmedeus report view --raw -t target.com\n"
 	h += "  osmedeus report view --static -t target.com\n"
 	h += "  osmedeus report view --static --ip 0 -t target.com\n"
+	h += "  osmedeus report changes -t target.com\n"
+	h += "  osmedeus report changes -t target.com --since 2023-01-30 --format json\n"
+	h += "  osmedeus report query 'type=vuln severity>=high host~\"*.api.*\"'\n"
+	h += "  osmedeus report query 'type=http tech~jenkins' --format csv\n"
+	h += "  osmedeus report query -t target.com 'type=port port=8080' --format json\n"
+	h += "  osmedeus report export -t target.com --format sarif -o findings.sarif\n"
+	h += "  osmedeus report export -t target.com --format csv\n"
+	h += "  osmedeus report compare staging.target.com target.com\n"
+	h += "  osmedeus report compare target.com target.com-last-month --format markdown -o diff.md\n"
+	return h
+}
+
+func DBUsage() string {
+	h := color.HiCyanString("\nDatabase Usage:\n")
+	h += "  osmedeus db import\n"
+	h += "  osmedeus db import -t target.com\n"
+	h += "  osmedeus db import -T list-of-workspaces.txt\n"
 	return h
 }
 
@@ -218,9 +267,53 @@ func ServerUsage() string {
 	h += "  osmedeus server --port 5000\n"
 	h += "  osmedeus server --disable-ssl\n"
 	h += "  osmedeus server -A --disable-ssl\n"
+	h += "  osmedeus server --executors 4\n"
+	h += "  osmedeus server --executors 0 (the scans are only run by the remote workers)\n"
+	h += "  osmedeus server --metrics-bind 127.0.0.1:9110\n"
+
+	h += color.HiCyanString("\nUser Usage:\n")
+	h += "  osmedeus user add alice --password xxx --role operator\n"
+	h += "  osmedeus user list\n"
+	h += "  osmedeus user update alice --role viewer\n"
+	h += "  osmedeus user update alice --disable\n"
+	h += "  osmedeus user delete alice\n"
+	h += "  osmedeus user token create alice --name ci --ttl 90\n"
+	h += "  osmedeus user token list alice\n"
+	h += "  osmedeus user token revoke 3\n"
+
+	h += color.HiCyanString("\nAudit Usage:\n")
+	h += "  osmedeus audit --since 24h\n"
+	h += "  osmedeus audit --actor alice --since 2024-01-02T00:00:00Z --until 2024-01-03T00:00:00Z\n"
+	h += "  osmedeus audit --action 'cli scan' --json\n"
 	return h
 }
 
+// RemoteUsage usage of the remote commands
+func RemoteUsage() string {
+	h := color.HiCyanString("\nRemote Usage:\n")
+	h += "  osmedeus remote login https://master:8000 --username osmedeus --password xxx --insecure\n"
+	h += "  osmedeus remote login https://master:8000 --api-token vx_xxx\n"
+	h += "  osmedeus remote scan -t example.com -f general\n"
+	h += "  osmedeus remote scan -T list_of_targets.txt -m content-discovery\n"
+	h += "  osmedeus remote ls --status running --sort findings\n"
+	h += "  osmedeus remote status example.com\n"
+	h += "  osmedeus remote logs example.com -f\n"
+	h += "  osmedeus remote cancel example.com\n"
+	h += "  osmedeus remote pull example.com\n"
+	h += "  osmedeus remote flows\n"
+	h += "  osmedeus remote logout\n"
+	return h
+}
+
+// RemoteHelp remote help message
+func RemoteHelp(cmd *cobra.Command, _ []string) {
+	fmt.Println(core.Banner())
+	fmt.Println(cmd.UsageString())
+	h := RemoteUsage()
+	fmt.Println(h)
+	printDocs(cmd)
+}
+
 func QueueHelp(cmd *cobra.Command, _ []string) {
 	fmt.Println(core.Banner())
 	fmt.Println(cmd.UsageString())
@@ -295,6 +388,17 @@ func ReportHelp(cmd *cobra.Command, _ []string) {
 	printDocs(cmd)
 }
 
+// DBHelp database help message
+func DBHelp(cmd *cobra.Command, _ []string) {
+	fmt.Println(core.Banner())
+	if options.FullHelp {
+		fmt.Println(cmd.UsageString())
+	}
+	h := DBUsage()
+	fmt.Println(h)
+	printDocs(cmd)
+}
+
 // RootHelp print help message
 func RootHelp(cmd *cobra.Command, _ []string) {
 	fmt.Println(core.Banner())
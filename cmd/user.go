@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+	"github.com/whoamikiddie/vulnx/core"
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+func init() {
+	var userCmd = &cobra.Command{
+		Use:     "user",
+		Aliases: []string{"users"},
+		Short:   "Manage the users and the API tokens of the server",
+		Long:    core.Banner(),
+		RunE:    runUserList,
+	}
+
+	var addCmd = &cobra.Command{
+		Use:   "add",
+		Short: "Add a new user",
+		Long:  core.Banner(),
+		Args:  cobra.ExactArgs(1),
+		RunE:  runUserAdd,
+	}
+	addCmd.Flags().StringVar(&options.Account.Password, "password", "", "Password of the user")
+	addCmd.Flags().StringVar(&options.Account.Role, "role", database.RoleViewer, "Role of the user (viewer, operator or admin)")
+	addCmd.MarkFlagRequired("password")
+	userCmd.AddCommand(addCmd)
+
+	var listCmd = &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the users",
+		Long:    core.Banner(),
+		RunE:    runUserList,
+	}
+	userCmd.AddCommand(listCmd)
+
+	var updateCmd = &cobra.Command{
+		Use:   "update",
+		Short: "Change the password, the role or the state of the user",
+		Long:  core.Banner(),
+		Args:  cobra.ExactArgs(1),
+		RunE:  runUserUpdate,
+	}
+	updateCmd.Flags().StringVar(&options.Account.Password, "password", "", "New password of the user")
+	updateCmd.Flags().StringVar(&options.Account.Role, "role", "", "New role of the user (viewer, operator or admin)")
+	updateCmd.Flags().BoolVar(&options.Account.Disabled, "disable", false, "Disable the user")
+	updateCmd.Flags().BoolVar(&options.Account.Enabled, "enable", false, "Enable the disabled user")
+	userCmd.AddCommand(updateCmd)
+
+	var deleteCmd = &cobra.Command{
+		Use:     "delete",
+		Aliases: []string{"del", "rm"},
+		Short:   "Delete the users and their API tokens",
+		Long:    core.Banner(),
+		Args:    cobra.MinimumNArgs(1),
+		RunE:    runUserDelete,
+	}
+	userCmd.AddCommand(deleteCmd)
+
+	var tokenCmd = &cobra.Command{
+		Use:     "token",
+		Aliases: []string{"tokens"},
+		Short:   "Manage the API tokens of the users",
+		Long:    core.Banner(),
+		RunE:    runTokenList,
+	}
+	userCmd.AddCommand(tokenCmd)
+
+	var tokenCreateCmd = &cobra.Command{
+		Use:   "create",
+		Short: "Create a new API token for the user",
+		Long:  core.Banner(),
+		Args:  cobra.ExactArgs(1),
+		RunE:  runTokenCreate,
+	}
+	tokenCreateCmd.Flags().StringVar(&options.Account.TokenName, "name", "", "Name of the token")
+	tokenCreateCmd.Flags().IntVar(&options.Account.TokenTTL, "ttl", 0, "Number of days before the token expires, 0 means never")
+	tokenCmd.AddCommand(tokenCreateCmd)
+
+	var tokenListCmd = &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the API tokens, only the tokens of the user are listed if it's given",
+		Long:    core.Banner(),
+		RunE:    runTokenList,
+	}
+	tokenCmd.AddCommand(tokenListCmd)
+
+	var tokenRevokeCmd = &cobra.Command{
+		Use:   "revoke",
+		Short: "Revoke the API tokens by their ID",
+		Long:  core.Banner(),
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runTokenRevoke,
+	}
+	tokenCmd.AddCommand(tokenRevokeCmd)
+	RootCmd.AddCommand(userCmd)
+}
+
+func runUserAdd(_ *cobra.Command, args []string) error {
+	user, err := database.CreateUser(args[0], options.Account.Password, options.Account.Role)
+	if err != nil {
+		return err
+	}
+	utils.InforF("Added the user %v with the %v role", color.HiCyanString(user.Username), color.HiMagentaString(user.Role))
+	return nil
+}
+
+func runUserList(_ *cobra.Command, _ []string) error {
+	users, err := database.ListUsers()
+	if err != nil {
+		return err
+	}
+	if options.JsonOutput {
+		for _, user := range users {
+			if data, err := jsoniter.MarshalToString(user); err == nil {
+				fmt.Println(data)
+			}
+		}
+		return nil
+	}
+
+	var content [][]string
+	for _, user := range users {
+		state := color.HiGreenString("active")
+		if user.Disabled {
+			state = color.HiYellowString("disabled")
+		}
+		content = append(content, []string{user.Username, user.Role, state, user.CreatedAt.Format(time.RFC3339)})
+	}
+	table := tablewriter.NewWriter(os.Stderr)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeader([]string{"Username", "Role", "State", "Created"})
+	table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+	table.AppendBulk(content)
+	table.Render()
+	return nil
+}
+
+func runUserUpdate(_ *cobra.Command, args []string) error {
+	var disabled *bool
+	if options.Account.Disabled || options.Account.Enabled {
+		if options.Account.Disabled && options.Account.Enabled {
+			return fmt.Errorf("--disable and --enable can't be used together")
+		}
+		disabled = &options.Account.Disabled
+	}
+	user, err := database.UpdateUser(args[0], options.Account.Password, options.Account.Role, disabled)
+	if err != nil {
+		return err
+	}
+	utils.InforF("Updated the user %v", color.HiCyanString(user.Username))
+	return nil
+}
+
+func runUserDelete(_ *cobra.Command, args []string) error {
+	for _, username := range args {
+		if err := database.DeleteUser(username); err != nil {
+			utils.ErrorF("%v", err)
+			continue
+		}
+		utils.InforF("Deleted the user %v", color.HiCyanString(username))
+	}
+	return nil
+}
+
+func runTokenCreate(_ *cobra.Command, args []string) error {
+	ttl := time.Duration(options.Account.TokenTTL) * 24 * time.Hour
+	raw, token, err := database.CreateAPIToken(args[0], options.Account.TokenName, ttl)
+	if err != nil {
+		return err
+	}
+	utils.InforF("Created the API token %v for the user %v, it won't be shown again", color.HiCyanString("%v", token.ID), color.HiCyanString(args[0]))
+	fmt.Println(raw)
+	return nil
+}
+
+func runTokenList(_ *cobra.Command, args []string) error {
+	var username string
+	if len(args) > 0 {
+		username = args[0]
+	}
+	tokens, err := database.ListAPITokens(username)
+	if err != nil {
+		return err
+	}
+	if options.JsonOutput {
+		for _, token := range tokens {
+			if data, err := jsoniter.MarshalToString(token); err == nil {
+				fmt.Println(data)
+			}
+		}
+		return nil
+	}
+
+	usernames := make(map[uint]string)
+	if users, err := database.ListUsers(); err == nil {
+		for _, user := range users {
+			usernames[user.ID] = user.Username
+		}
+	}
+	var content [][]string
+	for _, token := range tokens {
+		state := color.HiGreenString("active")
+		if token.Revoked {
+			state = color.HiRedString("revoked")
+		} else if token.ExpiresAt != nil && token.ExpiresAt.Before(time.Now()) {
+			state = color.HiYellowString("expired")
+		}
+		var expires, lastUsed string
+		if token.ExpiresAt != nil {
+			expires = token.ExpiresAt.Format(time.RFC3339)
+		}
+		if token.LastUsedAt != nil {
+			lastUsed = token.LastUsedAt.Format(time.RFC3339)
+		}
+		content = append(content, []string{cast.ToString(token.ID), usernames[token.UserID], token.Name, token.Prefix + "...", state, expires, lastUsed})
+	}
+	table := tablewriter.NewWriter(os.Stderr)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeader([]string{"ID", "User", "Name", "Token", "State", "Expires", "Last Used"})
+	table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+	table.AppendBulk(content)
+	table.Render()
+	return nil
+}
+
+func runTokenRevoke(_ *cobra.Command, args []string) error {
+	for _, id := range args {
+		if err := database.RevokeAPIToken(cast.ToUint(id), ""); err != nil {
+			utils.ErrorF("%v", err)
+			continue
+		}
+		utils.InforF("Revoked the API token %v", color.HiCyanString(id))
+	}
+	return nil
+}
@@ -3,9 +3,11 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/fatih/color"
 	jsoniter "github.com/json-iterator/go"
+	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	"github.com/whoamikiddie/vulnx/core"
 	"github.com/whoamikiddie/vulnx/execution"
@@ -54,11 +56,19 @@ func init() {
 	cronCmd.Flags().BoolVar(&options.Cron.Forever, "for", false, "Keep running forever right after the command done")
 	cronCmd.Flags().StringVar(&options.Cron.Command, "cmd", "", "Command to run")
 
+	var outboxCmd = &cobra.Command{
+		Use:   "outbox",
+		Short: "Utility to list, retry or purge the pending notifications",
+		Long:  core.Banner(),
+		RunE:  runOutbox,
+	}
+
 	// add command
 	utilsCmd.PersistentFlags().BoolVar(&options.JsonOutput, "json", false, "Output as JSON")
 	utilsCmd.AddCommand(cronCmd)
 	utilsCmd.AddCommand(tmuxCmd)
 	utilsCmd.AddCommand(psCmd)
+	utilsCmd.AddCommand(outboxCmd)
 	utilsCmd.SetHelpFunc(UtilsHelp)
 	RootCmd.AddCommand(utilsCmd)
 
@@ -137,3 +147,65 @@ func runCron(_ *cobra.Command, _ []string) error {
 	core.RunCron(options.Cron.Command, options.Cron.Schedule)
 	return nil
 }
+
+func runOutbox(_ *cobra.Command, args []string) error {
+	outbox := execution.GetOutbox(options)
+	if outbox == nil {
+		return fmt.Errorf("root folder is not set")
+	}
+
+	action := "list"
+	if len(args) > 0 {
+		action = args[0]
+		args = args[1:]
+	}
+	switch action {
+	case "retry", "r":
+		sent, failed, err := outbox.Retry(args...)
+		if err != nil {
+			return err
+		}
+		utils.InforF("Delivered %v notifications, %v still failed", color.HiGreenString("%v", sent), color.HiRedString("%v", failed))
+	case "purge", "clean":
+		count, err := outbox.Purge(args...)
+		if err != nil {
+			return err
+		}
+		utils.InforF("Purged %v notifications from the outbox", color.HiMagentaString("%v", count))
+	default:
+		entries, err := outbox.List()
+		if err != nil {
+			return err
+		}
+		if options.JsonOutput {
+			for _, entry := range entries {
+				if data, err := jsoniter.MarshalToString(entry); err == nil {
+					fmt.Println(data)
+				}
+			}
+			return nil
+		}
+
+		var content [][]string
+		for _, entry := range entries {
+			title := entry.Message.Title
+			if title == "" {
+				title = entry.Message.Content
+			}
+			if len(title) > 60 {
+				title = title[:60] + "..."
+			}
+			content = append(content, []string{entry.ID, entry.Notifier, entry.Message.Class, title, entry.Status,
+				fmt.Sprintf("%v", entry.Attempts), entry.NextAttempt.Format(time.RFC3339), entry.LastError})
+		}
+		table := tablewriter.NewWriter(os.Stderr)
+		table.SetAutoFormatHeaders(false)
+		table.SetHeader([]string{"ID", "Notifier", "Class", "Message", "Status", "Attempts", "Next Attempt", "Last Error"})
+		table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+		table.SetColWidth(60)
+		table.AppendBulk(content)
+		table.Render()
+		fmt.Println(color.HiGreenString("📨 Pending Notifications: ") + color.HiMagentaString("%v", len(entries)))
+	}
+	return nil
+}
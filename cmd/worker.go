@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/whoamikiddie/vulnx/core"
+	"github.com/whoamikiddie/vulnx/execution"
+)
+
+func init() {
+	var workerCmd = &cobra.Command{
+		Use:   "worker",
+		Short: "Pull the jobs from the queue of a server and run them locally",
+		Long:  core.Banner(),
+		RunE:  runWorker,
+	}
+	workerCmd.Flags().StringVar(&options.Worker.Server, "server", "", "URL of the API server (e.g: https://master:8000)")
+	workerCmd.Flags().StringVar(&options.Worker.Name, "name", "", "Name of the worker (default: hostname and process ID)")
+	workerCmd.Flags().StringVar(&options.Worker.Username, "username", "", "Username to login to the server (default will get from the config file)")
+	workerCmd.Flags().StringVar(&options.Worker.Password, "password", "", "Password to login to the server (default will get from the config file)")
+	workerCmd.Flags().StringVar(&options.Worker.Token, "worker-token", "", "Use the token instead of login with the username and password")
+	workerCmd.Flags().IntVar(&options.Worker.Lease, "lease", 120, "Lease of the job in seconds, the job is re-queued if the worker stops sending the heartbeat")
+	workerCmd.Flags().IntVar(&options.Queue.PollingTime, "poll", 10, "Number of seconds to wait before asking the server again")
+	workerCmd.Flags().BoolVar(&options.Worker.KeepWorkspace, "keep", false, "Keep the workspace on the worker after it's uploaded")
+	workerCmd.MarkFlagRequired("server")
+	RootCmd.AddCommand(workerCmd)
+	workerCmd.PreRun = func(cmd *cobra.Command, args []string) {
+		if options.FullHelp {
+			cmd.Help()
+			os.Exit(0)
+		}
+	}
+}
+
+func runWorker(_ *cobra.Command, _ []string) error {
+	if options.Worker.Username == "" && options.Worker.Password == "" {
+		options.Worker.Username = options.Client.Username
+		options.Worker.Password = options.Client.Password
+	}
+	worker, err := core.NewWorker(options)
+	if err != nil {
+		return err
+	}
+	execution.StartOutboxSender(options)
+	return worker.Start()
+}
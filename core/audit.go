@@ -0,0 +1,164 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/user"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// auditMaxParams the params longer than this are truncated so the huge bodies don't bloat the log
+const auditMaxParams = 4096
+
+// the params with these words in their name are never written to the audit log
+var auditSecrets = []string{"pass", "token", "secret"}
+
+var auditMu sync.Mutex
+
+// AuditFile the JSONL file which the audit entries are appended to when the database is disabled
+func AuditFile(options libs.Options) string {
+	return path.Join(utils.NormalizePath(options.Env.RootFolder), "audit.jsonl")
+}
+
+// Audit append the entry to the audit log, the failure is only logged so it never blocks the action itself
+func Audit(entry database.AuditLog, options libs.Options) {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	if entry.Actor == "" {
+		entry.Actor = "anonymous"
+	}
+	if database.DB != nil {
+		if err := database.RecordAudit(&entry); err != nil {
+			utils.ErrorF("Error writing the audit log: %v", err)
+		}
+		return
+	}
+
+	data, err := jsoniter.MarshalToString(entry)
+	if err != nil {
+		return
+	}
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	filename := AuditFile(options)
+	utils.MakeDir(path.Dir(filename))
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		utils.ErrorF("Error writing the audit log: %v", err)
+		return
+	}
+	defer f.Close()
+	f.WriteString(data + "\n")
+}
+
+// ReadAudit get the audit entries matching the filter from the database or the JSONL file, the newest first
+func ReadAudit(filter database.AuditFilter, options libs.Options) (entries []database.AuditLog, total int64, err error) {
+	if database.DB != nil {
+		return database.QueryAudit(filter)
+	}
+
+	f, err := os.Open(AuditFile(options))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, 0, nil
+		}
+		return entries, 0, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry database.AuditLog
+		if jsoniter.Unmarshal(scanner.Bytes(), &entry) != nil || !filter.Match(entry) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+	total = int64(len(entries))
+	if filter.Offset > 0 {
+		if filter.Offset >= len(entries) {
+			return nil, total, scanner.Err()
+		}
+		entries = entries[filter.Offset:]
+	}
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, total, scanner.Err()
+}
+
+// AuditParams encode the params of the action for the audit log, the secrets are redacted
+func AuditParams(params map[string]interface{}) string {
+	if len(params) == 0 {
+		return ""
+	}
+	redactSecrets(params)
+	data, err := jsoniter.MarshalToString(params)
+	if err != nil {
+		return ""
+	}
+	if len(data) > auditMaxParams {
+		data = data[:auditMaxParams] + "...(truncated)"
+	}
+	return data
+}
+
+func redactSecrets(params map[string]interface{}) {
+	for key, value := range params {
+		if isSecretParam(key) {
+			params[key] = "[redacted]"
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			redactSecrets(nested)
+		}
+	}
+}
+
+func isSecretParam(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range auditSecrets {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseAuditTime parse the RFC3339 time or the duration before now, the empty string is the zero time
+func ParseAuditTime(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if duration, err := time.ParseDuration(raw); err == nil {
+		return time.Now().Add(-duration), nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return t, fmt.Errorf("invalid time %v, please use RFC3339 or a duration like 24h", raw)
+	}
+	return t, nil
+}
+
+// LocalActor the OS user running the CLI
+func LocalActor() string {
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		return current.Username
+	}
+	return os.Getenv("USER")
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/libs"
+)
+
+func TestAuditParams(t *testing.T) {
+	params := AuditParams(map[string]interface{}{
+		"target":   "example.com",
+		"password": "hunter2",
+		"pass":     "hunter2",
+		"nested":   map[string]interface{}{"api_token": "xxx"},
+	})
+	if strings.Contains(params, "hunter2") || strings.Contains(params, "xxx") {
+		t.Errorf("Error AuditParams should redact the secrets: %v", params)
+	}
+	if !strings.Contains(params, "example.com") {
+		t.Errorf("Error AuditParams should keep the other params: %v", params)
+	}
+}
+
+func TestAuditFile(t *testing.T) {
+	var options libs.Options
+	options.Env.RootFolder = t.TempDir()
+
+	// the JSONL file is used when the database is disabled
+	Audit(database.AuditLog{Source: database.AuditSourceCLI, Actor: "alice", Action: "cli scan", Result: "success"}, options)
+	Audit(database.AuditLog{Source: database.AuditSourceCLI, Actor: "bob", Action: "cli config", Result: "success"}, options)
+	Audit(database.AuditLog{Source: database.AuditSourceCLI, Action: "cli cloud", Result: "failed"}, options)
+
+	entries, total, err := ReadAudit(database.AuditFilter{Actor: "alice"}, options)
+	if err != nil || total != 1 || entries[0].Action != "cli scan" {
+		t.Fatalf("Error ReadAudit by actor: %v %v", total, err)
+	}
+	entries, total, _ = ReadAudit(database.AuditFilter{Limit: 2}, options)
+	if total != 3 || len(entries) != 2 {
+		t.Errorf("Error ReadAudit with limit: %v %v", total, len(entries))
+	}
+	if _, _, err := ReadAudit(database.AuditFilter{Actor: "anonymous"}, options); err != nil {
+		t.Errorf("Error ReadAudit: %v", err)
+	}
+	if since, err := ParseAuditTime("24h"); err != nil || since.IsZero() {
+		t.Errorf("Error ParseAuditTime with duration: %v", err)
+	}
+	if _, err := ParseAuditTime("yesterday"); err == nil {
+		t.Errorf("Error ParseAuditTime should reject the invalid time")
+	}
+}
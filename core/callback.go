@@ -0,0 +1,65 @@
+package core
+
+import (
+	"time"
+
+	"github.com/whoamikiddie/vulnx/execution"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// Callback post the summary of the scan to the callback URL, it does nothing if there is no callback URL
+func (r *Runner) Callback(status string, scanErr error) {
+	if r.Opt.Scan.CallbackURL == "" {
+		return
+	}
+	execution.ScanCallback(r.Opt, r.CallbackPayload(status, scanErr))
+}
+
+// CallbackPayload the summary of the scan with the counters and the existing report files
+func (r *Runner) CallbackPayload(status string, scanErr error) execution.CallbackPayload {
+	finishedAt := time.Now()
+	if r.StartTime.IsZero() {
+		r.StartTime = finishedAt
+	}
+	payload := execution.CallbackPayload{
+		Workspace:  r.Workspace,
+		Target:     r.Input,
+		ScanID:     r.ScanObj.ID,
+		Status:     status,
+		StartedAt:  r.StartTime,
+		FinishedAt: finishedAt,
+		Duration:   int(finishedAt.Sub(r.StartTime).Seconds()),
+		Counters:   make(map[string]int),
+		Reports:    []string{},
+	}
+	if r.RoutineType == "flow" {
+		payload.Flow = r.RoutineName
+	} else {
+		payload.Modules = r.Opt.Scan.Modules
+	}
+	if payload.Workspace == "" {
+		payload.Workspace = r.Target["Workspace"]
+	}
+	if scanErr != nil {
+		payload.Error = scanErr.Error()
+	}
+
+	for _, counter := range r.ScanCounters() {
+		payload.Counters[counter.Name] = counter.Total
+	}
+
+	seen := make(map[string]bool)
+	reports := []string{r.ScanObj.MarkDownReport, r.ScanObj.MarkDownSunmmary}
+	for _, report := range r.TargetObj.Reports {
+		reports = append(reports, report.ReportPath)
+	}
+	reports = append(reports, r.Reports...)
+	for _, report := range reports {
+		if report == "" || seen[report] || !utils.FileExists(report) {
+			continue
+		}
+		seen[report] = true
+		payload.Reports = append(payload.Reports, report)
+	}
+	return payload
+}
@@ -0,0 +1,238 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/olekukonko/tablewriter"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// the status of the file when comparing two workspaces
+const (
+	CompareAdded     = "added"
+	CompareRemoved   = "removed"
+	CompareModified  = "modified"
+	CompareIdentical = "identical"
+)
+
+// FileComparison the difference of a report file between two workspaces
+type FileComparison struct {
+	Path    string           `json:"path"`
+	Status  string           `json:"status"`
+	Binary  bool             `json:"binary,omitempty"`
+	Added   int              `json:"added"`
+	Removed int              `json:"removed"`
+	Diff    []utils.DiffLine `json:"diff,omitempty"`
+}
+
+// WorkspaceComparison the difference between two workspaces, files are matched by their relative path
+type WorkspaceComparison struct {
+	Source    string           `json:"source"`
+	Dest      string           `json:"dest"`
+	Added     int              `json:"added"`
+	Removed   int              `json:"removed"`
+	Identical int              `json:"identical"`
+	Files     []FileComparison `json:"files"`
+}
+
+// CompareWorkspaces compare all the files of two workspaces, the line diff is kept when withDiff is set
+func CompareWorkspaces(options libs.Options, source string, dest string, withDiff bool) (comparison WorkspaceComparison, err error) {
+	comparison.Source, comparison.Dest = source, dest
+	sourceFolder, err := resolveWorkspaceFolder(options, source)
+	if err != nil {
+		return comparison, err
+	}
+	destFolder, err := resolveWorkspaceFolder(options, dest)
+	if err != nil {
+		return comparison, err
+	}
+
+	sourceFiles := workspaceFiles(sourceFolder)
+	destFiles := workspaceFiles(destFolder)
+	var files []string
+	for file := range sourceFiles {
+		files = append(files, file)
+	}
+	for file := range destFiles {
+		if !sourceFiles[file] {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		var before, after []byte
+		if sourceFiles[file] {
+			before, _ = os.ReadFile(path.Join(sourceFolder, file))
+		}
+		if destFiles[file] {
+			after, _ = os.ReadFile(path.Join(destFolder, file))
+		}
+
+		result := FileComparison{Path: file, Status: CompareModified}
+		switch {
+		case !destFiles[file]:
+			result.Status = CompareRemoved
+		case !sourceFiles[file]:
+			result.Status = CompareAdded
+		case bytes.Equal(before, after):
+			result.Status = CompareIdentical
+		}
+		if result.Status == CompareIdentical {
+			comparison.Identical++
+			continue
+		}
+
+		if isBinaryContent(before) || isBinaryContent(after) {
+			result.Binary = true
+		} else {
+			lines := utils.DiffLines(splitContentLines(before), splitContentLines(after))
+			result.Added, result.Removed = utils.DiffStat(lines)
+			if withDiff {
+				result.Diff = lines
+			}
+		}
+		comparison.Added += result.Added
+		comparison.Removed += result.Removed
+		comparison.Files = append(comparison.Files, result)
+	}
+	return comparison, nil
+}
+
+// CompareReport print the comparison of two workspaces as a table, markdown or JSON
+func CompareReport(options libs.Options, source string, dest string) (WorkspaceComparison, error) {
+	format := options.Report.Format
+	comparison, err := CompareWorkspaces(options, source, dest, format == "markdown" || format == "json")
+	if err != nil {
+		return comparison, err
+	}
+
+	var out io.Writer = os.Stdout
+	if options.Report.Output != "" {
+		f, err := os.Create(utils.NormalizePath(options.Report.Output))
+		if err != nil {
+			return comparison, err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	switch format {
+	case "json":
+		// only the changed lines are relevant in the JSON output
+		for i, file := range comparison.Files {
+			var changes []utils.DiffLine
+			for _, line := range file.Diff {
+				if line.Kind != utils.DiffEqual {
+					changes = append(changes, line)
+				}
+			}
+			comparison.Files[i].Diff = changes
+		}
+		data, _ := jsoniter.MarshalIndent(comparison, "", "  ")
+		fmt.Fprintln(out, string(data))
+	case "markdown":
+		fmt.Fprint(out, ComparisonToMarkdown(comparison))
+	default:
+		var content [][]string
+		for _, file := range comparison.Files {
+			status := file.Status
+			switch file.Status {
+			case CompareAdded:
+				status = color.HiGreenString(status)
+			case CompareRemoved:
+				status = color.HiRedString(status)
+			}
+			if file.Binary {
+				status += " (binary)"
+			}
+			content = append(content, []string{file.Path, status, color.HiGreenString("+%v", file.Added), color.HiRedString("-%v", file.Removed)})
+		}
+		table := tablewriter.NewWriter(os.Stderr)
+		table.SetAutoFormatHeaders(false)
+		table.SetHeader([]string{"File", "Status", "Added", "Removed"})
+		table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+		table.SetColWidth(120)
+		table.AppendBulk(content)
+		table.Render()
+	}
+
+	utils.InforF("Compared %v with %v: %v changed files, %v identical files, %v",
+		color.HiCyanString(source), color.HiCyanString(dest), color.HiMagentaString("%v", len(comparison.Files)),
+		comparison.Identical, color.HiWhiteString("+%v -%v lines", comparison.Added, comparison.Removed))
+	return comparison, nil
+}
+
+// ComparisonToMarkdown render the comparison with the unified diff of every changed file
+func ComparisonToMarkdown(comparison WorkspaceComparison) string {
+	var md strings.Builder
+	md.WriteString(fmt.Sprintf("# Compare `%s` with `%s`\n\n", comparison.Source, comparison.Dest))
+	md.WriteString(fmt.Sprintf("%d changed files, %d identical files, +%d -%d lines\n\n",
+		len(comparison.Files), comparison.Identical, comparison.Added, comparison.Removed))
+	md.WriteString("| File | Status | Added | Removed |\n| --- | --- | --- | --- |\n")
+	for _, file := range comparison.Files {
+		md.WriteString(fmt.Sprintf("| %s | %s | +%d | -%d |\n", file.Path, file.Status, file.Added, file.Removed))
+	}
+
+	for _, file := range comparison.Files {
+		md.WriteString(fmt.Sprintf("\n## %s\n\n", file.Path))
+		if file.Binary {
+			md.WriteString("_Binary file differs_\n")
+			continue
+		}
+		md.WriteString("```diff\n" + utils.UnifiedDiff(file.Diff, 3) + "```\n")
+	}
+	return md.String()
+}
+
+// resolveWorkspaceFolder accept either the workspace name or the path of the workspace folder
+func resolveWorkspaceFolder(options libs.Options, workspace string) (string, error) {
+	folder := path.Join(utils.NormalizePath(options.Env.WorkspacesFolder), workspace)
+	if !utils.FolderExists(folder) {
+		folder = utils.NormalizePath(workspace)
+	}
+	if workspace == "" || !utils.FolderExists(folder) {
+		return "", fmt.Errorf("workspace %v not found", workspace)
+	}
+	return folder, nil
+}
+
+// workspaceFiles the relative path of the regular files in the workspace folder
+func workspaceFiles(folder string) map[string]bool {
+	files := make(map[string]bool)
+	filepath.Walk(folder, func(filename string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return nil
+		}
+		if relative, err := filepath.Rel(folder, filename); err == nil {
+			files[filepath.ToSlash(relative)] = true
+		}
+		return nil
+	})
+	return files
+}
+
+func isBinaryContent(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+func splitContentLines(data []byte) []string {
+	content := strings.TrimSuffix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if content == "" {
+		return nil
+	}
+	return strings.Split(content, "\n")
+}
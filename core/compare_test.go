@@ -0,0 +1,63 @@
+package core
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+func TestDiffLines(t *testing.T) {
+	a := []string{"a", "b", "c", "d", "e"}
+	b := []string{"a", "c", "d", "x", "e", "f"}
+	lines := utils.DiffLines(a, b)
+	added, removed := utils.DiffStat(lines)
+	if added != 2 || removed != 1 {
+		t.Errorf("Error DiffStat: +%v -%v %v", added, removed, lines)
+	}
+
+	unified := utils.UnifiedDiff(lines, 1)
+	if !strings.Contains(unified, "-b\n") || !strings.Contains(unified, "+x\n") || !strings.Contains(unified, "+f\n") {
+		t.Errorf("Error UnifiedDiff: %v", unified)
+	}
+	if len(utils.DiffLines(a, a)) != len(a) || utils.UnifiedDiff(utils.DiffLines(a, a), 3) != "" {
+		t.Errorf("Error diff of identical lines")
+	}
+}
+
+func TestCompareWorkspaces(t *testing.T) {
+	var opt libs.Options
+	opt.Env.WorkspacesFolder = t.TempDir()
+	for _, ws := range []string{"staging", "prod"} {
+		os.MkdirAll(path.Join(opt.Env.WorkspacesFolder, ws, "subdomain"), 0755)
+		utils.WriteToFile(path.Join(opt.Env.WorkspacesFolder, ws, "done"), "done")
+	}
+	utils.WriteToFile(path.Join(opt.Env.WorkspacesFolder, "staging", "subdomain", "final.txt"), "a.example.com\nb.example.com\n")
+	utils.WriteToFile(path.Join(opt.Env.WorkspacesFolder, "prod", "subdomain", "final.txt"), "a.example.com\nc.example.com\nd.example.com\n")
+	utils.WriteToFile(path.Join(opt.Env.WorkspacesFolder, "prod", "ports.txt"), "a.example.com:443\n")
+
+	comparison, err := CompareWorkspaces(opt, "staging", "prod", true)
+	if err != nil {
+		t.Fatalf("Error CompareWorkspaces: %v", err)
+	}
+	if comparison.Identical != 1 || len(comparison.Files) != 2 {
+		t.Fatalf("Error matching the files: %+v", comparison)
+	}
+	if comparison.Files[0].Path != "ports.txt" || comparison.Files[0].Status != CompareAdded {
+		t.Errorf("Error detecting the added file: %+v", comparison.Files[0])
+	}
+	final := comparison.Files[1]
+	if final.Path != "subdomain/final.txt" || final.Added != 2 || final.Removed != 1 {
+		t.Errorf("Error counting the changed lines: %+v", final)
+	}
+	if !strings.Contains(ComparisonToMarkdown(comparison), "+c.example.com") {
+		t.Errorf("Error rendering the markdown diff")
+	}
+
+	if _, err := CompareWorkspaces(opt, "staging", "not-found", false); err == nil {
+		t.Errorf("Error CompareWorkspaces should fail on missing workspace")
+	}
+}
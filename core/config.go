@@ -92,10 +92,10 @@ func InitConfig(options *libs.Options) error {
 			"db_name": utils.GetOSEnv("DB_NAME", "osm-core"),
 			"db_user": utils.GetOSEnv("DB_USER", "root"),
 			"db_pass": utils.GetOSEnv("DB_PASS", ""),
-			// default will be file system
+			// embedded sqlite database
 			"db_path": utils.GetOSEnv("DB_PATH", dbPath),
-			// sqlite or mysql
-			"db_type": utils.GetOSEnv("DB_TYPE", "filesystem"),
+			// sqlite or none to only use the runtime files
+			"db_type": utils.GetOSEnv("DB_TYPE", "sqlite"),
 		})
 
 		// default user
@@ -0,0 +1,190 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/spf13/cast"
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// the actions to control the running scan
+const (
+	ScanCancel = "cancel"
+	ScanPause  = "pause"
+	ScanResume = "resume"
+)
+
+// controlInterval how often the runner checks the control file of the workspace
+const controlInterval = 2 * time.Second
+
+// ErrScanCancelled the scan has been cancelled by the control action
+var ErrScanCancelled = errors.New("the scan has been cancelled")
+
+// ControlFile the file in the workspace which the control action is written to
+func ControlFile(workspaceFolder string) string {
+	return path.Join(workspaceFolder, "control")
+}
+
+// ResolveScan get the workspace of the argument which could be the workspace or the scan ID
+func ResolveScan(target string) (workspace string, scanID uint, err error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return workspace, scanID, fmt.Errorf("workspace or scan ID is required")
+	}
+	workspace = utils.CleanPath(target)
+	scanID = cast.ToUint(target)
+	if scanID > 0 && database.DB != nil {
+		scan, err := database.GetScan(scanID)
+		if err != nil {
+			return workspace, scanID, fmt.Errorf("scan %v not found", target)
+		}
+		workspace = scan.Target.Workspace
+	}
+	return workspace, scanID, nil
+}
+
+// ControlScan ask the runner of the workspace or scan ID to cancel, pause or resume the scan
+// the runner could be in any process on this machine since it watches the control file of its workspace
+func ControlScan(target string, action string, options libs.Options) (scan database.Scan, err error) {
+	if action != ScanCancel && action != ScanPause && action != ScanResume {
+		return scan, fmt.Errorf("invalid action %v", action)
+	}
+	workspace, scanID, err := ResolveScan(target)
+	if err != nil {
+		return scan, err
+	}
+	workspaceFolder := path.Join(utils.NormalizePath(options.Env.WorkspacesFolder), workspace)
+	scan, err = database.ParseRuntimeFile(path.Join(workspaceFolder, "runtime"))
+	if err != nil {
+		return scan, fmt.Errorf("workspace %v not found", target)
+	}
+	if scanID > 0 && database.DB != nil && scan.ID != scanID {
+		return scan, fmt.Errorf("scan %v is not running", target)
+	}
+	if !scan.IsRunning || scan.IsCancelled {
+		return scan, fmt.Errorf("scan of the workspace %v is not running", workspace)
+	}
+
+	if _, err := utils.WriteToFile(ControlFile(workspaceFolder), action); err != nil {
+		return scan, err
+	}
+	utils.InforF("Sent the %v action to the scan of the workspace %v", color.HiMagentaString(action), color.HiCyanString(workspace))
+	return scan, nil
+}
+
+// WatchControl apply the control actions of the workspace until the channel is closed
+func (r *Runner) WatchControl() chan struct{} {
+	if r.scanMu == nil {
+		r.scanMu = &sync.Mutex{}
+	}
+	stop := make(chan struct{})
+	controlFile := ControlFile(r.WorkspaceFolder)
+	go func() {
+		ticker := time.NewTicker(controlInterval)
+		defer ticker.Stop()
+		var last string
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if !utils.FileExists(controlFile) {
+					continue
+				}
+				action := strings.TrimSpace(utils.GetFileContent(controlFile))
+				if action == last {
+					continue
+				}
+				last = action
+				r.ApplyControl(action)
+			}
+		}
+	}()
+	return stop
+}
+
+// ApplyControl cancel, pause or resume the commands of the runner and record the state of the scan
+func (r *Runner) ApplyControl(action string) {
+	if r.Processes == nil {
+		r.Processes = utils.NewProcessGroup()
+	}
+	if r.Processes.IsKilled() {
+		return
+	}
+
+	switch action {
+	case ScanCancel:
+		utils.WarnF("Cancelling the scan of %v", color.HiCyanString(r.Input))
+		r.Processes.Kill()
+		r.LogEvent("Cancelling the scan")
+	case ScanPause:
+		if r.Processes.IsPaused() {
+			return
+		}
+		utils.WarnF("Pausing the scan of %v", color.HiCyanString(r.Input))
+		r.Processes.Pause()
+		r.StateEvent(StatePaused, "The scan has been paused")
+	case ScanResume:
+		if !r.Processes.IsPaused() {
+			return
+		}
+		utils.InforF("Resuming the scan of %v", color.HiCyanString(r.Input))
+		r.Processes.Resume()
+		r.StateEvent(StateResumed, "The scan has been resumed")
+	default:
+		utils.WarnF("Unknown control action: %v", action)
+		return
+	}
+	r.saveControlState()
+}
+
+// saveControlState record the state of the commands to the scan record and the runtime file
+// the target is owned by the runner goroutine so it's not saved here
+func (r *Runner) saveControlState() {
+	defer r.lockScan()()
+	r.ScanObj.IsCancelled = r.Processes.IsKilled()
+	r.ScanObj.IsPaused = !r.ScanObj.IsCancelled && r.Processes.IsPaused()
+	r.ScanObj.UpdatedAt = time.Now()
+	if !r.Opt.NoDB {
+		if err := database.SaveScan(&r.ScanObj); err != nil {
+			utils.ErrorF("[DB] Error saving scan record: %v", err)
+		}
+	}
+	if runtimeData, err := jsoniter.MarshalToString(r.ScanObj); err == nil {
+		utils.WriteToFile(r.RuntimeFile, runtimeData)
+	}
+}
+
+// WaitControl block while the scan is paused, ErrScanCancelled is returned if the scan has been cancelled
+// it's checked before every module and step so the paused scan doesn't start anything new
+func (r *Runner) WaitControl() error {
+	for {
+		if r.Processes.IsKilled() {
+			return ErrScanCancelled
+		}
+		if !r.Processes.IsPaused() {
+			return nil
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// IsCancelled the scan has been cancelled
+func (r *Runner) IsCancelled() bool {
+	return r.Processes.IsKilled()
+}
+
+// clearControl remove the control file left by the previous scan of the workspace
+func (r *Runner) clearControl() {
+	os.Remove(ControlFile(r.WorkspaceFolder))
+}
@@ -0,0 +1,199 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/robertkrimen/otto"
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+func TestControlScan(t *testing.T) {
+	var options libs.Options
+	options.Env.WorkspacesFolder = t.TempDir()
+	workspaceFolder := path.Join(options.Env.WorkspacesFolder, "example.com")
+	utils.MakeDir(workspaceFolder)
+
+	if _, err := ControlScan("example.com", ScanPause, options); err == nil {
+		t.Errorf("Error ControlScan should fail without the runtime file")
+	}
+
+	runtime, _ := jsoniter.MarshalToString(database.Scan{InputName: "example.com", IsRunning: true})
+	utils.WriteToFile(path.Join(workspaceFolder, "runtime"), runtime)
+	if _, err := ControlScan("example.com", "stop", options); err == nil {
+		t.Errorf("Error ControlScan should reject the unknown action")
+	}
+	if _, err := ControlScan("example.com", ScanPause, options); err != nil {
+		t.Fatalf("Error ControlScan: %v", err)
+	}
+	if action := utils.GetFileContent(ControlFile(workspaceFolder)); action != ScanPause+"\n" {
+		t.Errorf("Error control file: %q", action)
+	}
+
+	runtime, _ = jsoniter.MarshalToString(database.Scan{InputName: "example.com", IsDone: true})
+	utils.WriteToFile(path.Join(workspaceFolder, "runtime"), runtime)
+	if _, err := ControlScan("example.com", ScanCancel, options); err == nil {
+		t.Errorf("Error ControlScan should reject the finished scan")
+	}
+}
+
+func TestApplyControl(t *testing.T) {
+	var r Runner
+	r.Opt.NoDB = true
+	r.Input = "example.com"
+	r.RuntimeFile = path.Join(t.TempDir(), "runtime")
+	r.Processes = utils.NewProcessGroup()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := r.Processes.RunOSCommand("sleep 30")
+		done <- err
+	}()
+	for i := 0; i < 50 && r.Processes.Len() == 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	r.ApplyControl(ScanPause)
+	if !r.ScanObj.IsPaused || !r.Processes.IsPaused() {
+		t.Errorf("Error the scan should be paused")
+	}
+	waited := make(chan error, 1)
+	go func() { waited <- r.WaitControl() }()
+	select {
+	case <-waited:
+		t.Errorf("Error WaitControl should block while the scan is paused")
+	case <-time.After(1500 * time.Millisecond):
+	}
+
+	r.ApplyControl(ScanCancel)
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Error the command should be killed")
+	}
+	select {
+	case err := <-waited:
+		if err != ErrScanCancelled {
+			t.Errorf("Error WaitControl: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Error WaitControl should return after the cancel")
+	}
+	if !r.ScanObj.IsCancelled || r.ScanObj.IsPaused {
+		t.Errorf("Error the scan should be cancelled")
+	}
+	if _, err := r.Processes.RunOSCommand("true"); err != utils.ErrProcessGroupKilled {
+		t.Errorf("Error no command should be started after the cancel: %v", err)
+	}
+}
+
+// the control actions of the watcher goroutine and the updates of the runner goroutine, run with -race
+func TestApplyControlRace(t *testing.T) {
+	var r Runner
+	r.Opt.NoDB = true
+	r.Input = "example.com"
+	r.TotalSteps = 1000
+	r.RuntimeFile = path.Join(t.TempDir(), "runtime")
+	r.Processes = utils.NewProcessGroup()
+	stop := r.WatchControl()
+	defer close(stop)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			r.DoneStep = i
+			r.DBUpdateScan()
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		r.ApplyControl(ScanPause)
+		r.ApplyControl(ScanResume)
+	}
+	<-done
+
+	r.ApplyControl(ScanPause)
+	r.DBUpdateScan()
+	if !r.ScanObj.IsPaused || r.ScanObj.DoneStep != 199 {
+		t.Errorf("Error the control state should be kept by the runner updates: %v %v", r.ScanObj.IsPaused, r.ScanObj.DoneStep)
+	}
+	if scan, err := database.ParseRuntimeFile(r.RuntimeFile); err != nil || !scan.IsPaused {
+		t.Errorf("Error the paused state should be in the runtime file: %v %v", scan.IsPaused, err)
+	}
+}
+
+func TestControlScriptCommands(t *testing.T) {
+	var r Runner
+	r.Opt.NoDB = true
+	r.RuntimeFile = path.Join(t.TempDir(), "runtime")
+	r.Target = make(map[string]string)
+	r.Processes = utils.NewProcessGroup()
+	r.VM = otto.New()
+	r.LoadScripts()
+
+	r.ExecScript(`ExecCmdB("sleep 30")`)
+	for i := 0; i < 50 && r.Processes.Len() == 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if r.Processes.Len() != 1 {
+		t.Fatalf("Error the background command should belong to the runner")
+	}
+	if out := r.ExecScript(`ExecContain("echo found-it", "", "found-it")`); out != "true" {
+		t.Errorf("Error ExecContain: %v", out)
+	}
+
+	r.ApplyControl(ScanCancel)
+	for i := 0; i < 100 && r.Processes.Len() > 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if r.Processes.Len() != 0 {
+		t.Errorf("Error the background command should be killed by the cancel")
+	}
+	if out := r.ExecScript(`ExecContain("echo found-it", "", "found-it")`); out != "false" {
+		t.Errorf("Error no command should be started after the cancel: %v", out)
+	}
+}
+
+func TestRunJobKilled(t *testing.T) {
+	processes := utils.NewProcessGroup()
+	done := make(chan error, 1)
+	go func() {
+		_, err := RunJob(database.Job{Input: "example.com", Command: "sleep 30 # {{.input}}"}, libs.Options{}, processes)
+		done <- err
+	}()
+	for i := 0; i < 50 && processes.Len() == 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	processes.Kill()
+	select {
+	case err := <-done:
+		if err != ErrScanCancelled {
+			t.Errorf("Error RunJob: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Error the command of the job should be killed")
+	}
+}
+
+func TestRunJobCallbackEnv(t *testing.T) {
+	inputFormat := libs.InputFormat{Input: "example.com", Extra: "--debug", CallbackURL: "https://hook.example.com/x", CallbackSecret: "s3cret'; id #"}
+	job := NewJob(inputFormat)
+	if strings.Contains(job.Command, "s3cret") || strings.Contains(job.Command, "hook.example.com") {
+		t.Errorf("Error the callback should not be in the command: %v", job.Command)
+	}
+
+	out := path.Join(t.TempDir(), "env")
+	job.Command = fmt.Sprintf("echo \"$%v $%v\" > %v", libs.CallbackURLEnv, libs.CallbackSecretEnv, out)
+	if _, err := RunJob(job, libs.Options{}, utils.NewProcessGroup()); err != nil {
+		t.Fatalf("Error RunJob: %v", err)
+	}
+	if got := strings.TrimSpace(utils.GetFileContent(out)); got != "https://hook.example.com/x s3cret'; id #" {
+		t.Errorf("Error the callback should be in the environment of the command: %v", got)
+	}
+}
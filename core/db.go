@@ -46,6 +46,16 @@ func (r *Runner) LoadDBScripts() string {
 	})
 
 	r.VM.Set(TotalVulnerability, func(call otto.FunctionCall) otto.Value {
+		// the counters are derived from the findings imported by ImportNucleiVulnJson, ImportJaelesVulnJson, etc
+		if !r.Opt.NoDB && database.DB != nil {
+			if err := database.UpdateFindingCounters(&r.TargetObj); err != nil {
+				utils.ErrorF("[DB] Error counting the findings: %v", err)
+			}
+			utils.InforF("Total Vulnerability: %v %v", color.HiMagentaString("%v", r.TargetObj.TotalVulnerability),
+				color.HiWhiteString("(critical/%v high/%v medium/%v low/%v)", r.TargetObj.TotalCritical, r.TargetObj.TotalHigh, r.TargetObj.TotalMedium, r.TargetObj.TotalLow))
+			return otto.Value{}
+		}
+
 		data := utils.ReadingFileUnique(call.Argument(0).String())
 		var length int
 		for _, line := range data {
@@ -107,10 +117,16 @@ func (r *Runner) DBNewTarget() {
 		InputType: r.InputType,
 	}
 
+	if !r.Opt.NoDB {
+		if err := database.SaveTarget(&r.TargetObj); err != nil {
+			utils.ErrorF("[DB] Error saving target record: %v", err)
+		}
+	}
 	r.DBRuntimeUpdate()
 }
 
 func (r *Runner) DBNewScan() {
+	defer r.lockScan()()
 	r.ScanObj = database.Scan{
 		TaskType: r.RoutineType,
 		TaskName: path.Base(r.RoutineName),
@@ -140,14 +156,11 @@ func (r *Runner) DBNewScan() {
 	}
 
 	r.ScanObj.CreatedAt = time.Now()
-
-	if runtimeData, err := jsoniter.MarshalToString(r.ScanObj); err == nil {
-		utils.WriteToFile(r.RuntimeFile, runtimeData)
-	}
-
+	r.saveRuntime()
 }
 
 func (r *Runner) DBUpdateScan() {
+	defer r.lockScan()()
 	r.ScanObj.DoneStep = r.DoneStep
 	r.ScanObj.CurrentModule = r.CurrentModule
 	r.ScanObj.RunningTime = r.RunningTime
@@ -163,10 +176,11 @@ func (r *Runner) DBUpdateScan() {
 	}
 
 	utils.DebugF("[DB] Finished %v steps in the %v module", color.HiCyanString("%v/%v", r.DoneStep, r.TotalSteps), r.CurrentModule)
-	r.DBRuntimeUpdate()
+	r.saveRuntime()
 }
 
 func (r *Runner) DBDoneScan() {
+	defer r.lockScan()()
 	r.ScanObj.CurrentModule = "done"
 	r.ScanObj.RunningTime = r.RunningTime
 
@@ -177,9 +191,17 @@ func (r *Runner) DBDoneScan() {
 	r.ScanObj.UpdatedAt = time.Now()
 
 	utils.DebugF("[DB] The scan has been completed: %v -- %v", color.HiCyanString(r.ScanObj.InputName), color.HiCyanString(r.ScanObj.TaskName))
+	if !r.Opt.NoDB {
+		if err := database.MarkDisappeared(r.TargetObj.ID, r.ScanObj.ID); err != nil {
+			utils.ErrorF("[DB] Error tracking the disappeared assets: %v", err)
+		}
+		if err := database.UpdateFindingCounters(&r.TargetObj); err != nil {
+			utils.ErrorF("[DB] Error counting the findings: %v", err)
+		}
+	}
+	r.saveRuntime()
 	if runtimeData, err := jsoniter.MarshalToString(r.ScanObj); err == nil {
 		utils.WriteToFile(r.DoneFile, runtimeData)
-		utils.WriteToFile(r.RuntimeFile, runtimeData)
 	}
 
 	if utils.FileExists(r.ScanObj.MarkDownReport) {
@@ -190,15 +212,65 @@ func (r *Runner) DBDoneScan() {
 	}
 }
 
+// DBCancelScan mark the scan as cancelled, the done file is not written so the scan could be resumed later
+func (r *Runner) DBCancelScan() {
+	defer r.lockScan()()
+	r.ScanObj.CurrentModule = "cancelled"
+	r.ScanObj.RunningTime = r.RunningTime
+	r.ScanObj.DoneStep = r.DoneStep
+
+	r.ScanObj.IsCancelled = true
+	r.ScanObj.IsPaused = false
+	r.ScanObj.IsDone = false
+	r.ScanObj.IsRunning = false
+	r.ScanObj.IsStarted = false
+
+	utils.DebugF("[DB] The scan has been cancelled: %v -- %v", color.HiCyanString(r.ScanObj.InputName), color.HiCyanString(r.ScanObj.TaskName))
+	r.saveRuntime()
+}
+
+// DBRuntimeUpdate store the scan record to the database and export it to the runtime file
 func (r *Runner) DBRuntimeUpdate() {
+	defer r.lockScan()()
+	r.saveRuntime()
+}
+
+// lockScan lock ScanObj and return the unlock function
+// the lock is created by InitRunner and WatchControl, without it the runner goroutine is the only one using ScanObj
+func (r *Runner) lockScan() func() {
+	if r.scanMu == nil {
+		return func() {}
+	}
+	r.scanMu.Lock()
+	return r.scanMu.Unlock
+}
+
+// saveRuntime same as DBRuntimeUpdate but the scan lock is already held
+func (r *Runner) saveRuntime() {
 	r.ScanObj.UpdatedAt = time.Now()
 	r.ScanObj.Target = r.TargetObj
+
+	if !r.Opt.NoDB {
+		// keep the counters of the target up to date
+		if err := database.SaveTarget(&r.TargetObj); err != nil {
+			utils.ErrorF("[DB] Error saving target record: %v", err)
+		}
+		if err := database.SaveScan(&r.ScanObj); err != nil {
+			utils.ErrorF("[DB] Error saving scan record: %v", err)
+		}
+		if err := database.SaveReports(&r.TargetObj, r.ScanObj.ID); err != nil {
+			utils.ErrorF("[DB] Error saving report records: %v", err)
+		}
+	}
+
+	// runtime file is kept for the compatibility with the older version
 	if runtimeData, err := jsoniter.MarshalToString(r.ScanObj); err == nil {
 		utils.WriteToFile(r.RuntimeFile, runtimeData)
 	}
 }
 
 func (r *Runner) DBNewReports(module libs.Module) {
+	defer r.lockScan()()
 	r.ScanObj.CurrentModule = r.CurrentModule
 	r.ScanObj.RunningTime = r.RunningTime
 
@@ -225,5 +297,11 @@ func (r *Runner) DBNewReports(module libs.Module) {
 		r.TargetObj.Reports = append(r.TargetObj.Reports, reportObj)
 
 	}
+
+	if !r.Opt.NoDB {
+		if err := database.SaveReports(&r.TargetObj, r.ScanObj.ID); err != nil {
+			utils.ErrorF("[DB] Error saving report records: %v", err)
+		}
+	}
 	r.ScanObj.Target = r.TargetObj
 }
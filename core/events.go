@@ -0,0 +1,185 @@
+package core
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/whoamikiddie/vulnx/api"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// the type of the scan events, see the api package
+const (
+	EventLog      = api.EventLog
+	EventModule   = api.EventModule
+	EventStep     = api.EventStep
+	EventProgress = api.EventProgress
+	EventState    = api.EventState
+)
+
+// the states of the scan sent with the state event
+const (
+	StateRunning   = api.StateRunning
+	StatePaused    = api.StatePaused
+	StateResumed   = api.StateResumed
+	StateCancelled = api.StateCancelled
+	StateDone      = api.StateDone
+	StateFailed    = api.StateFailed
+)
+
+// eventsPollInterval how often the events file is checked for the new events
+const eventsPollInterval = 500 * time.Millisecond
+
+// ScanEvent the event of the scan, they're appended to the events file of the workspace
+type ScanEvent = api.ScanEvent
+
+// EventsFile the file in the workspace which the events of the scan are appended to
+func EventsFile(workspaceFolder string) string {
+	return path.Join(workspaceFolder, "events.jsonl")
+}
+
+// eventWriter append the events of the runner to the events file
+type eventWriter struct {
+	mu       sync.Mutex
+	filename string
+	seq      int
+}
+
+// newEventWriter start a fresh events file for the new scan
+func newEventWriter(workspaceFolder string) *eventWriter {
+	filename := EventsFile(workspaceFolder)
+	os.Remove(filename)
+	return &eventWriter{filename: filename}
+}
+
+func (w *eventWriter) write(event ScanEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.seq++
+	event.ID = w.seq
+	event.Time = time.Now()
+	data, err := jsoniter.MarshalToString(event)
+	if err != nil {
+		return
+	}
+	f, err := os.OpenFile(w.filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		utils.DebugF("Error writing the scan event: %v", err)
+		return
+	}
+	defer f.Close()
+	f.WriteString(data + "\n")
+}
+
+// Event record the event of the scan, the progress counters are filled in
+func (r *Runner) Event(event ScanEvent) {
+	if r.events == nil {
+		return
+	}
+	if event.Module == "" {
+		event.Module = r.CurrentModule
+	}
+	event.DoneStep = r.DoneStep
+	event.TotalSteps = r.TotalSteps
+	r.events.write(event)
+}
+
+// LogEvent record the log line of the scan
+func (r *Runner) LogEvent(message string) {
+	r.Event(ScanEvent{Type: EventLog, Message: message})
+}
+
+// StateEvent record the state change of the scan
+func (r *Runner) StateEvent(state string, message string) {
+	r.Event(ScanEvent{Type: EventState, State: state, Message: message})
+}
+
+// FollowEvents send the events of the workspace after the ID since, then keep sending the new ones
+// it returns when the scan is finished, the send function fails or the stop channel is closed
+// idle is called on every poll without any new event, it could be nil
+func FollowEvents(workspaceFolder string, since int, stop <-chan struct{}, send func(ScanEvent) error, idle func() error) error {
+	filename := EventsFile(workspaceFolder)
+	var offset int64
+	var partial string
+	ticker := time.NewTicker(eventsPollInterval)
+	defer ticker.Stop()
+
+	for {
+		sent := since
+		if info, err := os.Stat(filename); err == nil {
+			// the file is started over by the new scan of the workspace
+			if info.Size() < offset {
+				offset, partial, since = 0, "", 0
+			}
+			if info.Size() > offset {
+				events, read, err := readEvents(filename, offset)
+				if err != nil {
+					return err
+				}
+				offset += read
+				for _, line := range events {
+					line = partial + line
+					partial = ""
+					if !strings.HasSuffix(line, "\n") {
+						// the runner is still writing this line
+						partial = line
+						continue
+					}
+					var event ScanEvent
+					if err := jsoniter.UnmarshalFromString(line, &event); err != nil || event.ID <= since {
+						continue
+					}
+					if err := send(event); err != nil {
+						return err
+					}
+					since = event.ID
+					if event.IsFinal() {
+						return nil
+					}
+				}
+			}
+		}
+
+		if sent == since && idle != nil {
+			if err := idle(); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// readEvents read the lines of the file from the offset, the number of bytes read is returned too
+func readEvents(filename string, offset int64) (lines []string, read int64, err error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return lines, 0, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return lines, 0, err
+	}
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			lines = append(lines, line)
+			read += int64(len(line))
+		}
+		if err != nil {
+			break
+		}
+	}
+	return lines, read, nil
+}
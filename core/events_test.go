@@ -0,0 +1,87 @@
+package core
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+func TestFollowEvents(t *testing.T) {
+	var r Runner
+	r.WorkspaceFolder = t.TempDir()
+	r.events = newEventWriter(r.WorkspaceFolder)
+	r.TotalSteps = 2
+
+	r.StateEvent(StateRunning, "Running the routine")
+	r.Event(ScanEvent{Type: EventModule, Module: "probing", State: "started"})
+	r.DoneStep = 1
+	r.Event(ScanEvent{Type: EventProgress})
+
+	// replay the backlog after the first event then follow the new ones
+	events := make(chan ScanEvent, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- FollowEvents(r.WorkspaceFolder, 1, nil, func(event ScanEvent) error {
+			events <- event
+			return nil
+		}, nil)
+	}()
+
+	time.Sleep(time.Second)
+	r.DoneStep = 2
+	r.StateEvent(StateDone, "The scan was completed")
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Error FollowEvents: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Error FollowEvents should return after the final event")
+	}
+	close(events)
+
+	var got []ScanEvent
+	for event := range events {
+		got = append(got, event)
+	}
+	if len(got) != 3 {
+		t.Fatalf("Error number of events: %v", got)
+	}
+	if got[0].ID != 2 || got[0].Module != "probing" || got[1].Type != EventProgress || got[1].DoneStep != 1 {
+		t.Errorf("Error backlog events: %v", got[:2])
+	}
+	if !got[2].IsFinal() || got[2].DoneStep != 2 || got[2].TotalSteps != 2 {
+		t.Errorf("Error final event: %v", got[2])
+	}
+}
+
+func TestCommandLogEvents(t *testing.T) {
+	var r Runner
+	r.WorkspaceFolder = t.TempDir()
+	r.events = newEventWriter(r.WorkspaceFolder)
+	r.Processes = utils.NewProcessGroup()
+	r.Processes.SetOutput(r.LogEvent)
+	r.CurrentModule = "probing"
+
+	r.RunCommands([]string{"echo hello-from-the-step; echo warning-line >&2"}, "")
+
+	errBacklog := errors.New("backlog sent")
+	var logs []string
+	err := FollowEvents(r.WorkspaceFolder, 0, nil, func(event ScanEvent) error {
+		if event.Type == EventLog && event.Module == "probing" {
+			logs = append(logs, event.Message)
+		}
+		return nil
+	}, func() error { return errBacklog })
+	if err != errBacklog {
+		t.Fatalf("Error FollowEvents: %v", err)
+	}
+	sort.Strings(logs)
+	if len(logs) != 2 || logs[0] != "hello-from-the-step" || logs[1] != "warning-line" {
+		t.Errorf("Error the output of the command should be streamed as the log events: %v", logs)
+	}
+}
@@ -0,0 +1,265 @@
+package core
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// ExportSchemaVersion version of the export schema, bump it when a field is renamed or removed
+const ExportSchemaVersion = "1.0"
+
+// ExportFinding a finding in the export schema
+type ExportFinding struct {
+	Fingerprint string    `json:"fingerprint"`
+	CheckID     string    `json:"check_id"`
+	Title       string    `json:"title"`
+	Severity    string    `json:"severity"`
+	Confidence  string    `json:"confidence"`
+	Host        string    `json:"host"`
+	Location    string    `json:"location"`
+	Evidence    string    `json:"evidence"`
+	Source      string    `json:"source"`
+	Status      string    `json:"status"`
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+}
+
+// ExportReport the findings of a workspace in the export schema
+type ExportReport struct {
+	SchemaVersion string          `json:"schema_version"`
+	Generator     string          `json:"generator"`
+	GeneratedAt   time.Time       `json:"generated_at"`
+	Workspace     string          `json:"workspace"`
+	Target        string          `json:"target"`
+	Total         int             `json:"total"`
+	Findings      []ExportFinding `json:"findings"`
+}
+
+var exportCSVHeader = []string{"fingerprint", "check_id", "title", "severity", "confidence", "host", "location", "evidence", "source", "status", "first_seen", "last_seen"}
+
+// NewExportReport get the findings of the workspace which are still present
+func NewExportReport(wsName string) (report ExportReport, err error) {
+	target, err := database.GetTargetByWorkspace(wsName)
+	if err != nil {
+		return report, fmt.Errorf("workspace %v not found in the database: %v", wsName, err)
+	}
+	findings, err := database.GetFindings(target.ID)
+	if err != nil {
+		return report, err
+	}
+
+	report = ExportReport{
+		SchemaVersion: ExportSchemaVersion,
+		Generator:     fmt.Sprintf("%s %s", libs.BINARY, libs.VERSION),
+		GeneratedAt:   time.Now().UTC(),
+		Workspace:     target.Workspace,
+		Target:        target.InputName,
+		Findings:      []ExportFinding{},
+	}
+	for _, finding := range findings {
+		report.Findings = append(report.Findings, ExportFinding{
+			Fingerprint: finding.Fingerprint,
+			CheckID:     finding.CheckID,
+			Title:       finding.Title,
+			Severity:    finding.Severity,
+			Confidence:  finding.Confidence,
+			Host:        finding.Host,
+			Location:    finding.MatchedAt,
+			Evidence:    finding.Evidence,
+			Source:      finding.Source,
+			Status:      finding.Status,
+			FirstSeen:   finding.FirstSeen.UTC(),
+			LastSeen:    finding.LastSeen.UTC(),
+		})
+	}
+	report.Total = len(report.Findings)
+	return report, nil
+}
+
+// ExportWorkspace write the findings of the workspace to the writer in the format of sarif, json or csv
+func ExportWorkspace(wsName string, format string, w io.Writer) error {
+	report, err := NewExportReport(wsName)
+	if err != nil {
+		return err
+	}
+	return report.Write(format, w)
+}
+
+// ValidExportFormat check if the format is one of json, csv or sarif
+func ValidExportFormat(format string) bool {
+	switch strings.ToLower(format) {
+	case "json", "csv", "sarif":
+		return true
+	}
+	return false
+}
+
+// Write encode the report in the format
+func (e ExportReport) Write(format string, w io.Writer) error {
+	switch strings.ToLower(format) {
+	case "json":
+		encoder := jsoniter.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(e)
+	case "csv":
+		writer := csv.NewWriter(w)
+		writer.Write(exportCSVHeader)
+		for _, finding := range e.Findings {
+			writer.Write([]string{
+				finding.Fingerprint, finding.CheckID, finding.Title, finding.Severity, finding.Confidence, finding.Host,
+				finding.Location, finding.Evidence, finding.Source, finding.Status,
+				finding.FirstSeen.Format(time.RFC3339), finding.LastSeen.Format(time.RFC3339),
+			})
+		}
+		writer.Flush()
+		return writer.Error()
+	case "sarif":
+		encoder := jsoniter.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(e.ToSARIF())
+	}
+	return fmt.Errorf("unsupported export format: %v", format)
+}
+
+// ExportWorkspaceToFile write the findings of the workspace to the output file
+// the format and the workspace are checked first so the existing file is kept if they are invalid
+func ExportWorkspaceToFile(wsName string, format string, output string) error {
+	if !ValidExportFormat(format) {
+		return fmt.Errorf("unsupported export format: %v", format)
+	}
+	report, err := NewExportReport(wsName)
+	if err != nil {
+		return err
+	}
+	utils.MakeDir(path.Dir(output))
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return report.Write(format, f)
+}
+
+// ToSARIF convert the report to SARIF 2.1.0 so it can be consumed by the code scanning dashboards
+func (e ExportReport) ToSARIF() map[string]interface{} {
+	var rules []map[string]interface{}
+	var results []map[string]interface{}
+	ruleIndex := make(map[string]int)
+
+	for _, finding := range e.Findings {
+		index, ok := ruleIndex[finding.CheckID]
+		if !ok {
+			index = len(rules)
+			ruleIndex[finding.CheckID] = index
+			title := finding.Title
+			if title == "" {
+				title = finding.CheckID
+			}
+			rules = append(rules, map[string]interface{}{
+				"id":               finding.CheckID,
+				"name":             title,
+				"shortDescription": map[string]string{"text": title},
+				"defaultConfiguration": map[string]string{
+					"level": sarifLevel(finding.Severity),
+				},
+				"properties": map[string]interface{}{
+					"tags":              []string{"security", finding.Source},
+					"security-severity": sarifSecuritySeverity(finding.Severity),
+				},
+			})
+		}
+
+		message := fmt.Sprintf("[%s] %s at %s", finding.Severity, rules[index]["name"], finding.Location)
+		if finding.Evidence != "" {
+			message += fmt.Sprintf(" -- %s", finding.Evidence)
+		}
+		results = append(results, map[string]interface{}{
+			"ruleId":    finding.CheckID,
+			"ruleIndex": index,
+			"level":     sarifLevel(finding.Severity),
+			"message":   map[string]string{"text": message},
+			"locations": []map[string]interface{}{
+				{
+					"physicalLocation": map[string]interface{}{
+						"artifactLocation": map[string]string{"uri": finding.Location},
+					},
+				},
+			},
+			"partialFingerprints": map[string]string{
+				fmt.Sprintf("%sFingerprint/v1", libs.BINARY): finding.Fingerprint,
+			},
+			"properties": map[string]interface{}{
+				"severity":   finding.Severity,
+				"host":       finding.Host,
+				"evidence":   finding.Evidence,
+				"source":     finding.Source,
+				"status":     finding.Status,
+				"first_seen": finding.FirstSeen,
+				"last_seen":  finding.LastSeen,
+			},
+		})
+	}
+	if rules == nil {
+		rules = []map[string]interface{}{}
+	}
+	if results == nil {
+		results = []map[string]interface{}{}
+	}
+
+	return map[string]interface{}{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []map[string]interface{}{
+			{
+				"tool": map[string]interface{}{
+					"driver": map[string]interface{}{
+						"name":           libs.BINARY,
+						"version":        libs.VERSION,
+						"informationUri": libs.DOCS,
+						"rules":          rules,
+					},
+				},
+				"results": results,
+				"properties": map[string]interface{}{
+					"schema_version": e.SchemaVersion,
+					"workspace":      e.Workspace,
+					"target":         e.Target,
+				},
+			},
+		},
+	}
+}
+
+func sarifLevel(severity string) string {
+	switch severity {
+	case database.SeverityCritical, database.SeverityHigh:
+		return "error"
+	case database.SeverityMedium:
+		return "warning"
+	}
+	return "note"
+}
+
+func sarifSecuritySeverity(severity string) string {
+	switch severity {
+	case database.SeverityCritical:
+		return "9.5"
+	case database.SeverityHigh:
+		return "8.0"
+	case database.SeverityMedium:
+		return "5.5"
+	case database.SeverityLow:
+		return "3.0"
+	}
+	return "0.0"
+}
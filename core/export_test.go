@@ -0,0 +1,72 @@
+package core
+
+import (
+	"bytes"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+func TestExportWorkspace(t *testing.T) {
+	r := initImportRunner(t)
+	database.ImportFindings([]database.Finding{
+		{CheckID: "git-config", Title: "Git Config", MatchedAt: "https://example.com/.git/config", Severity: "medium", Evidence: "[core]", Source: "nuclei", TargetRefer: r.TargetObj.ID},
+		{CheckID: "CVE-2021-44228", MatchedAt: "https://api.example.com/login", Severity: "critical", Source: "nuclei", TargetRefer: r.TargetObj.ID},
+	})
+
+	var buf bytes.Buffer
+	if err := ExportWorkspace("example.com", "json", &buf); err != nil {
+		t.Fatalf("Error ExportWorkspace json: %v", err)
+	}
+	jsonParsed, err := gabs.ParseJSON(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Error parsing json export: %v", err)
+	}
+	if jsonParsed.S("schema_version").Data() != ExportSchemaVersion || len(jsonParsed.S("findings").Children()) != 2 {
+		t.Errorf("Error json export: %v", buf.String())
+	}
+
+	buf.Reset()
+	if err := ExportWorkspace("example.com", "sarif", &buf); err != nil {
+		t.Fatalf("Error ExportWorkspace sarif: %v", err)
+	}
+	jsonParsed, _ = gabs.ParseJSON(buf.Bytes())
+	results := jsonParsed.Path("runs.0.results").Children()
+	if jsonParsed.S("version").Data() != "2.1.0" || len(results) != 2 {
+		t.Fatalf("Error sarif export: %v", buf.String())
+	}
+	if results[0].S("level").Data() != "warning" || results[1].S("level").Data() != "error" {
+		t.Errorf("Error sarif level: %v", results)
+	}
+
+	buf.Reset()
+	ExportWorkspace("example.com", "csv", &buf)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "fingerprint,check_id") {
+		t.Errorf("Error csv export: %v", lines)
+	}
+
+	if err := ExportWorkspace("example.com", "xml", &buf); err == nil {
+		t.Errorf("Error ExportWorkspace should reject unknown format")
+	}
+
+	// the existing output is kept when the export is rejected
+	output := path.Join(t.TempDir(), "report.json")
+	utils.WriteToFile(output, "previous report")
+	if err := ExportWorkspaceToFile("example.com", "xml", output); err == nil {
+		t.Errorf("Error ExportWorkspaceToFile should reject unknown format")
+	}
+	if err := ExportWorkspaceToFile("not-exist.com", "json", output); err == nil {
+		t.Errorf("Error ExportWorkspaceToFile should reject unknown workspace")
+	}
+	if content := strings.TrimSpace(utils.GetFileContent(output)); content != "previous report" {
+		t.Errorf("Error the output should be kept: %v", content)
+	}
+	if err := ExportWorkspaceToFile("example.com", "csv", output); err != nil || !strings.HasPrefix(utils.GetFileContent(output), "fingerprint,check_id") {
+		t.Errorf("Error ExportWorkspaceToFile: %v", err)
+	}
+}
@@ -294,5 +294,18 @@ func (r *Runner) LoadGitScripts() string {
 		return otto.Value{}
 	})
 
+	// ExportFindings("sarif", "{{Output}}/findings.sarif")
+	vm.Set(ExportFindings, func(call otto.FunctionCall) otto.Value {
+		args := call.ArgumentList
+		format := args[0].String()
+		output := args[1].String()
+		if err := ExportWorkspaceToFile(r.Workspace, format, output); err != nil {
+			utils.ErrorF("Error exporting findings: %v", err)
+			return otto.Value{}
+		}
+		utils.InforF("Exported the findings to: %v", output)
+		return otto.Value{}
+	})
+
 	return output
 }
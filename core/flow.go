@@ -220,3 +220,56 @@ func SelectScript(scriptName string, options libs.Options) string {
 	}
 	return ""
 }
+
+// ValidateRoutine make sure the flow or the modules exist before the scan is started
+// only the names are accepted, they must resolve to a file in the workflow folder
+func ValidateRoutine(flowName string, modules []string, options libs.Options) error {
+	if len(modules) > 0 {
+		for _, module := range modules {
+			if !isRoutineName(module) {
+				return fmt.Errorf("invalid module name: %v", module)
+			}
+			if modulePath := DirectSelectModule(options, module); modulePath == "" || !inFolder(modulePath, options.Env.WorkFlowsFolder) {
+				return fmt.Errorf("module %v not found", module)
+			}
+		}
+		return nil
+	}
+	if flowName == "" {
+		return fmt.Errorf("flow or module is required")
+	}
+	if !isRoutineName(flowName) {
+		return fmt.Errorf("invalid flow name: %v", flowName)
+	}
+	flows := SelectFlow(flowName, options)
+	if len(flows) == 0 {
+		return fmt.Errorf("flow %v not found", flowName)
+	}
+	for _, flow := range flows {
+		if !inFolder(flow, options.Env.WorkFlowsFolder) {
+			return fmt.Errorf("flow %v not found", flowName)
+		}
+	}
+	return nil
+}
+
+// isRoutineName the flow or module name is not a path
+func isRoutineName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, "/\\") && !strings.Contains(name, "..")
+}
+
+// inFolder the file is inside the folder
+func inFolder(file string, folder string) bool {
+	if folder == "" {
+		return false
+	}
+	file, err := filepath.Abs(utils.NormalizePath(file))
+	if err != nil {
+		return false
+	}
+	folder, err = filepath.Abs(utils.NormalizePath(folder))
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(file, folder+string(filepath.Separator))
+}
@@ -0,0 +1,256 @@
+package core
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flosch/pongo2/v6"
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/spf13/cast"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// TOCItem a heading in the table of contents of the HTML report
+type TOCItem struct {
+	Level int
+	ID    string
+	Title string
+}
+
+// HTMLReport data to render the HTML report template
+type HTMLReport struct {
+	Title       string
+	Target      string
+	Workflow    string
+	Version     string
+	GeneratedAt string
+	CSS         string
+	Content     string
+	TOC         []TOCItem
+}
+
+// MarkDownToHTML render the markdown file to a single HTML file without any external reference
+// the template could be overridden per flow with {{Data}}/markdown/<flow>.html or {{Data}}/markdown/report.html
+func MarkDownToHTML(options libs.Options, target string, flow string, markdownFile string, outputFile string) error {
+	input, err := os.ReadFile(markdownFile)
+	if err != nil {
+		utils.ErrorF("Error reading %s: %v", markdownFile, err)
+		return err
+	}
+
+	var extensions = parser.NoIntraEmphasis |
+		parser.Tables |
+		parser.FencedCode |
+		parser.Autolink |
+		parser.Strikethrough |
+		parser.SpaceHeadings |
+		parser.AutoHeadingIDs
+
+	var htmlFlags html.Flags
+	htmlFlags |= html.Smartypants
+	htmlFlags |= html.UseXHTML
+	htmlFlags |= html.SmartypantsLatexDashes
+	htmlFlags |= html.SmartypantsFractions
+	renderer := html.NewRenderer(html.RendererOptions{Flags: htmlFlags})
+
+	// @NOTE: beware of XSS as I assume you will trust the markdown content you generate
+	content := string(markdown.ToHTML(input, parser.NewWithExtensions(extensions), renderer))
+	content = EmbedImages(content, path.Dir(markdownFile))
+
+	report := HTMLReport{
+		Title:       fmt.Sprintf("Executive Summary - %s", target),
+		Target:      target,
+		Workflow:    flow,
+		Version:     libs.VERSION,
+		GeneratedAt: time.Now().Format(time.RFC1123),
+		CSS:         defaultReportCSS,
+		Content:     content,
+		TOC:         GenTOC(content),
+	}
+	css := path.Join(options.Env.DataFolder, "markdown/style.css")
+	if utils.FileExists(css) {
+		report.CSS = utils.GetFileContent(css)
+	}
+
+	tpl, err := pongo2.FromString(reportTemplate(options, flow))
+	if err != nil {
+		utils.ErrorF("Error parsing the report template: %v", err)
+		return err
+	}
+	finalHTML, err := tpl.Execute(pongo2.Context{
+		"Report": report,
+		"Script": sortableTableScript,
+	})
+	if err != nil {
+		utils.ErrorF("Error rendering the report template: %v", err)
+		return err
+	}
+
+	if err := os.WriteFile(outputFile, []byte(finalHTML), 0644); err != nil {
+		utils.ErrorF("Error writing output: %v", err)
+		return err
+	}
+	return nil
+}
+
+// reportTemplate get the report template of the flow, fall back to the built-in one
+func reportTemplate(options libs.Options, flow string) string {
+	candidates := []string{
+		path.Join(options.Env.DataFolder, "markdown", fmt.Sprintf("%s.html", flow)),
+		path.Join(options.Env.DataFolder, "markdown", "report.html"),
+	}
+	for _, candidate := range candidates {
+		if flow == "" && strings.HasSuffix(candidate, "/.html") {
+			continue
+		}
+		if utils.FileExists(candidate) {
+			utils.DebugF("Using report template: %v", candidate)
+			return utils.GetFileContent(candidate)
+		}
+	}
+	return defaultReportTemplate
+}
+
+var imgRegex = regexp.MustCompile(`<img[^>]*\ssrc="([^"]+)"[^>]*>`)
+
+// EmbedImages inline the local images as base64 and replace the remote images with a link
+// so the report doesn't make any network request when it's opened
+func EmbedImages(content string, baseDir string) string {
+	return imgRegex.ReplaceAllStringFunc(content, func(tag string) string {
+		src := imgRegex.FindStringSubmatch(tag)[1]
+		if strings.HasPrefix(src, "data:") {
+			return tag
+		}
+		if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") || strings.HasPrefix(src, "//") {
+			return fmt.Sprintf(`<a href="%s">%s</a>`, src, src)
+		}
+
+		imgPath := utils.NormalizePath(src)
+		if !filepath.IsAbs(imgPath) {
+			imgPath = path.Join(baseDir, imgPath)
+		}
+		data := ""
+		if utils.FileExists(imgPath) {
+			data = utils.ImageAsBase64(imgPath)
+		}
+		if data == "" {
+			return fmt.Sprintf(`<em>missing image: %s</em>`, path.Base(src))
+		}
+		return strings.Replace(tag, fmt.Sprintf(`src="%s"`, src), fmt.Sprintf(`src="data:%s;base64,%s"`, imageMimeType(imgPath), data), 1)
+	})
+}
+
+func imageMimeType(filename string) string {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".gif":
+		return "image/gif"
+	case ".svg":
+		return "image/svg+xml"
+	case ".webp":
+		return "image/webp"
+	}
+	return "image/png"
+}
+
+var headingRegex = regexp.MustCompile(`<h([1-3]) id="([^"]+)">(.*?)</h[1-3]>`)
+var tagRegex = regexp.MustCompile(`<[^>]+>`)
+
+// GenTOC generate the table of contents from the heading of the HTML content
+func GenTOC(content string) (toc []TOCItem) {
+	for _, match := range headingRegex.FindAllStringSubmatch(content, -1) {
+		toc = append(toc, TOCItem{
+			Level: cast.ToInt(match[1]),
+			ID:    match[2],
+			Title: strings.TrimSpace(tagRegex.ReplaceAllString(match[3], "")),
+		})
+	}
+	return toc
+}
+
+// CSVToMarkdown render the CSV file as a markdown table, sorted by the column and limited to the number of rows
+func CSVToMarkdown(src string, sortBy string, limit int) string {
+	f, err := os.Open(utils.NormalizePath(src))
+	if err != nil {
+		utils.ErrorF("File not found: %v", src)
+		return ""
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil || len(records) == 0 {
+		utils.ErrorF("Error reading CSV file %v: %v", src, err)
+		return ""
+	}
+
+	header, rows := records[0], records[1:]
+	if sortBy != "" {
+		desc := strings.HasPrefix(sortBy, "-")
+		sortBy = strings.TrimPrefix(sortBy, "-")
+		column := -1
+		for i, name := range header {
+			if strings.EqualFold(strings.TrimSpace(name), sortBy) {
+				column = i
+			}
+		}
+		if column >= 0 {
+			sort.SliceStable(rows, func(i, j int) bool {
+				left, right := csvCell(rows[i], column), csvCell(rows[j], column)
+				less := left < right
+				if leftNum, err := cast.ToFloat64E(left); err == nil {
+					if rightNum, err := cast.ToFloat64E(right); err == nil {
+						less = leftNum < rightNum
+					}
+				}
+				if desc {
+					return !less && left != right
+				}
+				return less
+			})
+		}
+	}
+	if limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+
+	escape := func(cell string) string {
+		return strings.ReplaceAll(strings.ReplaceAll(strings.TrimSpace(cell), "|", `\|`), "\n", " ")
+	}
+	var table strings.Builder
+	var separators []string
+	for i := range header {
+		header[i] = escape(header[i])
+		separators = append(separators, "---")
+	}
+	table.WriteString("| " + strings.Join(header, " | ") + " |\n")
+	table.WriteString("| " + strings.Join(separators, " | ") + " |\n")
+	for _, row := range rows {
+		var cells []string
+		for i := range header {
+			cells = append(cells, escape(csvCell(row, i)))
+		}
+		table.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	return table.String()
+}
+
+func csvCell(row []string, index int) string {
+	if index < len(row) {
+		return row[index]
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package core
+
+// defaultReportTemplate the built-in pongo2 template of the HTML report, it must not load anything from the network
+const defaultReportTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="generator" content="osmedeus {{ Report.Version }}">
+<title>{{ Report.Title }}</title>
+<style>{{ Report.CSS|safe }}</style>
+</head>
+<body>
+<header class="report-header">
+  <h1 class="report-title">{{ Report.Title }}</h1>
+  <p class="report-meta">Workflow: <strong>{{ Report.Workflow }}</strong> &middot; Generated at {{ Report.GeneratedAt }} &middot; osmedeus {{ Report.Version }}</p>
+</header>
+{% if Report.TOC %}
+<nav class="report-toc">
+  <h2>Table of Contents</h2>
+  <ul>
+  {% for item in Report.TOC %}
+    <li class="toc-level-{{ item.Level }}"><a href="#{{ item.ID }}">{{ item.Title|safe }}</a></li>
+  {% endfor %}
+  </ul>
+</nav>
+{% endif %}
+<main class="report-content">
+{{ Report.Content|safe }}
+</main>
+<script>{{ Script|safe }}</script>
+</body>
+</html>
+`
+
+// defaultReportCSS used when there is no style.css in the data folder
+const defaultReportCSS = `
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; color: #24292f; max-width: 1200px; margin: 0 auto; padding: 24px; line-height: 1.5; }
+.report-header { border-bottom: 2px solid #d0d7de; margin-bottom: 16px; }
+.report-meta { color: #57606a; font-size: 14px; }
+.report-toc { background: #f6f8fa; border: 1px solid #d0d7de; border-radius: 6px; padding: 8px 16px; margin-bottom: 24px; }
+.report-toc ul { list-style: none; padding-left: 0; }
+.report-toc .toc-level-2 { padding-left: 16px; }
+.report-toc .toc-level-3 { padding-left: 32px; }
+a { color: #0969da; text-decoration: none; }
+h1, h2, h3 { border-bottom: 1px solid #d8dee4; padding-bottom: 4px; }
+pre, code { background: #f6f8fa; border-radius: 6px; font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 13px; }
+pre { padding: 12px; overflow: auto; }
+table { border-collapse: collapse; margin: 12px 0; width: 100%; font-size: 14px; }
+th, td { border: 1px solid #d0d7de; padding: 6px 10px; text-align: left; word-break: break-all; }
+th { background: #f6f8fa; cursor: pointer; user-select: none; }
+th.sort-asc::after { content: " \25B2"; }
+th.sort-desc::after { content: " \25BC"; }
+tr:nth-child(even) { background: #fbfcfd; }
+img { max-width: 100%; border: 1px solid #d0d7de; border-radius: 6px; }
+details { margin: 8px 0; }
+.gallery { display: grid; grid-template-columns: repeat(auto-fill, minmax(280px, 1fr)); gap: 12px; }
+.gallery figure { margin: 0; }
+.gallery figcaption { color: #57606a; font-size: 12px; word-break: break-all; }
+.bar-chart { max-width: 100%; height: auto; }
+`
+
+// sortableTableScript sort the table by clicking on the header
+const sortableTableScript = `
+document.querySelectorAll("table").forEach(function (table) {
+  var headers = table.querySelectorAll("th");
+  headers.forEach(function (th, index) {
+    th.addEventListener("click", function () {
+      var body = table.tBodies[0];
+      if (!body) { return; }
+      var asc = !th.classList.contains("sort-asc");
+      headers.forEach(function (h) { h.classList.remove("sort-asc", "sort-desc"); });
+      th.classList.add(asc ? "sort-asc" : "sort-desc");
+      var rows = Array.prototype.slice.call(body.rows);
+      rows.sort(function (a, b) {
+        var x = a.cells[index] ? a.cells[index].innerText.trim() : "";
+        var y = b.cells[index] ? b.cells[index].innerText.trim() : "";
+        var nx = parseFloat(x), ny = parseFloat(y);
+        var result = (!isNaN(nx) && !isNaN(ny)) ? nx - ny : x.localeCompare(y);
+        return asc ? result : -result;
+      });
+      rows.forEach(function (row) { body.appendChild(row); });
+    });
+  });
+});
+`
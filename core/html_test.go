@@ -0,0 +1,52 @@
+package core
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+func TestMarkDownToHTML(t *testing.T) {
+	var opt libs.Options
+	opt.Env.DataFolder = t.TempDir()
+	dir := t.TempDir()
+
+	// png header is enough for the test
+	os.WriteFile(path.Join(dir, "shot.png"), []byte("\x89PNG\r\n\x1a\n"), 0644)
+	utils.WriteToFile(path.Join(dir, "hosts.csv"), "host,port\nb.example.com,443\na.example.com,80\nc.example.com,8080\n")
+
+	md := "# Summary\n\n## Screenshots\n\n![shot](shot.png)\n\n![remote](https://example.com/logo.png)\n\n## Hosts\n\n" + CSVToMarkdown(path.Join(dir, "hosts.csv"), "-port", 2)
+	mdFile := path.Join(dir, "summary.md")
+	utils.WriteToFile(mdFile, md)
+
+	output := path.Join(dir, "summary.html")
+	if err := MarkDownToHTML(opt, "example.com", "general", mdFile, output); err != nil {
+		t.Fatalf("Error MarkDownToHTML: %v", err)
+	}
+	content := utils.GetFileContent(output)
+
+	if !strings.Contains(content, `src="data:image/png;base64,`) {
+		t.Errorf("Error embedding the local image")
+	}
+	if strings.Contains(content, `src="http`) || strings.Contains(content, "<link") {
+		t.Errorf("Error the report should not load remote resources")
+	}
+	if !strings.Contains(content, `href="#screenshots"`) || !strings.Contains(content, "<style>") {
+		t.Errorf("Error generating TOC or inline CSS")
+	}
+	if !strings.Contains(content, "c.example.com") || strings.Contains(content, "a.example.com") {
+		t.Errorf("Error sorting or limiting the CSV table")
+	}
+
+	// the template could be overridden per flow
+	os.MkdirAll(path.Join(opt.Env.DataFolder, "markdown"), 0755)
+	utils.WriteToFile(path.Join(opt.Env.DataFolder, "markdown", "general.html"), "<html>custom {{ Report.Target }}</html>")
+	MarkDownToHTML(opt, "example.com", "general", mdFile, output)
+	if strings.TrimSpace(utils.GetFileContent(output)) != "<html>custom example.com</html>" {
+		t.Errorf("Error using the flow template: %v", utils.GetFileContent(output))
+	}
+}
@@ -1,574 +1,563 @@
 package core
 
-// func (r *Runner) LoadImportScripts() string {
-// 	var output string
-
-// 	// DB scripts
-
-// 	r.VM.Set("ImportSubdomain", func(call otto.FunctionCall) otto.Value {
-// 		src := call.Argument(0).String()
-// 		r.ImportSubdomain(src)
-// 		return otto.Value{}
-// 	})
-
-// 	r.VM.Set("ImportDns", func(call otto.FunctionCall) otto.Value {
-// 		src := call.Argument(0).String()
-// 		r.ImportDns(src)
-// 		return otto.Value{}
-// 	})
-
-// 	r.VM.Set("ImportTech", func(call otto.FunctionCall) otto.Value {
-// 		src := call.Argument(0).String()
-// 		r.ImportTech(src)
-// 		return otto.Value{}
-// 	})
-
-// 	r.VM.Set("ImportHTTPJson", func(call otto.FunctionCall) otto.Value {
-// 		src := call.Argument(0).String()
-// 		r.ImportHTTPJson(src)
-// 		return otto.Value{}
-// 	})
-
-// 	r.VM.Set("ImportScreenShotJson", func(call otto.FunctionCall) otto.Value {
-// 		src := call.Argument(0).String()
-// 		r.ImportScreenShotJson(src)
-// 		return otto.Value{}
-// 	})
-
-// 	r.VM.Set("ImportPortJson", func(call otto.FunctionCall) otto.Value {
-// 		src := call.Argument(0).String()
-// 		r.ImportPortJson(src)
-// 		return otto.Value{}
-// 	})
-
-// 	r.VM.Set("ImportJaelesVulnJson", func(call otto.FunctionCall) otto.Value {
-// 		src := call.Argument(0).String()
-// 		r.ImportJaelesVulnJson(src)
-// 		return otto.Value{}
-// 	})
-
-// 	r.VM.Set("ImportNucleiVulnJson", func(call otto.FunctionCall) otto.Value {
-// 		src := call.Argument(0).String()
-// 		r.ImportNucleiVulnJson(src)
-// 		return otto.Value{}
-// 	})
-
-// 	r.VM.Set("ImportDirectoryJson", func(call otto.FunctionCall) otto.Value {
-// 		src := call.Argument(0).String()
-// 		r.ImportDirectoryJson(src)
-// 		return otto.Value{}
-// 	})
-
-// 	r.VM.Set("ImportLinks", func(call otto.FunctionCall) otto.Value {
-// 		src := call.Argument(0).String()
-// 		r.ImportLinks(src)
-// 		return otto.Value{}
-// 	})
-
-// 	r.VM.Set("ImportArchive", func(call otto.FunctionCall) otto.Value {
-// 		src := call.Argument(0).String()
-// 		r.ImportArchive(src)
-// 		return otto.Value{}
-// 	})
-
-// 	r.VM.Set("ImportIPRange", func(call otto.FunctionCall) otto.Value {
-// 		src := call.Argument(0).String()
-// 		r.ImportIPRange(src)
-// 		return otto.Value{}
-// 	})
-
-// 	r.VM.Set("ImportCred", func(call otto.FunctionCall) otto.Value {
-// 		src := call.Argument(0).String()
-// 		r.ImportCred(src)
-// 		return otto.Value{}
-// 	})
-
-// 	r.VM.Set("ImportCert", func(call otto.FunctionCall) otto.Value {
-// 		src := call.Argument(0).String()
-// 		r.ImportCert(src)
-// 		return otto.Value{}
-// 	})
-
-// 	r.VM.Set("ImportCloudBrute", func(call otto.FunctionCall) otto.Value {
-// 		src := call.Argument(0).String()
-// 		r.ImportCloudBrute(src)
-// 		return otto.Value{}
-// 	})
-
-// 	r.VM.Set("SummaryTarget", func(call otto.FunctionCall) otto.Value {
-// 		r.ScanObj.SummaryTarget()
-// 		return otto.Value{}
-// 	})
-
-// 	return output
-// }
-
-// func (r *Runner) ImportSubdomain(src string) {
-// 	if !utils.FileExists(src) {
-// 		utils.ErrorF("file not found: %v", src)
-// 		return
-// 	}
-
-// 	domains := utils.ReadingLines(src)
-// 	var objs []database.Asset
-
-// 	for _, domain := range domains {
-// 		domain = strings.TrimSpace(domain)
-// 		if domain == "" {
-// 			continue
-// 		}
-
-// 		obj := database.Asset{
-// 			AssetValue: domain,
-// 			//Dns:           nil,
-// 			HTTP:          nil,
-// 			Directory:     nil,
-// 			Vulnerability: nil,
-// 			ScanRefer:     r.ScanObj.ID,
-// 			TargetRefer:   r.ScanObj.TargetRefer,
-// 		}
-// 		objs = append(objs, obj)
-// 	}
-// 	database.ImportAssets(objs)
-// }
-
-// func (r *Runner) ImportDns(src string) {
-// 	if !utils.FileExists(src) {
-// 		utils.ErrorF("file not found: %v", src)
-// 		return
-// 	}
-
-// 	content := utils.ReadingLines(src)
-// 	for _, line := range content {
-// 		line = strings.TrimSpace(line)
-// 		if line == "" || !strings.Contains(line, " ") {
-// 			continue
-// 		}
-
-// 		raw := strings.Split(line, " ")
-// 		domain := strings.Trim(raw[0], ".")
-// 		dnsType := strings.TrimSpace(raw[1])
-// 		dnsValue := strings.Trim(raw[2], ".")
-
-// 		if strings.TrimSpace(domain) == "" || strings.TrimSpace(dnsValue) == "" {
-// 			continue
-// 		}
-
-// 		dnsChecksum := utils.GenHash(fmt.Sprintf("%s-%s-%s", domain, dnsType, dnsValue))
-
-// 		obj := database.Dns{
-// 			Domain:      domain,
-// 			DnsType:     dnsType,
-// 			DnsValue:    dnsValue,
-// 			DnsChecksum: dnsChecksum,
-// 			ScanRefer:   r.ScanObj.ID,
-// 			TargetRefer: r.ScanObj.TargetRefer,
-// 		}
-// 		obj.Create()
-// 	}
-// }
-
-// func (r *Runner) ImportTech(src string) {
-// 	if !utils.FileExists(src) {
-// 		utils.ErrorF("file not found: %v", src)
-// 		return
-// 	}
-
-// 	content := utils.ReadingLines(src)
-// 	for _, line := range content {
-// 		if !strings.Contains(line, ";;") {
-// 			utils.ErrorF("Invalid format: %v", line)
-// 			continue
-// 		}
-
-// 		// data should be domain|%v;;techs|%v
-// 		domain := strings.TrimPrefix(strings.Split(line, ";;")[0], "domain|")
-// 		techs := strings.TrimPrefix(strings.Split(line, ";;")[1], "techs|")
-// 		if strings.TrimSpace(techs) == "" {
-// 			utils.ErrorF("Invalid format: %v", line)
-// 			continue
-// 		}
-
-// 		obj := database.Asset{
-// 			AssetValue:  domain,
-// 			Technology:  techs,
-// 			IsAlive:     true,
-// 			ScanRefer:   r.ScanObj.ID,
-// 			TargetRefer: r.ScanObj.TargetRefer,
-// 		}
-
-// 		obj.UpdateTech()
-// 	}
-// }
-
-// func (r *Runner) ImportHTTPJson(src string) {
-// 	if !utils.FileExists(src) {
-// 		utils.ErrorF("file not found: %v", src)
-// 		return
-// 	}
-
-// 	content := utils.ReadingLines(src)
-
-// 	baseResult, _ := filepath.Abs(src)
-// 	baseResult = path.Dir(baseResult)
-// 	utils.DebugF("Set Base Dir for content: %v", baseResult)
-
-// 	for _, line := range content {
-// 		if strings.TrimSpace(line) == "" {
-// 			continue
-// 		}
-// 		jsonParsed, err := gabs.ParseJSON([]byte(line))
-// 		if err != nil {
-// 			continue
-// 		}
-
-// 		//  {"url":"https://sso-na.tesla.com","title":"Blank Title","checksum":"527ef0b39a78caf74f54ca5b2ffb59bcfe688685","content_file":"overview/contents/https___sso-na.tesla.com.txt","status":"302","time":"0.046268348","length":"90","redirect":"https://teamchatgl.tesla.com/"}
-// 		URL := jsonParsed.S("url").Data().(string)
-// 		title := jsonParsed.S("title").Data().(string)
-// 		checksum := jsonParsed.S("checksum").Data().(string)
-// 		contentPath := jsonParsed.S("content_file").Data().(string)
-// 		status := jsonParsed.S("status").Data().(string)
-// 		length := jsonParsed.S("length").Data().(string)
-// 		redirect := jsonParsed.S("redirect").Data().(string)
-
-// 		if !utils.FileExists(contentPath) {
-// 			contentPath = path.Join(baseResult, contentPath)
-// 		}
-
-// 		// base64 content
-// 		data := "No-Content"
-// 		if !strings.Contains(contentPath, "No-Content") {
-// 			//utils.DebugF("Reading HTML content: %v", contentPath)
-// 			data = utils.ImageAsBase64(contentPath)
-// 		}
-
-// 		obj := database.HTTP{
-// 			URL:           URL,
-// 			Title:         title,
-// 			Checksum:      checksum,
-// 			StatusCode:    cast.ToInt(status),
-// 			ContentLength: cast.ToInt(length),
-// 			HTTPContent:   data,
-// 			Redirect:      redirect,
-// 			ScanRefer:     r.ScanObj.ID,
-// 			TargetRefer:   r.ScanObj.TargetRefer,
-// 		}
-// 		obj.CreateHTTP()
-// 	}
-// }
-
-// func (r *Runner) ImportScreenShotJson(src string) {
-// 	if !utils.FileExists(src) {
-// 		utils.ErrorF("file not found: %v", src)
-// 		return
-// 	}
-
-// 	baseResult, _ := filepath.Abs(src)
-// 	baseResult = path.Dir(baseResult)
-// 	content := utils.ReadingLines(src)
-// 	utils.DebugF("Set Base Dir for screenshot: %v", baseResult)
-
-// 	for _, line := range content {
-// 		if strings.TrimSpace(line) == "" {
-// 			continue
-// 		}
-// 		jsonParsed, err := gabs.ParseJSON([]byte(line))
-// 		if err != nil {
-// 			continue
-// 		}
-
-// 		URL := jsonParsed.S("url").Data().(string)
-// 		imgPath := jsonParsed.S("image").Data().(string)
-// 		tech := jsonParsed.S("tech").Data().(string)
-// 		if tech != "" {
-// 			//domain, _ := utils.GetDomain(URL)
-// 			utils.DebugF("more tech: %v", tech)
-// 			//database.NewTech(wsObj, domain, tech)
-// 		}
-
-// 		if !utils.FileExists(imgPath) {
-// 			imgPath = path.Join(baseResult, imgPath)
-// 		}
-// 		imgData := utils.ImageAsBase64(imgPath)
-
-// 		//database.NewScreenshot(wsObj, URL, data)
-
-// 		obj := database.HTTP{
-// 			URL: URL,
-// 			//Title:          tittle,
-// 			//Checksum:       "",
-// 			//StatusCode:     0,
-// 			//ContentLength:  0,
-// 			//HTTPContent:    "",
-// 			ScreenShotData: imgData,
-// 			ScanRefer:      r.ScanObj.ID,
-// 			TargetRefer:    r.ScanObj.TargetRefer,
-// 		}
-// 		obj.CreateScreenShot()
-// 	}
-// }
-
-// type PortObj struct {
-// 	Protocol string
-// 	PortID   string
-// 	State    string
-// 	Service  struct {
-// 		Name    string
-// 		Product string
-// 		Cpe     string
-// 	}
-// 	Script struct {
-// 		ID     string
-// 		Output string
-// 	}
-// }
-
-// func (r *Runner) ImportPortJson(src string) {
-// 	if !utils.FileExists(src) {
-// 		utils.ErrorF("file not found: %v", src)
-// 		return
-// 	}
-// 	content := utils.ReadingLines(src)
-
-// 	for _, line := range content {
-// 		if strings.TrimSpace(line) == "" {
-// 			continue
-// 		}
-// 		jsonParsed, err := gabs.ParseJSON([]byte(line))
-// 		if err != nil {
-// 			continue
-// 		}
-
-// 		var portObj []PortObj
-
-// 		ipAddress := jsonParsed.S("IPAddress").Data().(string)
-// 		rawPorts := jsonParsed.S("Ports").Bytes()
-// 		err = jsoniter.Unmarshal(rawPorts, &portObj)
-// 		if err != nil || len(portObj) == 0 {
-// 			continue
-// 		}
-
-// 		var ports []string
-// 		for _, p := range portObj {
-// 			info := fmt.Sprintf("%v/%v/%v", p.PortID, p.Protocol, p.Service.Product)
-// 			ports = append(ports, strings.Trim(info, "/"))
-// 		}
-// 		if len(ports) == 0 {
-// 			continue
-// 		}
-
-// 		obj := database.Dns{
-// 			DnsValue:    ipAddress,
-// 			DnsType:     "A",
-// 			Ports:       strings.Join(ports, ","),
-// 			ScanRefer:   r.ScanObj.ID,
-// 			TargetRefer: r.ScanObj.TargetRefer,
-// 		}
-// 		obj.UpdatePort()
-// 	}
-// }
-
-// // ImportJaelesVulnJson import new asset to DB
-// func (r *Runner) ImportJaelesVulnJson(src string) {
-// 	if !utils.FileExists(src) {
-// 		utils.ErrorF("file not found: %v", src)
-// 		return
-// 	}
-// 	content := utils.ReadingLines(src)
-// 	baseDir := path.Dir(src)
-// 	for _, line := range content {
-// 		if strings.TrimSpace(line) == "" {
-// 			continue
-// 		}
-
-// 		jsonParsed, err := gabs.ParseJSON([]byte(line))
-// 		if err != nil {
-// 			utils.ErrorF("Error parse JSON Data")
-// 			continue
-// 		}
-// 		raw := jsonParsed.S("OutputFile").Data().(string)
-// 		reportPath := raw
-// 		if !utils.FileExists(reportPath) {
-// 			reportPath = path.Join(baseDir, raw)
-// 			if !utils.FileExists(reportPath) {
-// 				reportPath = path.Join(path.Dir(baseDir), raw)
-// 			}
-// 		}
-// 		vulnContent := utils.GetFileContent(reportPath)
-
-// 		// parse Data as JSON
-// 		jsonParsed, err = gabs.ParseJSON([]byte(vulnContent))
-// 		if err != nil {
-// 			utils.ErrorF("Error parse JSON Data")
-// 			continue
-// 		}
-
-// 		obj := database.Vulnerability{
-// 			URL:                jsonParsed.S("URL").Data().(string),
-// 			VulnRequest:        jsonParsed.S("Req").Data().(string),
-// 			VulnResponse:       jsonParsed.S("Res").Data().(string),
-// 			DetectionString:    jsonParsed.S("DetectionString").Data().(string),
-// 			VulnerabilityTitle: jsonParsed.S("SignName").Data().(string),
-// 			SignatureID:        jsonParsed.S("SignID").Data().(string),
-// 			Confidence:         jsonParsed.S("Confidence").Data().(string),
-// 			Severity:           jsonParsed.S("Risk").Data().(string),
-// 			Source:             "Jaeles",
-// 			//VulnChecksum:       utils.GenHash(vulnData),
-// 			//PluginScan: SelectScanID(workspace, pluginName, scanID),
-// 			//Target:     SelectScanByWS(workspace),
-// 			//Asset:      SelectAssetByData(domain),
-// 			ScanRefer:   r.ScanObj.ID,
-// 			TargetRefer: r.ScanObj.TargetRefer,
-// 		}
-// 		obj.Create()
-// 	}
-// }
-
-// // ImportNucleiVulnJson import new asset to DB
-// func (r *Runner) ImportNucleiVulnJson(src string) {
-// 	if !utils.FileExists(src) {
-// 		utils.ErrorF("file not found: %v", src)
-// 		return
-// 	}
-// 	content := utils.ReadingLines(src)
-// 	for _, line := range content {
-// 		if strings.TrimSpace(line) == "" {
-// 			continue
-// 		}
-// 		jsonParsed, err := gabs.ParseJSON([]byte(line))
-// 		if err != nil {
-// 			utils.ErrorF("Error parse JSON Data")
-// 			continue
-// 		}
-
-// 		obj := database.Vulnerability{
-// 			URL:             jsonParsed.S("host").Data().(string),
-// 			VulnRequest:     utils.Base64Encode(jsonParsed.S("request").Data().(string)),
-// 			VulnResponse:    utils.Base64Encode(jsonParsed.S("response").Data().(string)),
-// 			DetectionString: jsonParsed.S("matched").Data().(string),
-
-// 			SignatureID:        jsonParsed.S("templateID").Data().(string),
-// 			Confidence:         "Tentative",
-// 			VulnerabilityTitle: jsonParsed.S("info", "name").Data().(string),
-// 			Severity:           jsonParsed.S("info", "severity").Data().(string),
-// 			Source:             "Nuclei",
-// 			TargetRefer:        r.ScanObj.TargetRefer,
-
-// 			ScanRefer: r.ScanObj.ID,
-// 		}
-// 		obj.Create()
-// 	}
-// }
-
-// // ImportDirectoryJson import new asset to DB
-// func (r *Runner) ImportDirectoryJson(src string) {
-// 	if !utils.FileExists(src) {
-// 		utils.ErrorF("file not found: %v", src)
-// 		return
-// 	}
-// 	content := utils.ReadingLines(src)
-// 	for _, line := range content {
-// 		if strings.TrimSpace(line) == "" || !strings.Contains(line, "url") {
-// 			continue
-// 		}
-
-// 		jsonParsed, err := gabs.ParseJSON([]byte(line))
-// 		if err != nil {
-// 			utils.ErrorF("Error parse JSON Data")
-// 			continue
-// 		}
-
-// 		// some parser here
-// 		URL := jsonParsed.S("url").Data().(string)
-// 		redirect, ok := jsonParsed.S("redirectlocation").Data().(string)
-// 		if ok {
-// 			redirect = ""
-// 		}
-
-// 		obj := database.Directory{
-// 			URL:           URL,
-// 			Status:        cast.ToInt(jsonParsed.S("status").Data()),
-// 			ContentLength: cast.ToInt(jsonParsed.S("length").Data()),
-// 			Words:         cast.ToInt(jsonParsed.S("words").Data()),
-// 			RedirectURL:   redirect,
-// 			ScanRefer:     r.ScanObj.ID,
-// 			TargetRefer:   r.ScanObj.TargetRefer,
-// 		}
-// 		obj.Create()
-// 	}
-// }
-
-//
-//// UpdateJSONDns update dns in db
-//func UpdateJSONDns(src string, options libs.Options) {
-//	content := utils.ReadingLines(src)
-//	if len(content) == 0 {
-//		utils.ErrorF("File not found: %v", src)
-//		return
-//	}
-//
-//	utils.DebugF("Update JSON DNS: %v", src)
-//	//wsObj := database.SelectScanByWS(options.Scan.ROptions["Workspace"])
-//	target := options.Scan.ROptions["Workspace"]
-//
-//	for _, line := range content {
-//		jsonParsed, err := gabs.ParseJSON([]byte(line))
-//		if err != nil {
-//			continue
-//		}
-//
-//		domain, ok := jsonParsed.S("host").Data().(string)
-//		if !ok {
-//			continue
-//		}
-//
-//		// filtered some unrelated domain
-//		if !strings.HasSuffix(domain, fmt.Sprintf(".%s", target)) {
-//			if domain != target {
-//				continue
-//			}
-//		}
-//
-//		data := "N/A"
-//		isError := jsonParsed.S("status_code").Data().(string)
-//		if isError == "NXDOMAIN" {
-//			//database.NewAssetWithDns(wsObj, domain, data)
-//			continue
-//		}
-//		var results []string
-//		a := jsonParsed.S("a")
-//		if a != nil {
-//			for _, record := range a.Children() {
-//				data := fmt.Sprintf("A/%s", cast.ToString(record.Data()))
-//				results = append(results, data)
-//			}
-//		}
-//
-//		cname := jsonParsed.S("cname")
-//		if cname != nil {
-//			for _, record := range cname.Children() {
-//				data := fmt.Sprintf("CNAME/%s", cast.ToString(record.Data()))
-//				results = append(results, data)
-//			}
-//		}
-//
-//		mx := jsonParsed.S("mx")
-//		if mx != nil {
-//			for _, record := range mx.Children() {
-//				data := fmt.Sprintf("MX/%s", cast.ToString(record.Data()))
-//				results = append(results, data)
-//			}
-//		}
-//
-//		ns := jsonParsed.S("ns")
-//		if ns != nil {
-//			for _, record := range ns.Children() {
-//				data := fmt.Sprintf("NS/%s", cast.ToString(record.Data()))
-//				results = append(results, data)
-//			}
-//		}
-//		results = funk.UniqString(results)
-//		data = strings.Join(results, ";;")
-//		//database.NewAssetWithDns(wsObj, domain, data)
-//	}
-//}
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/fatih/color"
+	"github.com/robertkrimen/otto"
+	"github.com/spf13/cast"
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/execution"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// LoadImportScripts import the output of the modules to the asset inventory
+func (r *Runner) LoadImportScripts() string {
+	var output string
+
+	importers := map[string]func(string){
+		ImportSubdomain:      r.ImportSubdomain,
+		ImportDns:            r.ImportDns,
+		ImportTech:           r.ImportTech,
+		ImportHTTPJson:       r.ImportHTTPJson,
+		ImportScreenShotJson: r.ImportScreenShotJson,
+		ImportDirectoryJson:  r.ImportDirectoryJson,
+		ImportPortJson:       r.ImportPortJson,
+		ImportJaelesVulnJson: r.ImportJaelesVulnJson,
+		ImportNucleiVulnJson: r.ImportNucleiVulnJson,
+		ImportFindingJson:    r.ImportFindingJson,
+		ImportCred:           r.ImportCred,
+	}
+
+	for name, importer := range importers {
+		importer := importer
+		r.VM.Set(name, func(call otto.FunctionCall) otto.Value {
+			importer(call.Argument(0).String())
+			return otto.Value{}
+		})
+	}
+
+	return output
+}
+
+// readImportFile read the non-empty lines of the file if the asset inventory is enabled
+func (r *Runner) readImportFile(src string) []string {
+	if r.Opt.NoDB || database.DB == nil {
+		utils.DebugF("Database is disabled, skip importing: %v", src)
+		return nil
+	}
+	if !utils.FileExists(src) {
+		utils.ErrorF("file not found: %v", src)
+		return nil
+	}
+
+	var content []string
+	for _, line := range utils.ReadingLines(src) {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			content = append(content, line)
+		}
+	}
+	return content
+}
+
+func (r *Runner) importResult(kind string, total int, err error) {
+	if err != nil {
+		utils.ErrorF("[DB] Error importing %v records: %v", kind, err)
+		return
+	}
+	utils.InforF("Imported %v %v records", color.HiMagentaString("%v", total), kind)
+}
+
+// jsonValue get the first non-empty value of the keys, nested key is separated by dot
+func jsonValue(jsonParsed *gabs.Container, keys ...string) string {
+	for _, key := range keys {
+		value := strings.TrimSpace(cast.ToString(jsonParsed.Path(key).Data()))
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// jsonList get the value of the key as a comma separated string
+func jsonList(jsonParsed *gabs.Container, key string) string {
+	var values []string
+	for _, child := range jsonParsed.Path(key).Children() {
+		if value := cast.ToString(child.Data()); value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return jsonValue(jsonParsed, key)
+	}
+	return strings.Join(values, ",")
+}
+
+// resolveImportPath the path in the summary file might be relative to it
+func resolveImportPath(src string, filename string) string {
+	if filename == "" || utils.FileExists(filename) {
+		return filename
+	}
+	baseDir, _ := filepath.Abs(src)
+	baseDir = path.Dir(baseDir)
+	if utils.FileExists(path.Join(baseDir, filename)) {
+		return path.Join(baseDir, filename)
+	}
+	return path.Join(path.Dir(baseDir), filename)
+}
+
+func getHost(raw string) string {
+	if !strings.Contains(raw, "://") {
+		return strings.Split(raw, ":")[0]
+	}
+	host, _ := utils.GetDomain(raw)
+	return host
+}
+
+// ImportSubdomain import the list of subdomains
+func (r *Runner) ImportSubdomain(src string) {
+	var objs []database.Asset
+	for _, domain := range r.readImportFile(src) {
+		objs = append(objs, database.Asset{
+			AssetValue:  strings.ToLower(domain),
+			ScanRefer:   r.ScanObj.ID,
+			TargetRefer: r.TargetObj.ID,
+		})
+	}
+	r.importResult("subdomain", len(objs), database.ImportAssets(objs))
+}
+
+// ImportDns import DNS records in the format of: domain type value
+func (r *Runner) ImportDns(src string) {
+	var objs []database.Dns
+	var assets []database.Asset
+	for _, line := range r.readImportFile(src) {
+		raw := strings.Fields(line)
+		if len(raw) < 3 {
+			continue
+		}
+		domain := strings.ToLower(strings.Trim(raw[0], "."))
+		dnsType := strings.ToUpper(raw[1])
+		dnsValue := strings.Trim(raw[2], ".")
+		if domain == "" || dnsValue == "" {
+			continue
+		}
+
+		objs = append(objs, database.Dns{
+			Domain:      domain,
+			DnsType:     dnsType,
+			DnsValue:    dnsValue,
+			DnsChecksum: utils.GenHash(fmt.Sprintf("%s-%s-%s", domain, dnsType, dnsValue)),
+			ScanRefer:   r.ScanObj.ID,
+			TargetRefer: r.TargetObj.ID,
+		})
+		assets = append(assets, database.Asset{
+			AssetValue:  domain,
+			ScanRefer:   r.ScanObj.ID,
+			TargetRefer: r.TargetObj.ID,
+		})
+	}
+
+	if err := database.ImportAssets(assets); err != nil {
+		utils.ErrorF("[DB] Error importing subdomain records: %v", err)
+	}
+	r.importResult("dns", len(objs), database.ImportDns(objs))
+}
+
+// ImportTech import technologies in the format of: domain|<domain>;;techs|<techs>
+func (r *Runner) ImportTech(src string) {
+	var objs []database.Asset
+	for _, line := range r.readImportFile(src) {
+		if !strings.Contains(line, ";;") {
+			utils.DebugF("Invalid format: %v", line)
+			continue
+		}
+
+		domain := strings.TrimPrefix(strings.Split(line, ";;")[0], "domain|")
+		techs := strings.TrimPrefix(strings.Split(line, ";;")[1], "techs|")
+		if strings.TrimSpace(techs) == "" {
+			continue
+		}
+
+		objs = append(objs, database.Asset{
+			AssetValue:  getHost(domain),
+			Technology:  techs,
+			IsAlive:     true,
+			ScanRefer:   r.ScanObj.ID,
+			TargetRefer: r.TargetObj.ID,
+		})
+	}
+	r.importResult("technology", len(objs), database.UpdateAssetTech(objs))
+}
+
+// ImportHTTPJson import HTTP endpoints from the httpx JSON output or the content summary
+func (r *Runner) ImportHTTPJson(src string) {
+	var objs []database.HTTP
+	var assets, techAssets []database.Asset
+	for _, line := range r.readImportFile(src) {
+		jsonParsed, err := gabs.ParseJSON([]byte(line))
+		if err != nil {
+			continue
+		}
+
+		URL := jsonValue(jsonParsed, "url")
+		if URL == "" {
+			continue
+		}
+
+		contentFile := jsonValue(jsonParsed, "content_file")
+		if contentFile != "" && !strings.Contains(contentFile, "No-Content") {
+			contentFile = resolveImportPath(src, contentFile)
+		}
+
+		obj := database.HTTP{
+			URL:           URL,
+			Host:          getHost(URL),
+			Title:         jsonValue(jsonParsed, "title"),
+			StatusCode:    cast.ToInt(jsonValue(jsonParsed, "status_code", "status")),
+			ContentLength: cast.ToInt(jsonValue(jsonParsed, "content_length", "length")),
+			Checksum:      jsonValue(jsonParsed, "checksum", "hash.body_sha256", "hash.body_md5"),
+			Redirect:      jsonValue(jsonParsed, "location", "redirect"),
+			Technology:    jsonList(jsonParsed, "tech"),
+			ContentFile:   contentFile,
+			ScanRefer:     r.ScanObj.ID,
+			TargetRefer:   r.TargetObj.ID,
+		}
+		objs = append(objs, obj)
+
+		// keep the technologies detected by the previous import
+		asset := database.Asset{
+			AssetValue:  obj.Host,
+			Technology:  obj.Technology,
+			IsAlive:     true,
+			ScanRefer:   r.ScanObj.ID,
+			TargetRefer: r.TargetObj.ID,
+		}
+		if asset.Technology == "" {
+			assets = append(assets, asset)
+		} else {
+			techAssets = append(techAssets, asset)
+		}
+	}
+
+	if err := database.ImportAssets(assets); err != nil {
+		utils.ErrorF("[DB] Error importing subdomain records: %v", err)
+	}
+	if err := database.UpdateAssetTech(techAssets); err != nil {
+		utils.ErrorF("[DB] Error importing subdomain records: %v", err)
+	}
+	r.importResult("http", len(objs), database.ImportHTTP(objs))
+}
+
+// ImportScreenShotJson import the screenshot of HTTP endpoints in the format of: {"url": "", "image": ""}
+func (r *Runner) ImportScreenShotJson(src string) {
+	var objs []database.HTTP
+	for _, line := range r.readImportFile(src) {
+		jsonParsed, err := gabs.ParseJSON([]byte(line))
+		if err != nil {
+			continue
+		}
+
+		URL := jsonValue(jsonParsed, "url")
+		imgPath := jsonValue(jsonParsed, "image", "screenshot")
+		if URL == "" || imgPath == "" {
+			continue
+		}
+
+		objs = append(objs, database.HTTP{
+			URL:         URL,
+			Host:        getHost(URL),
+			ScreenShot:  resolveImportPath(src, imgPath),
+			ScanRefer:   r.ScanObj.ID,
+			TargetRefer: r.TargetObj.ID,
+		})
+	}
+	r.importResult("screenshot", len(objs), database.UpdateHTTPScreenShot(objs))
+}
+
+// ImportDirectoryJson import the content discovery result as HTTP endpoints
+func (r *Runner) ImportDirectoryJson(src string) {
+	var objs []database.HTTP
+	for _, line := range r.readImportFile(src) {
+		jsonParsed, err := gabs.ParseJSON([]byte(line))
+		if err != nil {
+			continue
+		}
+
+		URL := jsonValue(jsonParsed, "url")
+		if URL == "" {
+			continue
+		}
+
+		objs = append(objs, database.HTTP{
+			URL:           URL,
+			Host:          getHost(URL),
+			StatusCode:    cast.ToInt(jsonValue(jsonParsed, "status")),
+			ContentLength: cast.ToInt(jsonValue(jsonParsed, "length")),
+			Redirect:      jsonValue(jsonParsed, "redirectlocation"),
+			ScanRefer:     r.ScanObj.ID,
+			TargetRefer:   r.TargetObj.ID,
+		})
+	}
+	r.importResult("directory", len(objs), database.ImportHTTP(objs))
+}
+
+// ImportPortJson import open ports from the nmap summary or the naabu JSON output
+func (r *Runner) ImportPortJson(src string) {
+	var objs []database.Port
+	for _, line := range r.readImportFile(src) {
+		jsonParsed, err := gabs.ParseJSON([]byte(line))
+		if err != nil {
+			continue
+		}
+
+		// {"host":"example.com","ip":"1.2.3.4","port":443,"protocol":"tcp"}
+		if jsonParsed.Exists("port") {
+			ip := jsonValue(jsonParsed, "ip", "host")
+			objs = append(objs, database.Port{
+				Host:        jsonValue(jsonParsed, "host"),
+				IPAddress:   ip,
+				PortID:      cast.ToInt(jsonValue(jsonParsed, "port")),
+				Protocol:    portProtocol(jsonValue(jsonParsed, "protocol")),
+				State:       "open",
+				ScanRefer:   r.ScanObj.ID,
+				TargetRefer: r.TargetObj.ID,
+			})
+			continue
+		}
+
+		// {"IPAddress":"1.2.3.4","Host":"example.com","Ports":[{"Protocol":"tcp","PortID":"443","State":"open","Service":{"Name":"https","Product":"nginx","Cpe":""}}]}
+		ip := jsonValue(jsonParsed, "IPAddress")
+		for _, p := range jsonParsed.S("Ports").Children() {
+			objs = append(objs, database.Port{
+				Host:        jsonValue(jsonParsed, "Host"),
+				IPAddress:   ip,
+				PortID:      cast.ToInt(jsonValue(p, "PortID")),
+				Protocol:    portProtocol(jsonValue(p, "Protocol")),
+				State:       jsonValue(p, "State"),
+				Service:     jsonValue(p, "Service.Name"),
+				Product:     jsonValue(p, "Service.Product"),
+				Cpe:         jsonValue(p, "Service.Cpe"),
+				ScanRefer:   r.ScanObj.ID,
+				TargetRefer: r.TargetObj.ID,
+			})
+		}
+	}
+	r.importResult("port", len(objs), database.ImportPorts(objs))
+}
+
+func portProtocol(protocol string) string {
+	if protocol == "" {
+		return "tcp"
+	}
+	return strings.ToLower(protocol)
+}
+
+// ImportJaelesVulnJson import the findings from the jaeles summary file
+func (r *Runner) ImportJaelesVulnJson(src string) {
+	var objs []database.Finding
+	for _, line := range r.readImportFile(src) {
+		jsonParsed, err := gabs.ParseJSON([]byte(line))
+		if err != nil {
+			continue
+		}
+
+		reportPath := resolveImportPath(src, jsonValue(jsonParsed, "OutputFile"))
+		jsonParsed, err = gabs.ParseJSON([]byte(utils.GetFileContent(reportPath)))
+		if err != nil {
+			utils.DebugF("Error parsing the jaeles output: %v", reportPath)
+			continue
+		}
+
+		objs = append(objs, r.newFinding(database.Finding{
+			CheckID:    jsonValue(jsonParsed, "SignID"),
+			Title:      jsonValue(jsonParsed, "SignName"),
+			Severity:   jsonValue(jsonParsed, "Risk"),
+			Confidence: jsonValue(jsonParsed, "Confidence"),
+			MatchedAt:  jsonValue(jsonParsed, "URL"),
+			Evidence:   jsonValue(jsonParsed, "DetectionString"),
+			Request:    jsonValue(jsonParsed, "Req"),
+			Response:   jsonValue(jsonParsed, "Res"),
+			Source:     "jaeles",
+		}))
+	}
+	r.importFindings(objs)
+}
+
+// ImportNucleiVulnJson import the findings from the nuclei JSONL output
+func (r *Runner) ImportNucleiVulnJson(src string) {
+	var objs []database.Finding
+	for _, line := range r.readImportFile(src) {
+		jsonParsed, err := gabs.ParseJSON([]byte(line))
+		if err != nil {
+			continue
+		}
+
+		// the same template could match multiple times on the same URL with different matchers
+		checkID := jsonValue(jsonParsed, "template-id", "templateID")
+		if matcher := jsonValue(jsonParsed, "matcher-name", "matcher_name"); matcher != "" {
+			checkID = fmt.Sprintf("%s:%s", checkID, matcher)
+		}
+
+		evidence := jsonList(jsonParsed, "extracted-results")
+		if evidence == "" {
+			evidence = jsonValue(jsonParsed, "matcher-name", "matched")
+		}
+
+		obj := database.Finding{
+			CheckID:    checkID,
+			Title:      jsonValue(jsonParsed, "info.name"),
+			Severity:   jsonValue(jsonParsed, "info.severity"),
+			Confidence: "Tentative",
+			MatchedAt:  jsonValue(jsonParsed, "matched-at", "matched", "host"),
+			Evidence:   evidence,
+			Request:    jsonValue(jsonParsed, "request"),
+			Response:   jsonValue(jsonParsed, "response"),
+			Source:     "nuclei",
+		}
+		if obj.CheckID == "" {
+			continue
+		}
+		objs = append(objs, r.newFinding(obj))
+	}
+	r.importFindings(objs)
+}
+
+// ImportFindingJson import the findings of the custom modules in JSONL format
+// {"check_id": "", "title": "", "severity": "", "host": "", "matched_at": "", "evidence": "", "source": ""}
+func (r *Runner) ImportFindingJson(src string) {
+	var objs []database.Finding
+	for _, line := range r.readImportFile(src) {
+		jsonParsed, err := gabs.ParseJSON([]byte(line))
+		if err != nil {
+			continue
+		}
+
+		obj := database.Finding{
+			CheckID:    jsonValue(jsonParsed, "check_id", "id"),
+			Title:      jsonValue(jsonParsed, "title", "name"),
+			Severity:   jsonValue(jsonParsed, "severity"),
+			Confidence: jsonValue(jsonParsed, "confidence"),
+			Host:       jsonValue(jsonParsed, "host"),
+			MatchedAt:  jsonValue(jsonParsed, "matched_at", "url", "host"),
+			Evidence:   jsonValue(jsonParsed, "evidence"),
+			Source:     jsonValue(jsonParsed, "source"),
+		}
+		if obj.CheckID == "" || obj.MatchedAt == "" {
+			continue
+		}
+		if obj.Source == "" {
+			obj.Source = r.CurrentModule
+		}
+		objs = append(objs, r.newFinding(obj))
+	}
+	r.importFindings(objs)
+}
+
+func (r *Runner) newFinding(obj database.Finding) database.Finding {
+	obj.ScanRefer = r.ScanObj.ID
+	obj.TargetRefer = r.TargetObj.ID
+	return obj
+}
+
+// importFindings store the findings, refresh the counters of the target and notify the new findings
+func (r *Runner) importFindings(objs []database.Finding) {
+	known := make(map[string]bool)
+	if existing, err := database.GetFindings(r.TargetObj.ID); err == nil {
+		for _, finding := range existing {
+			known[finding.Fingerprint] = true
+		}
+	}
+	err := database.ImportFindings(objs)
+	r.importResult("finding", len(objs), err)
+	if err := database.UpdateFindingCounters(&r.TargetObj); err != nil {
+		utils.ErrorF("[DB] Error counting the findings: %v", err)
+	}
+	if err != nil {
+		return
+	}
+	for _, msg := range r.findingMessages(objs, known) {
+		if err := execution.Notify(r.Opt, msg); err != nil {
+			utils.DebugF("Error sending finding notification: %v", err)
+		}
+	}
+}
+
+// the maximum number of findings listed in the notification
+const findingNotiLimit = 20
+
+// findingMessages one message per severity of the findings which are not known yet
+// the severity is set so the notification rules could filter them with min_severity
+func (r *Runner) findingMessages(objs []database.Finding, known map[string]bool) []execution.Message {
+	bySeverity := make(map[string][]string)
+	for _, obj := range objs {
+		fingerprint := obj.Fingerprint
+		if fingerprint == "" {
+			fingerprint = database.GenFingerprint(obj.CheckID, obj.MatchedAt)
+		}
+		if known[fingerprint] {
+			continue
+		}
+		known[fingerprint] = true
+		severity := database.NormalizeSeverity(obj.Severity)
+		bySeverity[severity] = append(bySeverity[severity], fmt.Sprintf("[%v] %v", obj.CheckID, obj.MatchedAt))
+	}
+
+	var severities []string
+	for severity := range bySeverity {
+		severities = append(severities, severity)
+	}
+	sort.Slice(severities, func(i, j int) bool {
+		return database.SeverityRank(severities[i]) > database.SeverityRank(severities[j])
+	})
+
+	var messages []execution.Message
+	for _, severity := range severities {
+		lines := bySeverity[severity]
+		title := fmt.Sprintf("%v new %v findings on %v", len(lines), severity, r.TargetObj.Workspace)
+		if len(lines) > findingNotiLimit {
+			lines = append(lines[:findingNotiLimit], fmt.Sprintf("... and %v more", len(lines)-findingNotiLimit))
+		}
+		msg := execution.NewMessage("finding", title, strings.Join(lines, "\n"), r.Opt)
+		msg.Class = execution.ClassReport
+		msg.Severity = severity
+		msg.Workspace = r.TargetObj.Workspace
+		msg.Module = r.CurrentModule
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+// ImportCred import leaked credentials in JSON format
+func (r *Runner) ImportCred(src string) {
+	var objs []database.Credential
+	for _, line := range r.readImportFile(src) {
+		jsonParsed, err := gabs.ParseJSON([]byte(line))
+		if err != nil {
+			continue
+		}
+
+		obj := database.Credential{
+			CredID:         jsonValue(jsonParsed, "id"),
+			Email:          jsonValue(jsonParsed, "email"),
+			Username:       jsonValue(jsonParsed, "username"),
+			Password:       jsonValue(jsonParsed, "password"),
+			HashedPassword: jsonValue(jsonParsed, "hashed_password"),
+			Name:           jsonValue(jsonParsed, "name"),
+			Phone:          jsonValue(jsonParsed, "phone"),
+			IPAddress:      jsonValue(jsonParsed, "ip_address"),
+			Source:         jsonValue(jsonParsed, "database_name", "source"),
+			ScanRefer:      r.ScanObj.ID,
+			TargetRefer:    r.TargetObj.ID,
+		}
+		obj.CredChecksum = utils.GenHash(fmt.Sprintf("%s-%s-%s-%s-%s", obj.Source, obj.CredID, obj.Email, obj.Username, obj.HashedPassword+obj.Password))
+		objs = append(objs, obj)
+	}
+	r.importResult("credential", len(objs), database.ImportCredentials(objs))
+}
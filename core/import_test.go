@@ -0,0 +1,97 @@
+package core
+
+import (
+	"path"
+	"testing"
+
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+func initImportRunner(t *testing.T) Runner {
+	var opt libs.Options
+	opt.Server.DBPath = path.Join(t.TempDir(), "sqlite.db")
+	if _, err := database.InitDB(opt); err != nil {
+		t.Fatalf("Error InitDB: %v", err)
+	}
+	t.Cleanup(database.CloseDB)
+
+	var r Runner
+	r.Opt = opt
+	r.TargetObj = database.Target{InputName: "example.com", Workspace: "example.com"}
+	database.SaveTarget(&r.TargetObj)
+	r.ScanObj = database.Scan{InputName: "example.com", Target: r.TargetObj}
+	database.SaveScan(&r.ScanObj)
+	return r
+}
+
+func TestImportAssets(t *testing.T) {
+	r := initImportRunner(t)
+	dir := t.TempDir()
+
+	subdomains := path.Join(dir, "subdomains.txt")
+	utils.WriteToFile(subdomains, "a.example.com\nb.example.com\n\na.example.com")
+	r.ImportSubdomain(subdomains)
+
+	httpx := path.Join(dir, "http.json")
+	utils.WriteToFile(httpx, `{"url":"https://c.example.com","title":"Login","status_code":200,"content_length":12,"tech":["Nginx","PHP"]}`)
+	r.ImportHTTPJson(httpx)
+
+	assets, err := database.GetAssets(r.TargetObj.ID)
+	if err != nil || len(assets) != 3 {
+		t.Fatalf("Error importing assets: %v", assets)
+	}
+	if assets[2].AssetValue != "c.example.com" || assets[2].Technology != "Nginx,PHP" || !assets[2].IsAlive {
+		t.Errorf("Error importing HTTP asset: %v", assets[2])
+	}
+
+	nuclei := path.Join(dir, "nuclei.json")
+	line := `{"template-id":"git-config","info":{"name":"Git Config","severity":"medium"},"host":"https://c.example.com","matched-at":"https://c.example.com/.git/config"}`
+	utils.WriteToFile(nuclei, line+"\n"+line)
+	r.ImportNucleiVulnJson(nuclei)
+	r.ImportNucleiVulnJson(nuclei)
+
+	findings, _ := database.GetFindings(r.TargetObj.ID)
+	if len(findings) != 1 {
+		t.Fatalf("Error importing findings: %v", len(findings))
+	}
+	if findings[0].Host != "c.example.com" || findings[0].Severity != "medium" || findings[0].ScanRefer != r.ScanObj.ID {
+		t.Errorf("Error parsing nuclei output: %v", findings[0])
+	}
+	if r.TargetObj.TotalVulnerability != 1 || r.TargetObj.TotalMedium != 1 {
+		t.Errorf("Error counting findings: %v", r.TargetObj.TotalVulnerability)
+	}
+
+	ports := path.Join(dir, "ports.json")
+	utils.WriteToFile(ports, `{"IPAddress":"1.2.3.4","Ports":[{"Protocol":"tcp","PortID":"443","State":"open","Service":{"Name":"https","Product":"nginx"}}]}`)
+	r.ImportPortJson(ports)
+
+	var port database.Port
+	database.DB.Where("target_refer = ?", r.TargetObj.ID).First(&port)
+	if port.PortID != 443 || port.Product != "nginx" {
+		t.Errorf("Error importing ports: %v", port)
+	}
+}
+
+func TestFindingMessages(t *testing.T) {
+	var r Runner
+	r.TargetObj = database.Target{Workspace: "example.com"}
+	known := map[string]bool{database.GenFingerprint("old", "https://example.com"): true}
+	messages := r.findingMessages([]database.Finding{
+		{CheckID: "old", MatchedAt: "https://example.com", Severity: "critical"},
+		{CheckID: "xss", MatchedAt: "https://example.com/a", Severity: "medium"},
+		{CheckID: "rce", MatchedAt: "https://example.com/b", Severity: "CRITICAL"},
+		{CheckID: "sqli", MatchedAt: "https://example.com/c", Severity: "critical"},
+	}, known)
+
+	if len(messages) != 2 {
+		t.Fatalf("Error findingMessages got %v messages: %+v", len(messages), messages)
+	}
+	if messages[0].Severity != database.SeverityCritical || messages[1].Severity != database.SeverityMedium {
+		t.Errorf("Error findingMessages severities: %v, %v", messages[0].Severity, messages[1].Severity)
+	}
+	if messages[0].Title != "2 new critical findings on example.com" || messages[0].Event != "finding" {
+		t.Errorf("Error findingMessages message: %+v", messages[0])
+	}
+}
@@ -5,18 +5,12 @@ import (
 	"text/template"
 
 	"fmt"
-	"os"
 	"regexp"
 	"strings"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cast"
-	"github.com/whoamikiddie/vulnx/libs"
 	"github.com/whoamikiddie/vulnx/utils"
-
-	"github.com/gomarkdown/markdown"
-	"github.com/gomarkdown/markdown/html"
-	"github.com/gomarkdown/markdown/parser"
 )
 
 func (r *Runner) GenMarkdownReport(markdownFile string, outputHTML string) {
@@ -35,6 +29,9 @@ func (r *Runner) GenMarkdownReport(markdownFile string, outputHTML string) {
 
 	// replace all the <content /> tag
 	mdContent = r.ResolveContentTag(mdContent)
+
+	// replace the <table />, <count />, <findings />, <screenshots /> and <bar /> tags
+	mdContent = r.ResolveExtraTags(mdContent)
 	// fmt.Println("mdContent", mdContent)
 
 	// generating the markdown file first
@@ -44,7 +41,7 @@ func (r *Runner) GenMarkdownReport(markdownFile string, outputHTML string) {
 	utils.InforF("Generate markdown report: %v", outputMD)
 	utils.InforF("Generate HTML report: %v", outputHTML)
 	// finally convert to HTML
-	MarkDownToHTML(r.Opt, r.Input, outputMD, outputHTML)
+	MarkDownToHTML(r.Opt, r.Input, r.ScanObj.TaskName, outputMD, outputHTML)
 }
 
 func (r *Runner) ResolveScanInfoTag(rawMarkdown string) string {
@@ -67,7 +64,7 @@ func (r *Runner) ResolveScanInfoTag(rawMarkdown string) string {
 			status = "running"
 		}
 
-		statistics := fmt.Sprintf("`assets/%v`, `dns/%v`, `vulnerability/%v`, ", r.TargetObj.TotalAssets, r.TargetObj.TotalDns, r.TargetObj.TotalVulnerability)
+		statistics := r.ScanStatistics()
 		replacements := map[string]string{
 			":target":      r.ScanObj.InputName,
 			":runningTime": cast.ToString(int(r.RunningTime)/3600) + " hours",
@@ -151,6 +148,17 @@ func (r *Runner) ResolveReportsTag(rawMarkDown string) string {
 			// add the full content if report file is a text file
 			mdContent += fmt.Sprintf("### %s -- *%s* \n\n", report.Module, report.ReportName)
 
+			switch strings.ToLower(path.Ext(report.ReportPath)) {
+			case ".csv":
+				mdContent += CSVToMarkdown(report.ReportPath, "", 0)
+				mdContent += "\n***\n\n"
+				continue
+			case ".png", ".jpg", ".jpeg", ".gif":
+				mdContent += fmt.Sprintf("![%s](%s)\n", report.ReportName, report.ReportPath)
+				mdContent += "\n***\n\n"
+				continue
+			}
+
 			fileContent := utils.GetFileContent(report.ReportPath)
 			if len(fileContent) > r.Opt.MDCodeBlockLimit {
 				mdContent += extendTag(fileContent)
@@ -167,92 +175,3 @@ func (r *Runner) ResolveReportsTag(rawMarkDown string) string {
 
 	return finalMarkdown
 }
-
-func MarkDownToHTML(options libs.Options, target string, markdownFile string, outputFile string) error {
-	css := path.Join(options.Env.DataFolder, "markdown/style.css")
-
-	var input []byte
-	var err error
-
-	if input, err = os.ReadFile(markdownFile); err != nil {
-		utils.ErrorF("Error reading %s: %v", markdownFile, err)
-		return err
-	}
-
-	// set up options
-	var extensions = parser.NoIntraEmphasis |
-		parser.Tables |
-		parser.FencedCode |
-		parser.Autolink |
-		parser.Strikethrough |
-		parser.SpaceHeadings
-
-	var renderer markdown.Renderer
-	// render the data into HTML
-	var htmlFlags html.Flags
-
-	htmlFlags |= html.Smartypants
-	htmlFlags |= html.UseXHTML
-	htmlFlags |= html.CompletePage
-	htmlFlags |= html.SmartypantsLatexDashes
-	htmlFlags |= html.SmartypantsFractions
-
-	params := html.RendererOptions{
-		Flags: htmlFlags,
-		CSS:   css,
-	}
-	renderer = html.NewRenderer(params)
-
-	// parse and render
-	var output []byte
-	parser := parser.NewWithExtensions(extensions)
-	// @NOTE: beware of XSS as I assume you will trust the markdown content you generate
-	output = markdown.ToHTML(input, parser, renderer)
-	// html := bluemonday.UGCPolicy().SanitizeBytes(output) // skip the sanitization as we prefer more beautiful output
-
-	cssContent, err := os.ReadFile(css)
-	if err != nil {
-		return err
-	}
-
-	finalHTML := fmt.Sprintf(`
-<html>
-	<head>
-	<meta charset="UTF-8">
-	<meta name="viewport" content="width=device-width">
-	<link rel="icon" type="image/svg+xml" href="https://www.osmedeus.org/favicon.png">
-	<meta name="generator" content="Generated by https://github.com/gomarkdown/markdown">
-	<meta name="description" content="A Workflow Engine for Offensive Security">
-	<title>Osmedeus Executive Summary - %s</title>
-	<meta property="og:title" content="Osmedeus Next Generation">
-	<meta property="og:type" content="website">
-	<meta property="og:url" content="https://www.osmedeus.org">
-	<meta property="og:image" content="https://raw.githubusercontent.com/osmedeus/assets/main/banner.png">
-	<meta property="og:description" content="A Workflow Engine for Offensive Security">
-	<meta name="twitter:card" content="">
-	<meta name="twitter:site" content="@OsmedeusEngine">
-	<meta name="twitter:creator" content="@OsmedeusEngine">
-	<style>%s</style>
-	</head>
-
-<body>
-%s
-</body>
-</html>
-	`, target, string(cssContent), string(output))
-
-	// output the result
-	var out *os.File
-	if out, err = os.Create(outputFile); err != nil {
-		utils.ErrorF("Error creating %s: %v", outputFile, err)
-		return err
-	}
-	defer out.Close()
-
-	if _, err = out.WriteString(finalHTML); err != nil {
-		utils.ErrorF("Error writing output: %v", err)
-		return err
-	}
-
-	return nil
-}
@@ -0,0 +1,292 @@
+package core
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cast"
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// the extra tags of the markdown template, all of them are self-closing so the real HTML tags are not touched
+var (
+	tableTagRegex       = regexp.MustCompile(`<table\s+[^>]*src="[^"]*"[^>]*/>`)
+	countTagRegex       = regexp.MustCompile(`<count\s+[^>]*/>`)
+	findingsTagRegex    = regexp.MustCompile(`<findings(\s+[^>]*)?/>`)
+	screenshotsTagRegex = regexp.MustCompile(`<screenshots\s+[^>]*/>`)
+	barTagRegex         = regexp.MustCompile(`<bar\s+[^>]*/>`)
+	attributeRegex      = regexp.MustCompile(`(\w+)="([^"]*)"`)
+)
+
+// ResolveExtraTags replace the <table />, <count />, <findings />, <screenshots /> and <bar /> tags
+func (r *Runner) ResolveExtraTags(rawMarkdown string) string {
+	resolvers := []struct {
+		re      *regexp.Regexp
+		resolve func(attrs map[string]string) string
+	}{
+		{tableTagRegex, func(attrs map[string]string) string {
+			return CSVToMarkdown(attrs["src"], attrs["sort"], cast.ToInt(attrs["limit"]))
+		}},
+		{countTagRegex, func(attrs map[string]string) string {
+			return cast.ToString(utils.FileLength(attrs["src"]))
+		}},
+		{findingsTagRegex, func(attrs map[string]string) string {
+			return r.FindingsToMarkdown(attrs["severity"], cast.ToInt(attrs["limit"]))
+		}},
+		{screenshotsTagRegex, func(attrs map[string]string) string {
+			return ScreenshotGallery(attrs["dir"], cast.ToInt(attrs["limit"]))
+		}},
+		{barTagRegex, func(attrs map[string]string) string {
+			return BarChartSVG(attrs["src"], attrs["title"], cast.ToInt(attrs["limit"]))
+		}},
+	}
+
+	for _, resolver := range resolvers {
+		rawMarkdown = resolver.re.ReplaceAllStringFunc(rawMarkdown, func(tag string) string {
+			utils.DebugF("Replace tag: %v", color.GreenString(tag))
+			return resolver.resolve(tagAttributes(tag))
+		})
+	}
+	return rawMarkdown
+}
+
+// tagAttributes parse the attributes of the tag into a map
+func tagAttributes(tag string) map[string]string {
+	attrs := make(map[string]string)
+	for _, match := range attributeRegex.FindAllStringSubmatch(tag, -1) {
+		attrs[strings.ToLower(match[1])] = match[2]
+	}
+	return attrs
+}
+
+// ScanCounter the total of a kind of asset or finding of the scan
+type ScanCounter struct {
+	Name  string
+	Total int
+}
+
+// ScanCounters the counters of the target
+// the counters of the asset inventory are preferred over the ones set by the workflow
+func (r *Runner) ScanCounters() []ScanCounter {
+	target := r.TargetObj
+	counters := []ScanCounter{
+		{"assets", target.TotalAssets},
+		{"dns", target.TotalDns},
+		{"ports", 0},
+		{"http", 0},
+		{"tech", target.TotalTech},
+		{"screenshot", target.TotalScreenShot},
+		{"directory", target.TotalDirb},
+		{"link", target.TotalLink},
+		{"archive", target.TotalArchive},
+		{"ip-range", target.TotalIPRange},
+		{"cloud", target.TotalCloud},
+		{"credential", target.TotalCred},
+		{"vulnerability", target.TotalVulnerability},
+		{"critical", target.TotalCritical},
+		{"high", target.TotalHigh},
+		{"medium", target.TotalMedium},
+		{"low", target.TotalLow},
+		{"info", target.TotalInfo},
+	}
+
+	if !r.Opt.NoDB && database.DB != nil && target.ID != 0 {
+		inventory, err := database.GetAssetCounters(target.ID)
+		if err != nil {
+			utils.ErrorF("Error getting the asset counters: %v", err)
+		}
+		fromInventory := map[string]string{
+			"assets":     "hosts",
+			"dns":        "dns",
+			"ports":      "ports",
+			"http":       "http",
+			"credential": "credentials",
+		}
+		for i, counter := range counters {
+			if key, ok := fromInventory[counter.Name]; ok && inventory[key] > 0 {
+				counters[i].Total = inventory[key]
+			}
+		}
+	}
+	return counters
+}
+
+// ScanStatistics the statistics line of the scan info
+func (r *Runner) ScanStatistics() string {
+	var statistics []string
+	for _, counter := range r.ScanCounters() {
+		if counter.Total > 0 {
+			statistics = append(statistics, fmt.Sprintf("`%s/%v`", counter.Name, counter.Total))
+		}
+	}
+	if len(statistics) == 0 {
+		return "N/A"
+	}
+	return strings.Join(statistics, " ")
+}
+
+// FindingsToMarkdown render the findings of the target at or above the severity as a markdown table
+func (r *Runner) FindingsToMarkdown(severity string, limit int) string {
+	if r.Opt.NoDB || database.DB == nil {
+		return "_No findings available without the database_\n"
+	}
+	findings, err := database.GetFindings(r.TargetObj.ID)
+	if err != nil {
+		utils.ErrorF("Error getting the findings: %v", err)
+		return ""
+	}
+
+	minRank := 0
+	if severity != "" {
+		minRank = database.SeverityRank(severity)
+	}
+	var selected []database.Finding
+	for _, finding := range findings {
+		if database.SeverityRank(finding.Severity) >= minRank {
+			selected = append(selected, finding)
+		}
+	}
+	if len(selected) == 0 {
+		return "_No findings_\n"
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		return database.SeverityRank(selected[i].Severity) > database.SeverityRank(selected[j].Severity)
+	})
+	if limit > 0 && len(selected) > limit {
+		selected = selected[:limit]
+	}
+
+	escape := func(cell string) string {
+		return strings.ReplaceAll(strings.ReplaceAll(strings.TrimSpace(cell), "|", `\|`), "\n", " ")
+	}
+	var table strings.Builder
+	table.WriteString("| Severity | Check | Title | Location | Status |\n")
+	table.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, finding := range selected {
+		table.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n", finding.Severity, escape(finding.CheckID),
+			escape(finding.Title), escape(finding.MatchedAt), finding.Status))
+	}
+	return table.String()
+}
+
+// ScreenshotGallery render the images in the folder as an HTML gallery, the images are inlined later on
+func ScreenshotGallery(dir string, limit int) string {
+	dir = utils.NormalizePath(dir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		utils.ErrorF("Error reading screenshot folder %v: %v", dir, err)
+		return ""
+	}
+
+	var gallery strings.Builder
+	gallery.WriteString("<div class=\"gallery\">\n")
+	count := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".png", ".jpg", ".jpeg", ".gif", ".webp":
+		default:
+			continue
+		}
+		if limit > 0 && count >= limit {
+			break
+		}
+		count++
+		name := html.EscapeString(entry.Name())
+		gallery.WriteString(fmt.Sprintf("<figure><img src=\"%s\" alt=\"%s\"/><figcaption>%s</figcaption></figure>\n",
+			html.EscapeString(path.Join(dir, entry.Name())), name, name))
+	}
+	gallery.WriteString("</div>\n")
+
+	if count == 0 {
+		return "_No screenshots_\n"
+	}
+	return gallery.String()
+}
+
+// BarChartSVG render an inline SVG horizontal bar chart
+// each line of the file is either "label,value" or a label which is counted by its occurrences
+func BarChartSVG(src string, title string, limit int) string {
+	if limit <= 0 {
+		limit = 20
+	}
+	var labels []string
+	values := make(map[string]float64)
+	for _, line := range utils.ReadingLines(src) {
+		label, value := line, 1.0
+		if index := strings.LastIndex(line, ","); index > 0 {
+			if number, err := cast.ToFloat64E(strings.TrimSpace(line[index+1:])); err == nil {
+				label, value = line[:index], number
+			}
+		}
+		label = strings.TrimSpace(label)
+		if label == "" {
+			continue
+		}
+		if _, ok := values[label]; !ok {
+			labels = append(labels, label)
+		}
+		values[label] += value
+	}
+	if len(labels) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(labels, func(i, j int) bool {
+		return values[labels[i]] > values[labels[j]]
+	})
+	if len(labels) > limit {
+		labels = labels[:limit]
+	}
+	maxValue := values[labels[0]]
+	if maxValue <= 0 {
+		maxValue = 1
+	}
+
+	const (
+		labelWidth = 240
+		barWidth   = 480
+		rowHeight  = 24
+		top        = 30
+	)
+	height := top + len(labels)*rowHeight + 10
+	var svg strings.Builder
+	svg.WriteString(fmt.Sprintf("<svg class=\"bar-chart\" xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		labelWidth+barWidth+80, height, labelWidth+barWidth+80, height))
+	if title == "" {
+		title = path.Base(src)
+	}
+	svg.WriteString(fmt.Sprintf("<text x=\"0\" y=\"18\" font-weight=\"bold\">%s</text>\n", html.EscapeString(title)))
+	for i, label := range labels {
+		y := top + i*rowHeight
+		width := int(values[label] / maxValue * barWidth)
+		if width < 1 {
+			width = 1
+		}
+		svg.WriteString(fmt.Sprintf("<text x=\"%d\" y=\"%d\" text-anchor=\"end\" font-size=\"12\">%s</text>\n",
+			labelWidth-8, y+15, html.EscapeString(shortLabel(label, 36))))
+		svg.WriteString(fmt.Sprintf("<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"#0969da\"/>\n",
+			labelWidth, y+2, width, rowHeight-6))
+		svg.WriteString(fmt.Sprintf("<text x=\"%d\" y=\"%d\" font-size=\"12\">%v</text>\n",
+			labelWidth+width+6, y+15, values[label]))
+	}
+	svg.WriteString("</svg>\n")
+	return svg.String()
+}
+
+func shortLabel(label string, size int) string {
+	if len(label) <= size {
+		return label
+	}
+	return label[:size-3] + "..."
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+func TestResolveExtraTags(t *testing.T) {
+	r := initImportRunner(t)
+	database.ImportFindings([]database.Finding{
+		{CheckID: "git-config", Title: "Git Config", MatchedAt: "https://example.com/.git/config", Severity: "medium", TargetRefer: r.TargetObj.ID},
+		{CheckID: "CVE-2021-44228", Title: "Log4Shell", MatchedAt: "https://api.example.com/login", Severity: "critical", TargetRefer: r.TargetObj.ID},
+	})
+	dir := t.TempDir()
+	utils.WriteToFile(path.Join(dir, "hosts.csv"), "host,port\nb.example.com,443\na.example.com,80\n")
+	utils.WriteToFile(path.Join(dir, "tech.txt"), "nginx\nphp\nnginx\n")
+	os.WriteFile(path.Join(dir, "shot.png"), []byte("\x89PNG\r\n\x1a\n"), 0644)
+
+	md := strings.Join([]string{
+		`<table src="` + path.Join(dir, "hosts.csv") + `" sort="port" limit="1"/>`,
+		`Total: <count src="` + path.Join(dir, "tech.txt") + `"/>`,
+		`<findings severity="high"/>`,
+		`<screenshots dir="` + dir + `"/>`,
+		`<bar src="` + path.Join(dir, "tech.txt") + `"/>`,
+		`<table><tr><td>untouched</td></tr></table>`,
+	}, "\n")
+	content := r.ResolveExtraTags(md)
+
+	if !strings.Contains(content, "| a.example.com | 80 |") || strings.Contains(content, "b.example.com") {
+		t.Errorf("Error resolving table tag: %v", content)
+	}
+	if !strings.Contains(content, "Total: 3") {
+		t.Errorf("Error resolving count tag: %v", content)
+	}
+	if !strings.Contains(content, "Log4Shell") || strings.Contains(content, "Git Config") {
+		t.Errorf("Error resolving findings tag: %v", content)
+	}
+	if !strings.Contains(content, `<div class="gallery">`) || !strings.Contains(content, "shot.png") {
+		t.Errorf("Error resolving screenshots tag: %v", content)
+	}
+	if !strings.Contains(content, "<svg") || !strings.Contains(content, ">nginx</text>") {
+		t.Errorf("Error resolving bar tag: %v", content)
+	}
+	if !strings.Contains(content, "<td>untouched</td>") {
+		t.Errorf("Error the HTML table should not be touched: %v", content)
+	}
+
+	database.UpdateFindingCounters(&r.TargetObj)
+	statistics := r.ScanStatistics()
+	if !strings.Contains(statistics, "`vulnerability/2`") || !strings.Contains(statistics, "`critical/1`") {
+		t.Errorf("Error ScanStatistics: %v", statistics)
+	}
+}
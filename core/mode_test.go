@@ -2,9 +2,11 @@ package core
 
 import (
 	"fmt"
+	"path"
 	"testing"
 
 	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
 )
 
 func TestListMode(t *testing.T) {
@@ -22,3 +24,43 @@ func TestListMode(t *testing.T) {
 		t.Errorf("Error selectedMode")
 	}
 }
+
+func TestValidateRoutine(t *testing.T) {
+	var options libs.Options
+	options.Env.WorkFlowsFolder = t.TempDir()
+	utils.WriteToFile(path.Join(options.Env.WorkFlowsFolder, "general.yaml"), "name: general")
+	utils.MakeDir(path.Join(options.Env.WorkFlowsFolder, "default-modules"))
+	utils.WriteToFile(path.Join(options.Env.WorkFlowsFolder, "default-modules", "probing.yaml"), "name: probing")
+
+	if err := ValidateRoutine("general", nil, options); err != nil {
+		t.Errorf("Error ValidateRoutine flow: %v", err)
+	}
+	if err := ValidateRoutine("", []string{"probing"}, options); err != nil {
+		t.Errorf("Error ValidateRoutine module: %v", err)
+	}
+	if err := ValidateRoutine("not-exist", nil, options); err == nil {
+		t.Errorf("Error ValidateRoutine should reject the unknown flow")
+	}
+	if err := ValidateRoutine("general", []string{"probing", "not-exist"}, options); err == nil {
+		t.Errorf("Error ValidateRoutine should reject the unknown module")
+	}
+
+	// the uploaded file exists but it's not in the workflow folder
+	uploaded := path.Join(t.TempDir(), "x.yaml")
+	utils.WriteToFile(uploaded, "name: x")
+	paths := []string{
+		uploaded,
+		path.Join(options.Env.WorkFlowsFolder, "general.yaml"),
+		path.Join(options.Env.WorkFlowsFolder, "default-modules", "probing.yaml"),
+		"../" + path.Base(options.Env.WorkFlowsFolder) + "/general",
+		"default-modules/probing",
+	}
+	for _, name := range paths {
+		if err := ValidateRoutine(name, nil, options); err == nil {
+			t.Errorf("Error ValidateRoutine should reject the workflow path: %v", name)
+		}
+		if err := ValidateRoutine("", []string{name}, options); err == nil {
+			t.Errorf("Error ValidateRoutine should reject the plugin path: %v", name)
+		}
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/panjf2000/ants"
 	"github.com/whoamikiddie/vulnx/execution"
 	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/metrics"
 	"github.com/whoamikiddie/vulnx/utils"
 )
 
@@ -33,6 +34,7 @@ func (r *Runner) RunModule(module libs.Module) {
 	r.CurrentModule = module.Name
 	timeStart := time.Now()
 	utils.TSPrintF("The %v module has begun with the objective %v", color.HiGreenString(module.Name), color.HiCyanString(module.Desc))
+	r.Event(ScanEvent{Type: EventModule, Module: module.Name, State: "started", Message: fmt.Sprintf("The %v module has begun with the objective %v", module.Name, module.Desc)})
 
 	// create report record first because I don't want to wait for them to show up in UI until the module done
 	r.DBNewReports(module)
@@ -40,12 +42,20 @@ func (r *Runner) RunModule(module libs.Module) {
 	// pre-run
 	if len(module.PreRun) > 0 && r.Opt.NoPreRun == false {
 		utils.InforF("Running prepare scripts for module %v", color.CyanString(module.Name))
+		r.LogEvent(fmt.Sprintf("Running prepare scripts for module %v", module.Name))
 		r.RunScripts(module.PreRun)
 	}
 
 	utils.InforF("Running steps for module %v", color.CyanString(module.Name))
+	r.LogEvent(fmt.Sprintf("Running steps for module %v", module.Name))
 	// main part
 	err := r.RunSteps(module.Steps)
+	if err == ErrScanCancelled {
+		utils.BadBlockF(fmt.Sprintf("The %v module has been cancelled", color.HiGreenString(module.Name)))
+		r.Event(ScanEvent{Type: EventModule, Module: module.Name, State: StateCancelled, Message: fmt.Sprintf("The %v module has been cancelled", module.Name)})
+		r.DBUpdateScan()
+		return
+	}
 	if err != nil {
 		utils.BadBlockF(fmt.Sprintf("got an exit call"))
 	}
@@ -53,6 +63,7 @@ func (r *Runner) RunModule(module libs.Module) {
 	// post-run
 	if len(module.PostRun) > 0 && r.Opt.NoPostRun == false {
 		utils.InforF("Running conclude scripts for module %v", color.CyanString(module.Name))
+		r.LogEvent(fmt.Sprintf("Running conclude scripts for module %v", module.Name))
 		r.RunScripts(module.PostRun)
 	}
 
@@ -62,8 +73,10 @@ func (r *Runner) RunModule(module libs.Module) {
 	// estimate time
 	elapsedTime := time.Since(timeStart).Seconds()
 	utils.TSPrintF("The %v module finished within %v.", color.HiGreenString(module.Name), color.HiMagentaString("%vs", elapsedTime))
+	r.Event(ScanEvent{Type: EventModule, Module: module.Name, State: "finished", Message: fmt.Sprintf("The %v module finished within %vs", module.Name, cast.ToInt(elapsedTime))})
 
 	r.RunningTime += cast.ToInt(elapsedTime)
+	metrics.ModuleDuration.WithLabelValues(r.RoutineName, module.Name).Observe(elapsedTime)
 
 	r.DBUpdateScan()
 }
@@ -125,13 +138,19 @@ func (r *Runner) RunScript(script string) string {
 func (r *Runner) RunSteps(steps []libs.Step) error {
 	var stepOut string
 	for _, step := range steps {
+		if err := r.WaitControl(); err != nil {
+			return err
+		}
 		r.DoneStep += 1
+		stepStart := time.Now()
 
 		if step.Timeout != "" {
 			// timeout should be: 30, 30m, 1h
 			timeout := utils.CalcTimeout(step.Timeout)
 			if timeout != 0 {
 				stepOut, _ = r.RunStepWithTimeout(timeout, step)
+				metrics.StepDuration.WithLabelValues(r.CurrentModule).Observe(time.Since(stepStart).Seconds())
+				r.Event(ScanEvent{Type: EventProgress})
 				if strings.Contains(stepOut, "exit") {
 					return fmt.Errorf("got exit call")
 				}
@@ -140,6 +159,8 @@ func (r *Runner) RunSteps(steps []libs.Step) error {
 		}
 
 		stepOut, _ = r.RunStep(step)
+		metrics.StepDuration.WithLabelValues(r.CurrentModule).Observe(time.Since(stepStart).Seconds())
+		r.Event(ScanEvent{Type: EventProgress})
 		if strings.Contains(stepOut, "exit") {
 			return fmt.Errorf("got an exit call")
 		}
@@ -172,6 +193,7 @@ func (r *Runner) RunStep(step libs.Step) (string, error) {
 	var output string
 	if step.Label != "" {
 		utils.TSPrintF("Initiating step %v", color.HiGreenString(step.Label))
+		r.Event(ScanEvent{Type: EventStep, Step: step.Label, Message: fmt.Sprintf("Initiating step %v", step.Label)})
 	}
 
 	// checking required file
@@ -189,6 +211,7 @@ func (r *Runner) RunStep(step libs.Step) (string, error) {
 
 		// run reverse commands
 		utils.InforF("Condition false, run the reverse commands")
+		r.LogEvent("Condition false, run the reverse commands")
 		if len(step.RCommands) > 0 {
 			r.RunCommands(step.RCommands, step.Std)
 		}
@@ -1,118 +1,255 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/fatih/color"
-	"github.com/fsnotify/fsnotify"
 	jsoniter "github.com/json-iterator/go"
-	"github.com/panjf2000/ants"
-	"github.com/thoas/go-funk"
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/execution"
 	"github.com/whoamikiddie/vulnx/libs"
 	"github.com/whoamikiddie/vulnx/utils"
 )
 
+// QueueWatcher run the jobs of the queue with the concurrency level
 func QueueWatcher(options libs.Options) {
-	queueFile := options.Queue.QueueFile
+	if database.DB == nil {
+		utils.ErrorF("The queue requires the database, please remove the %v flag", color.HiCyanString("--no-db"))
+		return
+	}
+	ImportQueueFile(options)
+	RunJobPool(options, options.Concurrency)
+}
 
-	var wg sync.WaitGroup
-	p, _ := ants.NewPoolWithFunc(options.Concurrency, func(i interface{}) {
-		RunTheScan(i.(string), options)
-		wg.Done()
-	}, ants.WithPreAlloc(true))
-	defer p.Release()
+// RunJobPool claim and run the jobs of the queue in this process, at most concurrency jobs are run at the same time
+// the jobs left running by a previous process on this machine are put back to the queue first
+func RunJobPool(options libs.Options, concurrency int) {
+	if database.DB == nil {
+		return
+	}
+	hostname, _ := os.Hostname()
+	if recovered, err := database.RecoverJobs(hostname, utils.IsProcessAlive); err != nil {
+		utils.ErrorF("Error recovering the running jobs: %v", err)
+	} else if recovered > 0 {
+		utils.InforF("Recovered %v running jobs from the previous process", color.HiMagentaString("%v", recovered))
+	}
 
-	data := utils.ReadingFileUnique(queueFile)
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	interval := time.Duration(options.Queue.PollingTime) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	worker := fmt.Sprintf("%v-%v", hostname, os.Getpid())
+	utils.InforF("Waiting for the jobs in the queue as %v with concurrency %v", color.HiCyanString(worker), color.HiMagentaString("%v", concurrency))
 
-	/* Start the watcher */
+	slots := make(chan struct{}, concurrency)
+	for {
+		// only claim the job when there is a free slot so the others could pick it up
+		slots <- struct{}{}
+		job, err := database.ClaimJob(worker, hostname, os.Getpid(), 0)
+		if err != nil || job == nil {
+			<-slots
+			if err != nil {
+				utils.ErrorF("Error claiming the job from the queue: %v", err)
+			}
+			time.Sleep(interval)
+			continue
+		}
 
-	// Create new watcher.
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		panic(err)
+		go func(job database.Job) {
+			defer func() { <-slots }()
+			processes := utils.NewProcessGroup()
+			stopWatch := watchJob(job.ID, processes)
+			_, jobErr := RunJob(job, options, processes)
+			close(stopWatch)
+			// the cancelled scan should not be retried
+			if errors.Is(jobErr, ErrScanCancelled) {
+				utils.WarnF("Job %v has been cancelled", job.ID)
+				if current, err := database.GetJob(job.ID); err == nil && current.State == database.JobCancelled {
+					return
+				}
+				if _, err := database.CancelJob(job.ID); err != nil {
+					utils.ErrorF("Error updating the job %v: %v", job.ID, err)
+				}
+				return
+			}
+			if jobErr != nil {
+				utils.ErrorF("Job %v failed: %v", job.ID, jobErr)
+			}
+			if err := database.FinishJob(job.ID, jobErr); err != nil {
+				utils.ErrorF("Error updating the job %v: %v", job.ID, err)
+			}
+		}(*job)
 	}
-	defer watcher.Close()
-	utils.InforF("Starting to watch the queue file: %v", color.HiMagentaString(queueFile))
+}
 
-	// Start listening for events.
+// watchJob kill the commands of the job once it's cancelled in the queue, until the channel is closed
+func watchJob(id uint, processes *utils.ProcessGroup) chan struct{} {
+	stop := make(chan struct{})
 	go func() {
+		ticker := time.NewTicker(controlInterval)
+		defer ticker.Stop()
 		for {
 			select {
-			case event, ok := <-watcher.Events:
-				if !ok {
-					return
-				}
-				if event.Op.String() == "WRITE" {
-					utils.DebugF(color.HiMagentaString("modified file: %v -- %v", event.Name, event.Op))
-					target := GetNewLine(queueFile)
-					wg.Add(1)
-					_ = p.Invoke(strings.TrimSpace(target))
-				}
-
-				if event.Op.String() == "REMOVE" {
-					utils.ErrorF("Queue file removed, exiting ...")
-					os.Exit(-1)
-				}
-			case err, ok := <-watcher.Errors:
-				if !ok {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if job, err := database.GetJob(id); err == nil && job.State == database.JobCancelled {
+					utils.WarnF("Job %v has been cancelled in the queue, stopping its commands", id)
+					processes.Kill()
 					return
 				}
-				utils.ErrorF("error: %v", err)
 			}
 		}
 	}()
+	return stop
+}
 
-	err = watcher.Add(queueFile)
-	if err != nil {
-		panic(err)
+// RunJob run the scan of the job in this process and return the workspaces of the scans
+// the raw command of the job is run in the shell instead, the callback of the job is passed in its environment
+// the commands of the job belong to processes, killing it stops the job
+func RunJob(job database.Job, options libs.Options, processes *utils.ProcessGroup) (workspaces []string, err error) {
+	utils.InforF("Picking the job %v from the queue: %v (attempt %v/%v)", color.HiMagentaString("#%v", job.ID),
+		color.CyanString(job.Input), job.Attempts, job.MaxAttempts)
+
+	if job.Command != "" {
+		cmd := strings.ReplaceAll(job.Command, "{{.input}}", job.Input)
+		utils.InforF("Running the command: %v", color.CyanString(cmd))
+		_, err := processes.RunOSCommandEnv(cmd, execution.CallbackEnv(job.CallbackURL, job.CallbackSecret))
+		if processes.IsKilled() {
+			return workspaces, ErrScanCancelled
+		}
+		return workspaces, err
 	}
 
-	// Just to trigger the file events
-	utils.WriteToFile(queueFile, strings.Join(append(data, "\n"), "\n"))
+	opt := JobOptions(job, options)
+	inputs := []string{job.Input}
+	if job.InputAsFile {
+		if !utils.FileExists(job.Input) {
+			return workspaces, fmt.Errorf("input file %v not found", job.Input)
+		}
+		inputs = utils.ReadingFileUnique(job.Input)
+	}
 
-	// Block main goroutine forever.
-	<-make(chan struct{})
+	var errs []string
+	for _, input := range inputs {
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+		if processes.IsKilled() {
+			return workspaces, ErrScanCancelled
+		}
+		runner, err := InitRunner(input, opt)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%v: %v", input, err))
+			continue
+		}
+		runner.Processes = processes
+		runner.Start()
+		if runner.Workspace != "" {
+			workspaces = append(workspaces, runner.Workspace)
+		}
+		if runner.IsCancelled() {
+			return workspaces, ErrScanCancelled
+		}
+		if !runner.ScanObj.IsDone || runner.ScanObj.IsError {
+			errs = append(errs, fmt.Sprintf("%v: the scan did not complete", input))
+		}
+	}
+	if len(errs) > 0 {
+		return workspaces, fmt.Errorf("%v", strings.Join(errs, "; "))
+	}
+	return workspaces, nil
 }
 
-func RunTheScan(target string, options libs.Options) error {
-	if strings.TrimSpace(target) == "" {
-		return fmt.Errorf("target is empty")
+// JobOptions the options of the process with the flow, modules and params of the job
+func JobOptions(job database.Job, options libs.Options) libs.Options {
+	opt := options
+	opt.ScanID = ""
+	opt.Scan.Inputs = []string{job.Input}
+	opt.Scan.Modules = job.Modules
+	opt.Scan.Flow = job.Flow
+	if opt.Scan.Flow == "" && len(job.Modules) == 0 {
+		opt.Scan.Flow = "general"
 	}
-	utils.InforF("Picking the target from the queue: %v", color.CyanString(target))
+	opt.Scan.Params = append(append([]string{}, options.Scan.Params...), job.Params...)
+	opt.Scan.CustomWorkspace = job.Workspace
+	opt.Scan.CallbackURL = job.CallbackURL
+	opt.Scan.CallbackSecret = job.CallbackSecret
+	return opt
+}
 
-	var inputFormat libs.InputFormat
-	if ok := jsoniter.UnmarshalFromString(target, &inputFormat); ok == nil {
-		utils.DebugF("Parsing the input in JSON format: %v", color.CyanString(target))
-		cmd := CommandBuilder(inputFormat)
-		utils.InforF("Running the command: %v", color.CyanString(cmd))
-		utils.RunOSCommand(cmd)
-		return nil
+// JobWorkspaces the workspaces the job would write, inputs are the lines of the targets file if the input is a file
+func JobWorkspaces(job database.Job, inputs []string, options libs.Options) []string {
+	if !job.InputAsFile {
+		inputs = []string{job.Input}
 	}
-
-	runner, err := InitRunner(target, options)
-	if err != nil {
-		utils.ErrorF("Error init runner with: %s", target)
-		return err
+	opt := JobOptions(job, options)
+	var workspaces []string
+	for _, input := range inputs {
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+		workspaces = append(workspaces, ParseInput(input, opt)["Workspace"])
 	}
-	runner.Start()
-
-	return nil
+	return workspaces
 }
 
-func GetNewLine(queueFile string) string {
-	data := utils.ReadingLines(queueFile)
-	if len(data) == 0 {
-		return ""
+// NewJob convert the queue input to the job, the extra flags could only be passed to a command
+func NewJob(inputFormat libs.InputFormat) database.Job {
+	job := database.Job{
+		Input:          strings.TrimSpace(inputFormat.Input),
+		InputAsFile:    inputFormat.InputAsFile,
+		Flow:           inputFormat.Flow,
+		Modules:        inputFormat.Modules,
+		Params:         inputFormat.Params,
+		Workspace:      inputFormat.Workspaces,
+		Command:        inputFormat.Command,
+		CallbackURL:    inputFormat.CallbackURL,
+		CallbackSecret: inputFormat.CallbackSecret,
 	}
+	if job.Command == "" && strings.TrimSpace(inputFormat.Extra) != "" {
+		job.Command = CommandBuilder(inputFormat)
+	}
+	return job
+}
 
-	target := data[0]
-	data = funk.DropString(data, 1)
-	utils.DebugF("Getting the target from the queue file: %v -- %v", queueFile, color.CyanString(target))
-	utils.WriteToFile(queueFile, strings.Join(data, "\n"))
-	return target
+// ImportQueueFile move the lines of the legacy queue file to the queue, the file is renamed after that
+func ImportQueueFile(options libs.Options) {
+	queueFile := utils.NormalizePath(options.Queue.QueueFile)
+	if queueFile == "" || !utils.FileExists(queueFile) {
+		return
+	}
+	lines := utils.ReadingFileUnique(queueFile)
+	imported := 0
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		inputFormat := libs.InputFormat{Input: line, Flow: options.Scan.Flow}
+		if err := jsoniter.UnmarshalFromString(line, &inputFormat); err != nil {
+			inputFormat = libs.InputFormat{Input: line, Flow: options.Scan.Flow}
+		}
+		job := NewJob(inputFormat)
+		if _, err := database.EnqueueJob(&job); err != nil {
+			utils.ErrorF("Error adding %v to the queue: %v", line, err)
+			continue
+		}
+		imported++
+	}
+	if err := os.Rename(queueFile, queueFile+".imported"); err != nil {
+		utils.ErrorF("Error renaming the queue file: %v", err)
+	}
+	utils.InforF("Imported %v inputs from the queue file %v", color.HiMagentaString("%v", imported), color.HiCyanString(queueFile))
 }
 
 func CommandBuilder(inputFormat libs.InputFormat) (command string) {
@@ -122,7 +259,7 @@ func CommandBuilder(inputFormat libs.InputFormat) (command string) {
 			inputFormat.Command = fmt.Sprintf("%v scan -T %v", libs.BINARY, inputFormat.Input)
 		}
 
-		if inputFormat.Flow == "" {
+		if inputFormat.Flow != "" {
 			inputFormat.Command += " -f " + inputFormat.Flow
 		}
 
@@ -144,5 +281,5 @@ func CommandBuilder(inputFormat libs.InputFormat) (command string) {
 
 	// formatting the command if there is any input in it
 	inputFormat.Command = strings.ReplaceAll(inputFormat.Command, "{{.input}}", inputFormat.Input)
-	return command
+	return strings.TrimSpace(inputFormat.Command)
 }
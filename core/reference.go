@@ -33,6 +33,9 @@ const (
 	TeleMessWrap   = "TeleMessWrap"
 	TeleMessByFile = "TeleMessByFile"
 	TeleSendFile   = "TeleSendFile"
+	// noti for the notifiers routed by the message class
+	SendNoti     = "SendNoti"
+	SendNotiFile = "SendNotiFile"
 )
 
 const (
@@ -77,6 +80,20 @@ const (
 	CreateReport       = "CreateReport"
 )
 
+const (
+	ImportSubdomain      = "ImportSubdomain"
+	ImportDns            = "ImportDns"
+	ImportTech           = "ImportTech"
+	ImportHTTPJson       = "ImportHTTPJson"
+	ImportScreenShotJson = "ImportScreenShotJson"
+	ImportDirectoryJson  = "ImportDirectoryJson"
+	ImportPortJson       = "ImportPortJson"
+	ImportJaelesVulnJson = "ImportJaelesVulnJson"
+	ImportNucleiVulnJson = "ImportNucleiVulnJson"
+	ImportFindingJson    = "ImportFindingJson"
+	ImportCred           = "ImportCred"
+)
+
 const (
 	RRSync      = "RRSync"
 	Clone       = "Clone"
@@ -99,6 +116,7 @@ const (
 	DownloadFromS3    = "DownloadFromS3"
 	DownloadFile      = "DownloadFile"
 	GenMarkdownReport = "GenMarkdownReport"
+	ExportFindings    = "ExportFindings"
 )
 
 const (
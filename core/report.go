@@ -1,16 +1,20 @@
 package core
 
 import (
+	"encoding/csv"
 	"fmt"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/Jeffail/gabs/v2"
 	"github.com/fatih/color"
+	jsoniter "github.com/json-iterator/go"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cast"
+	"github.com/whoamikiddie/vulnx/database"
 	"github.com/whoamikiddie/vulnx/libs"
 	"github.com/whoamikiddie/vulnx/utils"
 )
@@ -213,3 +217,108 @@ func processReport(options libs.Options, reportPath string) string {
 
 	return reportPath
 }
+
+// ListChanges show the assets appeared or vanished in the workspace since the given time
+func ListChanges(options libs.Options, target string) (changes database.Changes, err error) {
+	var since time.Time
+	if options.Report.Since != "" {
+		since, err = utils.ParseTime(options.Report.Since)
+		if err != nil {
+			return changes, err
+		}
+	}
+
+	changes, err = database.GetChanges(target, since, time.Time{})
+	if err != nil {
+		return changes, err
+	}
+
+	if options.Report.Format == "json" {
+		data, _ := jsoniter.MarshalIndent(changes, "", "  ")
+		fmt.Println(string(data))
+		return changes, nil
+	}
+
+	var content [][]string
+	sets := []struct {
+		name string
+		set  database.ChangeSet
+	}{
+		{"host", changes.Hosts},
+		{"port", changes.Ports},
+		{"url", changes.URLs},
+		{"finding", changes.Findings},
+	}
+	for _, item := range sets {
+		for _, value := range item.set.Appeared {
+			content = append(content, []string{item.name, color.HiGreenString("+ appeared"), value})
+		}
+		for _, value := range item.set.Vanished {
+			content = append(content, []string{item.name, color.HiRedString("- vanished"), value})
+		}
+	}
+
+	table := tablewriter.NewWriter(os.Stderr)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeader([]string{"Type", "Change", "Value"})
+	table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+	table.SetColWidth(120)
+	table.AppendBulk(content)
+	table.Render()
+
+	utils.InforF("Total changes of %v since %v: %v", color.HiCyanString(target), changes.Since.Format(time.RFC3339), color.HiMagentaString("%v", changes.Total()))
+	return changes, nil
+}
+
+// QueryReport query the assets and findings of the workspaces then print them in the given format
+func QueryReport(options libs.Options, query string) ([]database.QueryResult, error) {
+	results, err := database.QueryAssets(query, options.Scan.Inputs...)
+	if err != nil {
+		return results, err
+	}
+
+	// use the summary columns of the record type if all the records have the same type
+	header := []string{"workspace", "type", "summary"}
+	recordType := ""
+	for _, result := range results {
+		if recordType != "" && recordType != result.Get("type") {
+			recordType = ""
+			break
+		}
+		recordType = result.Get("type")
+	}
+	if recordType != "" {
+		header = append([]string{"workspace"}, database.SummaryFields(recordType)...)
+	}
+
+	var content [][]string
+	for _, result := range results {
+		row := []string{result.Get("workspace")}
+		if recordType != "" {
+			row = append(row, result.Summary()...)
+		} else {
+			row = append(row, result.Get("type"), strings.Join(result.Summary(), " | "))
+		}
+		content = append(content, row)
+	}
+
+	switch options.Report.Format {
+	case "json":
+		data, _ := jsoniter.MarshalIndent(results, "", "  ")
+		fmt.Println(string(data))
+	case "csv":
+		writer := csv.NewWriter(os.Stdout)
+		writer.Write(header)
+		writer.WriteAll(content)
+	default:
+		table := tablewriter.NewWriter(os.Stderr)
+		table.SetAutoFormatHeaders(false)
+		table.SetHeader(header)
+		table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+		table.SetColWidth(120)
+		table.AppendBulk(content)
+		table.Render()
+		fmt.Println(color.HiGreenString("🔎 Total Records: ") + color.HiMagentaString("%v", len(results)))
+	}
+	return results, nil
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/Shopify/yaml"
 	"github.com/fatih/color"
@@ -14,6 +15,7 @@ import (
 	"github.com/whoamikiddie/vulnx/database"
 	"github.com/whoamikiddie/vulnx/execution"
 	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/metrics"
 	"github.com/whoamikiddie/vulnx/utils"
 )
 
@@ -40,6 +42,7 @@ type Runner struct {
 	TotalSteps    int
 	RunningTime   int
 	CurrentModule string
+	StartTime     time.Time
 
 	DoneFile        string
 	RuntimeFile     string
@@ -56,6 +59,12 @@ type Runner struct {
 	Target map[string]string
 	// this is same as targets but won't change during the execution time
 	Params map[string]string
+
+	// the commands of the steps, they're signaled on cancel, pause and resume
+	Processes *utils.ProcessGroup
+	events    *eventWriter
+	// guard ScanObj since the control actions update it from the watcher goroutine
+	scanMu *sync.Mutex
 }
 
 // InitRunner init runner
@@ -63,6 +72,8 @@ func InitRunner(input string, opt libs.Options) (Runner, error) {
 	var runner Runner
 	runner.Input = input
 	runner.Opt = opt
+	runner.Processes = utils.NewProcessGroup()
+	runner.scanMu = &sync.Mutex{}
 	runner.PrepareRoutine()
 	runner.InitVM()
 
@@ -379,10 +390,13 @@ func (r *Runner) ResolveRoutine() {
 }
 
 func (r *Runner) Start() {
+	r.StartTime = time.Now()
 	err := r.Validator()
 	if err != nil {
 		utils.ErrorF("Input does not match the require type: %v -- %v", r.RequiredInput, r.Input)
 		utils.InforF("Adding %v flag if you want to disable input validate", color.HiCyanString(`'--nv'`))
+		metrics.ScansTotal.WithLabelValues(r.RoutineName, StateFailed).Inc()
+		r.Callback(execution.CallbackFailed, err)
 		return
 	}
 	utils.InforF("Running %s tactic with baseline threads hold as %s", color.YellowString(r.Opt.Tactics), color.HiMagentaString("%v", r.Opt.Threads))
@@ -394,10 +408,18 @@ func (r *Runner) Start() {
 	r.RuntimeFile = r.Target["Output"] + "/runtime"
 	r.WorkspaceFolder = r.Target["Output"]
 	os.Remove(r.DoneFile)
+	r.clearControl()
+	if r.Processes == nil {
+		r.Processes = utils.NewProcessGroup()
+	}
+	r.events = newEventWriter(r.WorkspaceFolder)
+	// the output of the commands is streamed as the log events
+	r.Processes.SetOutput(r.LogEvent)
 
 	utils.TSPrintF("Running the routine %v on %v", color.HiYellowString(r.RoutineName), color.CyanString(r.Input))
 	utils.InforF("Detailed runtime file can be found on %v", color.CyanString(r.RuntimeFile))
-	execution.TeleSendMess(r.Opt, fmt.Sprintf("%s -- Start new scan: %s -- %s", r.Opt.Noti.ClientName, r.Opt.Scan.Flow, r.Target["Workspace"]), "#status", false)
+	r.StateEvent(StateRunning, fmt.Sprintf("Running the routine %v on %v", r.RoutineName, r.Input))
+	execution.Notify(r.Opt, execution.NewMessage("scan-start", fmt.Sprintf("%s -- Start new scan: %s -- %s", r.Opt.Noti.ClientName, r.Opt.Scan.Flow, r.Target["Workspace"]), "", r.Opt))
 
 	r.DBNewTarget()
 	r.DBNewScan()
@@ -407,15 +429,36 @@ func (r *Runner) Start() {
 
 	/////
 	/* really start the scan here */
+	stopControl := r.WatchControl()
 	r.StartRoutines()
+	close(stopControl)
 	/////
 
+	if r.IsCancelled() {
+		r.DBCancelScan()
+		utils.TSPrintF("The scan for %v was cancelled after %v", color.HiCyanString(r.Input), color.HiMagentaString("%vs", r.RunningTime))
+		r.StateEvent(StateCancelled, fmt.Sprintf("The scan for %v was cancelled after %vs", r.Input, r.RunningTime))
+		metrics.ScansTotal.WithLabelValues(r.RoutineName, StateCancelled).Inc()
+		r.Callback(execution.CallbackFailed, ErrScanCancelled)
+		return
+	}
+
 	r.DBDoneScan()
 	utils.TSPrintF(fmt.Sprintf("The scan for %v was completed within %v", color.HiCyanString(r.Input), color.HiMagentaString("%vs", r.RunningTime)))
 
 	if r.Opt.EnableBackup {
 		r.BackupWorkspace()
 	}
+
+	if r.ScanObj.IsError {
+		r.StateEvent(StateFailed, "The scan has been marked as error")
+		metrics.ScansTotal.WithLabelValues(r.RoutineName, StateFailed).Inc()
+		r.Callback(execution.CallbackFailed, fmt.Errorf("the scan has been marked as error"))
+		return
+	}
+	r.StateEvent(StateDone, fmt.Sprintf("The scan for %v was completed within %vs", r.Input, r.RunningTime))
+	metrics.ScansTotal.WithLabelValues(r.RoutineName, StateDone).Inc()
+	r.Callback(execution.CallbackDone, nil)
 }
 
 // StartRoutines start the scan
@@ -436,6 +479,9 @@ func (r *Runner) RunRoutine(modules []libs.Module) {
 	defer p.Release()
 
 	for _, module := range modules {
+		if r.WaitControl() != nil {
+			break
+		}
 		if funk.ContainsString(r.Opt.Exclude, module.Name) {
 			utils.BadBlockF(fmt.Sprintf("Module %v has been excluded", color.CyanString(module.Name)))
 			continue
@@ -3,6 +3,7 @@ package core
 import (
 	"fmt"
 	"os"
+	"path"
 	"strings"
 	"time"
 
@@ -71,7 +72,7 @@ func (r *Runner) ConditionExecScript(script string) bool {
 func (r *Runner) LoadEngineScripts() {
 	r.LoadScripts()
 	r.LoadDBScripts()
-	// r.LoadImportScripts()
+	r.LoadImportScripts()
 	r.LoadExternalScripts()
 	r.LoadGitScripts()
 	r.LoadNotiScripts()
@@ -108,7 +109,7 @@ func (r *Runner) LoadScripts() string {
 	// ExecCmd execute command
 	vm.Set(ExecCmd, func(call otto.FunctionCall) otto.Value {
 		cmd := call.Argument(0).String()
-		_, err := utils.RunCommandWithErr(cmd)
+		_, err := r.Processes.RunOSCommand(cmd)
 		var validate bool
 		if err != nil {
 			validate = true
@@ -150,7 +151,7 @@ func (r *Runner) LoadScripts() string {
 	vm.Set(ExecCmdB, func(call otto.FunctionCall) otto.Value {
 		cmd := call.Argument(0).String()
 		go func() {
-			utils.RunOSCommand(cmd)
+			r.Processes.RunOSCommand(cmd)
 		}()
 		result, _ := vm.ToValue(true)
 		return result
@@ -158,7 +159,7 @@ func (r *Runner) LoadScripts() string {
 
 	// ExecCmd execute command
 	vm.Set(ExecCmdWithOutput, func(call otto.FunctionCall) otto.Value {
-		utils.RunCommandSteamOutput(call.Argument(0).String())
+		r.Processes.RunCommandSteamOutput(call.Argument(0).String())
 		result, err := vm.ToValue(true)
 		if err != nil {
 			return otto.Value{}
@@ -168,7 +169,7 @@ func (r *Runner) LoadScripts() string {
 
 	// ExecCmd execute command
 	vm.Set(ExecContain, func(call otto.FunctionCall) otto.Value {
-		out := utils.RunCmdWithOutput(call.Argument(0).String())
+		out := r.Processes.RunCmdWithOutput(call.Argument(0).String())
 		expected := call.Argument(2).String()
 		validate := strings.Contains(out, expected)
 		result, err := vm.ToValue(validate)
@@ -396,7 +397,7 @@ func (r *Runner) RetryCommandWithExpectString(cmd string, expectString string, t
 	utils.DebugF("Retry command: %s", cmd)
 	for i := 0; i < r.Opt.Cloud.Retry; i++ {
 		if timeout == "000" {
-			out, _ = utils.RunOSCommand(cmd)
+			out, _ = r.Processes.RunOSCommand(cmd)
 		} else {
 			out = utils.RunCmdWithOutput(cmd, timeout)
 		}
@@ -518,6 +519,37 @@ func (r *Runner) LoadNotiScripts() string {
 		return otto.Value{}
 	})
 
+	// SendNoti("#report", "message here")
+	vm.Set(SendNoti, func(call otto.FunctionCall) otto.Value {
+		args := call.ArgumentList
+		messContent := args[0].String()
+		class := execution.ClassStatus
+		if len(args) > 1 {
+			class = args[0].String()
+			messContent = args[1].String()
+		}
+		execution.Notify(options, execution.NewMessage(class, "", messContent, options))
+		return otto.Value{}
+	})
+	// SendNotiFile("#report", "/tmp/report.txt")
+	vm.Set(SendNotiFile, func(call otto.FunctionCall) otto.Value {
+		args := call.ArgumentList
+		fileName := args[0].String()
+		class := execution.ClassReport
+		if len(args) > 1 {
+			class = args[0].String()
+			fileName = args[1].String()
+		}
+		if !utils.FileExists(fileName) {
+			utils.DebugF("File %s not found", fileName)
+			return otto.Value{}
+		}
+		msg := execution.NewMessage(class, path.Base(fileName), "", options)
+		msg.File = fileName
+		execution.Notify(options, msg)
+		return otto.Value{}
+	})
+
 	return output
 
 }
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/robfig/cron/v3"
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// schedulerInterval how often the scheduler checks the due schedules
+const schedulerInterval = 30 * time.Second
+
+var schedulerOnce sync.Once
+
+// ParseCron parse the standard cron expression (or a descriptor like @daily) in the time zone
+func ParseCron(expr string, timezone string) (cron.Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("cron expression is required")
+	}
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("invalid time zone %v: %v", timezone, err)
+		}
+		expr = fmt.Sprintf("CRON_TZ=%v %v", timezone, expr)
+	}
+	schedule, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %v: %v", expr, err)
+	}
+	return schedule, nil
+}
+
+// NextScheduleRun the next run of the schedule after the time, the random jitter is added to it
+func NextScheduleRun(schedule database.Schedule, after time.Time) (time.Time, error) {
+	parsed, err := ParseCron(schedule.CronExpr, schedule.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	next := parsed.Next(after)
+	if schedule.Jitter > 0 {
+		next = next.Add(time.Duration(rand.Intn(schedule.Jitter)) * time.Second)
+	}
+	return next, nil
+}
+
+// AddSchedule validate and store the schedule with its first run
+// the job of the schedule goes through the same checks as the scans of the API, see ValidateJob
+func AddSchedule(schedule *database.Schedule, options libs.Options) error {
+	schedule.Name = strings.TrimSpace(schedule.Name)
+	if schedule.Name == "" {
+		return fmt.Errorf("schedule name is required")
+	}
+	if strings.TrimSpace(schedule.Input) == "" {
+		return fmt.Errorf("input of the schedule is required")
+	}
+	if len(schedule.Modules) > 0 {
+		schedule.Flow = ""
+	} else if schedule.Flow == "" {
+		schedule.Flow = "general"
+	}
+	job := ScheduleJob(*schedule)
+	if err := ValidateJob(&job, options); err != nil {
+		return err
+	}
+	schedule.Input = job.Input
+	next, err := NextScheduleRun(*schedule, time.Now())
+	if err != nil {
+		return err
+	}
+	schedule.NextRun = &next
+	return database.SaveSchedule(schedule)
+}
+
+// PauseSchedule pause or resume the schedule, the next run is calculated again when resumed
+func PauseSchedule(name string, paused bool) (*database.Schedule, error) {
+	schedule, err := database.GetSchedule(name)
+	if err != nil {
+		return nil, err
+	}
+	schedule.Paused = paused
+	if !paused {
+		next, err := NextScheduleRun(*schedule, time.Now())
+		if err != nil {
+			return schedule, err
+		}
+		schedule.NextRun = &next
+	}
+	return schedule, database.SaveSchedule(schedule)
+}
+
+// ScheduleJob the job of the schedule
+func ScheduleJob(schedule database.Schedule) database.Job {
+	job := NewJob(libs.InputFormat{
+		Input:       schedule.Input,
+		Flow:        schedule.Flow,
+		Modules:     schedule.Modules,
+		Params:      schedule.Params,
+		Workspaces:  schedule.Workspace,
+		InputAsFile: schedule.InputAsFile,
+	})
+	job.Priority = schedule.Priority
+	return job
+}
+
+// RunDueSchedules add the jobs of the due schedules to the queue
+// the run is skipped if the same target and flow is still pending or running in the queue
+// the job is checked again since the uploaded list of targets could have been replaced
+func RunDueSchedules(now time.Time, options libs.Options) (queued int, skipped int) {
+	schedules, err := database.DueSchedules(now)
+	if err != nil {
+		utils.ErrorF("Error getting the due schedules: %v", err)
+		return queued, skipped
+	}
+	for _, schedule := range schedules {
+		next, err := NextScheduleRun(schedule, now)
+		if err != nil {
+			utils.ErrorF("Error parsing the schedule %v: %v", schedule.Name, err)
+			database.UpdateScheduleStatus(schedule.ID, database.ScheduleError, 0)
+			continue
+		}
+		// the other scheduler process already took this run
+		if claimed, err := database.ClaimScheduleRun(schedule, next); err != nil || !claimed {
+			continue
+		}
+
+		job := ScheduleJob(schedule)
+		if err := ValidateJob(&job, options); err != nil {
+			utils.ErrorF("Error checking the job of the schedule %v: %v", schedule.Name, err)
+			database.UpdateScheduleStatus(schedule.ID, database.ScheduleError, 0)
+			continue
+		}
+		created, err := database.EnqueueJob(&job)
+		if err != nil {
+			utils.ErrorF("Error adding the job of the schedule %v: %v", schedule.Name, err)
+			database.UpdateScheduleStatus(schedule.ID, database.ScheduleError, 0)
+			continue
+		}
+		if !created {
+			utils.WarnF("Skipped the schedule %v since the job %v is still %v", color.HiCyanString(schedule.Name),
+				color.HiMagentaString("#%v", job.ID), job.State)
+			database.UpdateScheduleStatus(schedule.ID, database.ScheduleSkipped, job.ID)
+			skipped++
+			continue
+		}
+		utils.InforF("Queued the job %v of the schedule %v, next run at %v", color.HiMagentaString("#%v", job.ID),
+			color.HiCyanString(schedule.Name), next.Format(time.RFC3339))
+		database.UpdateScheduleStatus(schedule.ID, database.ScheduleQueued, job.ID)
+		queued++
+	}
+	return queued, skipped
+}
+
+// StartScheduler check the due schedules in the background, it's started once per process
+func StartScheduler(options libs.Options) {
+	if database.DB == nil {
+		return
+	}
+	schedulerOnce.Do(func() {
+		utils.InforF("Starting the scheduler, the due schedules are checked every %v", schedulerInterval)
+		go func() {
+			RunDueSchedules(time.Now(), options)
+			ticker := time.NewTicker(schedulerInterval)
+			defer ticker.Stop()
+			for now := range ticker.C {
+				RunDueSchedules(now, options)
+			}
+		}()
+	})
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// testWorkflows the options with the general flow and the probing module
+func testWorkflows(t *testing.T) libs.Options {
+	var options libs.Options
+	options.Env.WorkFlowsFolder = t.TempDir()
+	utils.WriteToFile(path.Join(options.Env.WorkFlowsFolder, "general.yaml"), "name: general")
+	utils.MakeDir(path.Join(options.Env.WorkFlowsFolder, "default-modules"))
+	utils.WriteToFile(path.Join(options.Env.WorkFlowsFolder, "default-modules", "probing.yaml"), "name: probing")
+	return options
+}
+
+func TestParseCron(t *testing.T) {
+	schedule, err := ParseCron("30 2 * * *", "Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("Error ParseCron: %v", err)
+	}
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	next := schedule.Next(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)).In(tokyo)
+	if next.Hour() != 2 || next.Minute() != 30 {
+		t.Errorf("Error next run in the time zone: %v", next)
+	}
+
+	if _, err := ParseCron("* * *", ""); err == nil {
+		t.Errorf("Error ParseCron should reject the invalid expression")
+	}
+	if _, err := ParseCron("@daily", "Mars/Olympus"); err == nil {
+		t.Errorf("Error ParseCron should reject the invalid time zone")
+	}
+
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	next, err = NextScheduleRun(database.Schedule{CronExpr: "@hourly", Timezone: "UTC", Jitter: 60}, after)
+	if err != nil || next.Before(after.Add(time.Hour)) || !next.Before(after.Add(time.Hour+time.Minute)) {
+		t.Errorf("Error NextScheduleRun with jitter: %v %v", next, err)
+	}
+}
+
+func TestRunDueSchedules(t *testing.T) {
+	initImportRunner(t)
+	options := testWorkflows(t)
+
+	schedule := database.Schedule{Name: "nightly", CronExpr: "0 2 * * *", Timezone: "UTC", Input: "example.com"}
+	if err := AddSchedule(&schedule, options); err != nil {
+		t.Fatalf("Error AddSchedule: %v", err)
+	}
+	if schedule.Flow != "general" || schedule.NextRun == nil {
+		t.Errorf("Error AddSchedule defaults: %v %v", schedule.Flow, schedule.NextRun)
+	}
+	if err := AddSchedule(&database.Schedule{Name: "nightly", CronExpr: "@daily", Input: "example.com"}, options); err == nil {
+		t.Errorf("Error AddSchedule should reject the duplicate name")
+	}
+
+	if queued, skipped := RunDueSchedules(time.Now(), options); queued != 0 || skipped != 0 {
+		t.Errorf("Error RunDueSchedules should not run before the next run: %v %v", queued, skipped)
+	}
+
+	now := schedule.NextRun.Add(time.Second)
+	if queued, _ := RunDueSchedules(now, options); queued != 1 {
+		t.Fatalf("Error RunDueSchedules should queue the job: %v", queued)
+	}
+	jobs, _ := database.ListJobs([]string{database.JobPending}, 0)
+	if len(jobs) != 1 || jobs[0].Input != "example.com" || jobs[0].Flow != "general" {
+		t.Fatalf("Error job of the schedule: %v", jobs)
+	}
+
+	// the previous run is still pending so the next one is skipped
+	now = now.Add(24 * time.Hour)
+	if queued, skipped := RunDueSchedules(now, options); queued != 0 || skipped != 1 {
+		t.Errorf("Error RunDueSchedules should skip the overlapping run: %v %v", queued, skipped)
+	}
+	updated, _ := database.GetSchedule("nightly")
+	if updated.LastStatus != database.ScheduleSkipped || updated.LastJobID != jobs[0].ID || !updated.NextRun.After(now) {
+		t.Errorf("Error schedule status: %v %v %v", updated.LastStatus, updated.LastJobID, updated.NextRun)
+	}
+
+	// paused schedules are not run
+	if _, err := PauseSchedule("nightly", true); err != nil {
+		t.Fatalf("Error PauseSchedule: %v", err)
+	}
+	database.FinishJob(jobs[0].ID, nil)
+	if queued, _ := RunDueSchedules(now.Add(48*time.Hour), options); queued != 0 {
+		t.Errorf("Error RunDueSchedules should not run the paused schedule")
+	}
+	resumed, err := PauseSchedule("nightly", false)
+	if err != nil || resumed.Paused || !resumed.NextRun.After(time.Now()) {
+		t.Errorf("Error resume the schedule: %v", err)
+	}
+}
+
+func TestAddScheduleValidation(t *testing.T) {
+	initImportRunner(t)
+	options := testWorkflows(t)
+	outside := path.Join(t.TempDir(), "targets.txt")
+	utils.WriteToFile(outside, "example.com")
+
+	rejected := []struct {
+		name     string
+		schedule database.Schedule
+	}{
+		{"target", database.Schedule{Input: "a.com;id"}},
+		{"subshell", database.Schedule{Input: "$(id)"}},
+		{"param", database.Schedule{Input: "example.com", Params: []string{"threads=10&id"}}},
+		{"workspace", database.Schedule{Input: "example.com", Workspace: "../ws"}},
+		{"unknown flow", database.Schedule{Input: "example.com", Flow: "not-exist"}},
+		{"unknown module", database.Schedule{Input: "example.com", Modules: []string{"not-exist"}}},
+		{"targets file outside of the uploads", database.Schedule{Input: outside, InputAsFile: true}},
+		{"system file", database.Schedule{Input: "/etc/passwd", InputAsFile: true}},
+		{"targets file path", database.Schedule{Input: InputFolder() + "../../etc/passwd", InputAsFile: true}},
+	}
+	for _, tc := range rejected {
+		schedule := tc.schedule
+		schedule.Name = "rejected"
+		schedule.CronExpr = "@daily"
+		if err := AddSchedule(&schedule, options); err == nil {
+			t.Errorf("Error AddSchedule should reject the %v: %+v", tc.name, tc.schedule)
+		}
+	}
+
+	targets := SaveTargets([]string{"example.com", "10.0.0.0/24"})
+	t.Cleanup(func() { os.Remove(targets) })
+	schedule := database.Schedule{Name: "uploaded", CronExpr: "@daily", Input: targets, InputAsFile: true, Modules: []string{"probing"}}
+	if err := AddSchedule(&schedule, options); err != nil {
+		t.Fatalf("Error AddSchedule with the uploaded targets: %v", err)
+	}
+
+	// the uploaded list replaced after the schedule is added is checked again before the run
+	utils.WriteToFile(targets, "a.com;id")
+	if queued, _ := RunDueSchedules(schedule.NextRun.Add(time.Second), options); queued != 0 {
+		t.Errorf("Error RunDueSchedules should not queue the invalid targets")
+	}
+	if updated, _ := database.GetSchedule("uploaded"); updated.LastStatus != database.ScheduleError {
+		t.Errorf("Error the schedule should be marked as failed: %v", updated.LastStatus)
+	}
+}
@@ -156,18 +156,19 @@ func (r *Runner) RunCommands(commands []string, std string) string {
 			var out string
 			if std != "" {
 				if strings.Contains(std, "/dev/pts") {
-					if err := utils.RunOSCommandStream(command, std); err != nil {
+					if err := r.Processes.RunOSCommandStream(command, std); err != nil {
 						utils.DebugF("error running command: %v -- %v", command, err)
 					}
 					return
 				}
-				out, err = utils.RunOSCommand(command)
+				out, err = r.Processes.RunOSCommand(command)
 			} else {
-				err = utils.RunCommandWithoutOutput(command)
+				err = r.Processes.RunCommandWithoutOutput(command)
 			}
 
 			if err != nil {
 				utils.DebugF("error running command: %v -- %v", color.HiYellowString(command), err)
+				r.LogEvent(fmt.Sprintf("Error running the command: %v", err))
 			}
 
 			if out != "" {
@@ -266,11 +266,6 @@ func GetNotification(options *libs.Options) {
 	options.Noti.SlackToken = tokens["slack_api_token"]
 	options.Noti.TelegramToken = tokens["telegram_api_token"]
 
-	// this mean you're not setup the notification yet
-	if len(options.Noti.TelegramToken) < 20 {
-		options.NoNoti = true
-	}
-
 	options.Noti.ClientName = noti["client_name"]
 	options.Noti.SlackStatusChannel = noti["slack_status_channel"]
 	options.Noti.SlackReportChannel = noti["slack_report_channel"]
@@ -282,6 +277,79 @@ func GetNotification(options *libs.Options) {
 	options.Noti.TelegramStatusChannel = noti["telegram_status_channel"]
 	options.Noti.TelegramDirbChannel = noti["telegram_dirb_channel"]
 	options.Noti.TelegramMicsChannel = noti["telegram_mics_channel"]
+
+	// named notifiers and the routes of the message classes
+	options.Noti.Notifiers = make(map[string]libs.NotifierConfig)
+	if err := v.UnmarshalKey("notifiers", &options.Noti.Notifiers); err != nil {
+		utils.ErrorF("Error reading notifiers config: %v", err)
+	}
+	options.Noti.Routes = v.GetStringMapStringSlice("notification_routes")
+	if err := v.UnmarshalKey("notification_rules", &options.Noti.Rules); err != nil {
+		utils.ErrorF("Error reading notification rules: %v", err)
+	}
+	options.Noti.DedupWindow = noti["dedup_window"]
+	options.Noti.RateLimit = utils.StrToInt(noti["rate_limit"])
+	options.Noti.RateWindow = noti["rate_window"]
+	options.Noti.DigestInterval = noti["digest_interval"]
+	addLegacyNotifiers(options)
+
+	// this mean you're not setup the notification yet
+	if len(options.Noti.TelegramToken) < 20 && len(options.Noti.Notifiers) == 0 {
+		options.NoNoti = true
+	}
+}
+
+// addLegacyNotifiers keep the Slack and Telegram settings of the notification section working as named notifiers
+func addLegacyNotifiers(options *libs.Options) {
+	isSet := func(value string) bool {
+		// the default config use the name of the environment variable as a placeholder
+		return value != "" && strings.ToUpper(value) != value
+	}
+	isSetID := func(value string) bool {
+		return value != "" && strings.Trim(value, "-0123456789") == ""
+	}
+
+	if _, ok := options.Noti.Notifiers["slack"]; !ok {
+		if len(options.Noti.SlackToken) > 20 {
+			options.Noti.Notifiers["slack"] = libs.NotifierConfig{
+				Type:    "slack",
+				Token:   options.Noti.SlackToken,
+				Channel: options.Noti.SlackStatusChannel,
+				Channels: map[string]string{
+					execution.ClassStatus: options.Noti.SlackStatusChannel,
+					execution.ClassReport: options.Noti.SlackReportChannel,
+					execution.ClassDiff:   options.Noti.SlackDiffChannel,
+				},
+			}
+		} else if isSet(options.Noti.SlackWebHook) {
+			options.Noti.Notifiers["slack"] = libs.NotifierConfig{Type: "slack", URL: options.Noti.SlackWebHook}
+		}
+	}
+
+	if _, ok := options.Noti.Notifiers["telegram"]; !ok && len(options.Noti.TelegramToken) >= 20 && isSetID(options.Noti.TelegramChannel) {
+		channels := make(map[string]string)
+		if isSetID(options.Noti.TelegramStatusChannel) {
+			channels[execution.ClassStatus] = options.Noti.TelegramStatusChannel
+		}
+		if isSetID(options.Noti.TelegramReportChannel) {
+			channels[execution.ClassReport] = options.Noti.TelegramReportChannel
+		}
+		if isSetID(options.Noti.TelegramSensitiveChannel) {
+			channels[execution.ClassSensitive] = options.Noti.TelegramSensitiveChannel
+		}
+		if isSetID(options.Noti.TelegramDirbChannel) {
+			channels[execution.ClassDirb] = options.Noti.TelegramDirbChannel
+		}
+		if isSetID(options.Noti.TelegramMicsChannel) {
+			channels[execution.ClassMics] = options.Noti.TelegramMicsChannel
+		}
+		options.Noti.Notifiers["telegram"] = libs.NotifierConfig{
+			Type:     "telegram",
+			Token:    options.Noti.TelegramToken,
+			Channel:  options.Noti.TelegramChannel,
+			Channels: channels,
+		}
+	}
 }
 
 // GetCdn get options for client
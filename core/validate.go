@@ -2,15 +2,89 @@ package core
 
 import (
 	"fmt"
+	"os"
 	"path"
+	"regexp"
 	"strings"
 
 	"github.com/fatih/color"
 	"github.com/go-playground/validator/v10"
+	"github.com/whoamikiddie/vulnx/database"
 	"github.com/whoamikiddie/vulnx/libs"
 	"github.com/whoamikiddie/vulnx/utils"
 )
 
+// the fields of the API scans and the schedules end up in the commands of the modules, only these characters are accepted
+var (
+	// domain, IP, CIDR or URL without the query string
+	safeTarget = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:/@%+,=~-]*$`)
+	safeParam  = regexp.MustCompile(`^[A-Za-z0-9_]+=[A-Za-z0-9._:/@%+,=~-]*$`)
+)
+
+// InputFolder the folder of the uploaded lists of targets, the target file of the job could only be read from here
+func InputFolder() string {
+	return fmt.Sprintf("/tmp/%v-input/", libs.BINARY)
+}
+
+// SaveTargets write the targets to a new file in the input folder
+func SaveTargets(targets []string) string {
+	baseDir := InputFolder()
+	if !utils.FolderExists(baseDir) {
+		os.MkdirAll(baseDir, 0755)
+	}
+	targetFile := path.Join(baseDir, fmt.Sprintf("data-%v-%v-%v.txt", utils.GetTS(), libs.BINARY, utils.RandomString(6)))
+	filename, err := utils.WriteToFile(targetFile, strings.Join(targets, "\n"))
+	if err != nil {
+		return ""
+	}
+	return filename
+}
+
+// CheckTargets make sure every target is a domain, IP, CIDR or URL
+func CheckTargets(targets []string) error {
+	for _, target := range targets {
+		if target = strings.TrimSpace(target); target != "" && !safeTarget.MatchString(target) {
+			return fmt.Errorf("invalid target: %v", target)
+		}
+	}
+	return nil
+}
+
+// ValidateJob make sure the job of the API or the schedule is safe to run
+// the fields are never joined into a shell command, the target file must be in the input folder and the flow or modules must exist
+func ValidateJob(job *database.Job, options libs.Options) error {
+	if job.Command != "" {
+		return fmt.Errorf("raw command is not allowed, please use the workflow or plugin field")
+	}
+
+	job.Input = strings.TrimSpace(job.Input)
+	switch {
+	case job.InputAsFile:
+		targetsFile := path.Clean(job.Input)
+		if !strings.HasPrefix(targetsFile, InputFolder()) || !utils.FileExists(targetsFile) {
+			return fmt.Errorf("targets file %v not found, please upload it first", job.Input)
+		}
+		if err := CheckTargets(utils.ReadingFileUnique(targetsFile)); err != nil {
+			return err
+		}
+		job.Input = targetsFile
+	case job.Input == "":
+		return fmt.Errorf("target is required")
+	case !safeTarget.MatchString(job.Input):
+		return fmt.Errorf("invalid target: %v", job.Input)
+	}
+
+	for _, param := range job.Params {
+		if !safeParam.MatchString(param) {
+			return fmt.Errorf("invalid param: %v", param)
+		}
+	}
+	if job.Workspace != "" && !utils.IsSafeName(job.Workspace) {
+		return fmt.Errorf("invalid workspace: %v", job.Workspace)
+	}
+	return ValidateRoutine(job.Flow, job.Modules, options)
+}
+
 func (r *Runner) Validator() error {
 	if r.RequiredInput == "" || r.Opt.DisableValidateInput {
 		return nil
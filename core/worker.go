@@ -0,0 +1,242 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/whoamikiddie/vulnx/api"
+	"github.com/whoamikiddie/vulnx/client"
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/execution"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// LeasedJob the job sent to the remote worker
+type LeasedJob = api.LeasedJob
+
+// errNoJob the server has no pending job
+var errNoJob = errors.New("no pending job")
+
+// Worker pull the jobs from the queue of the server and run them locally
+type Worker struct {
+	Opt      libs.Options
+	Server   string
+	Name     string
+	Hostname string
+	Lease    time.Duration
+
+	client *client.Client
+}
+
+// NewWorker create the worker of the server, the worker name is the hostname if it's not set
+func NewWorker(options libs.Options) (*Worker, error) {
+	server := strings.TrimRight(strings.TrimSpace(options.Worker.Server), "/")
+	if server == "" {
+		return nil, fmt.Errorf("server URL is required")
+	}
+	hostname, _ := os.Hostname()
+	name := options.Worker.Name
+	if name == "" {
+		name = fmt.Sprintf("%v-%v", hostname, os.Getpid())
+	}
+	lease := time.Duration(options.Worker.Lease) * time.Second
+	if lease <= 0 {
+		lease = 2 * time.Minute
+	}
+	return &Worker{
+		Opt:      options,
+		Server:   server,
+		Name:     name,
+		Hostname: hostname,
+		Lease:    lease,
+		client:   client.New(server, options.Worker.Token, false),
+	}, nil
+}
+
+// Login get the token from the server with the username and password, the token option is used as is if it's set
+func (w *Worker) Login() error {
+	if w.client.Token != "" {
+		return nil
+	}
+	login, err := w.client.Login(w.Opt.Worker.Username, w.Opt.Worker.Password)
+	if err != nil {
+		return fmt.Errorf("login to %v failed: %v", w.Server, err)
+	}
+	if login.Token == "" {
+		return fmt.Errorf("login to %v failed: empty token", w.Server)
+	}
+	return nil
+}
+
+// jobError turn the conflict of the server into database.ErrJobLost
+func jobError(err error) error {
+	if client.IsStatus(err, http.StatusConflict) {
+		return database.ErrJobLost
+	}
+	return err
+}
+
+func (w *Worker) request() api.WorkerRequest {
+	return api.WorkerRequest{
+		Worker:   w.Name,
+		Hostname: w.Hostname,
+		Lease:    int(w.Lease.Seconds()),
+	}
+}
+
+// LeaseJob ask the server for the next job, errNoJob is returned if the queue is empty
+func (w *Worker) LeaseJob() (*LeasedJob, error) {
+	leased, err := w.client.LeaseJob(w.request())
+	if err != nil {
+		return nil, err
+	}
+	if leased == nil {
+		return nil, errNoJob
+	}
+	return leased, nil
+}
+
+// Heartbeat extend the lease of the job, database.ErrJobLost is returned if the job was cancelled or re-queued
+func (w *Worker) Heartbeat(id uint) error {
+	return jobError(w.client.HeartbeatJob(id, w.request()))
+}
+
+// Finish report the result of the job
+func (w *Worker) Finish(id uint, jobErr error) error {
+	req := w.request()
+	if jobErr != nil {
+		req.Error = jobErr.Error()
+	}
+	return jobError(w.client.FinishJob(id, req))
+}
+
+// UploadWorkspace compress the workspace and send it to the server
+func (w *Worker) UploadWorkspace(id uint, workspace string) error {
+	src := path.Join(utils.NormalizePath(w.Opt.Env.WorkspacesFolder), workspace)
+	if utils.FolderLength(src) == 0 {
+		return fmt.Errorf("workspace %v is empty", workspace)
+	}
+	tmpDir, err := os.MkdirTemp("", fmt.Sprintf("%v-worker-", libs.BINARY))
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	archive := path.Join(tmpDir, workspace+".tar.gz")
+	execution.Compress(archive, src)
+	if !utils.FileExists(archive) {
+		return fmt.Errorf("error compressing the workspace %v", workspace)
+	}
+	_, err = w.client.UploadWorkspace(id, w.Name, workspace, archive)
+	return jobError(err)
+}
+
+// RunLeasedJob run the job while sending the heartbeat, then upload the workspaces and report the result
+// the commands of the job are killed once the job is no longer leased to the worker
+func (w *Worker) RunLeasedJob(leased LeasedJob) error {
+	job := leased.Job
+	job.CallbackSecret = leased.CallbackSecret
+	if job.InputAsFile && job.Command == "" {
+		inputFile := path.Join(os.TempDir(), fmt.Sprintf("%v-job-%v.txt", libs.BINARY, job.ID))
+		utils.WriteToFile(inputFile, strings.Join(leased.Inputs, "\n"))
+		defer os.Remove(inputFile)
+		job.Input = inputFile
+	}
+
+	processes := utils.NewProcessGroup()
+	stop := make(chan struct{})
+	var lost bool
+	var mu sync.Mutex
+	go func() {
+		ticker := time.NewTicker(w.Lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				err := w.Heartbeat(job.ID)
+				if errors.Is(err, database.ErrJobLost) {
+					utils.WarnF("The job %v is no longer leased to this worker", color.HiMagentaString("#%v", job.ID))
+					mu.Lock()
+					lost = true
+					mu.Unlock()
+					processes.Kill()
+					return
+				}
+				if err != nil {
+					utils.WarnF("Error sending the heartbeat of the job %v: %v", job.ID, err)
+				}
+			}
+		}
+	}()
+
+	workspaces, jobErr := RunJob(job, w.Opt, processes)
+	close(stop)
+	mu.Lock()
+	defer mu.Unlock()
+	if lost {
+		return database.ErrJobLost
+	}
+
+	for _, workspace := range workspaces {
+		utils.InforF("Uploading the workspace %v to %v", color.HiCyanString(workspace), color.HiCyanString(w.Server))
+		if err := w.UploadWorkspace(job.ID, workspace); err != nil {
+			utils.ErrorF("Error uploading the workspace %v: %v", workspace, err)
+			if jobErr == nil {
+				jobErr = fmt.Errorf("error uploading the workspace %v: %v", workspace, err)
+			}
+			continue
+		}
+		if !w.Opt.Worker.KeepWorkspace {
+			os.RemoveAll(path.Join(utils.NormalizePath(w.Opt.Env.WorkspacesFolder), workspace))
+		}
+	}
+	return w.Finish(job.ID, jobErr)
+}
+
+// Start lease and run the jobs with the concurrency level until the process is stopped
+func (w *Worker) Start() error {
+	if err := w.Login(); err != nil {
+		return err
+	}
+	concurrency := w.Opt.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	interval := time.Duration(w.Opt.Queue.PollingTime) * time.Second
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	utils.InforF("Worker %v is waiting for the jobs from %v with concurrency %v", color.HiCyanString(w.Name),
+		color.HiCyanString(w.Server), color.HiMagentaString("%v", concurrency))
+
+	slots := make(chan struct{}, concurrency)
+	for {
+		slots <- struct{}{}
+		leased, err := w.LeaseJob()
+		if err != nil {
+			<-slots
+			if err != errNoJob {
+				utils.ErrorF("Error leasing the job from %v: %v", w.Server, err)
+			}
+			time.Sleep(interval)
+			continue
+		}
+
+		utils.InforF("Leased the job %v: %v", color.HiMagentaString("#%v", leased.ID), color.HiCyanString(leased.Input))
+		go func(leased LeasedJob) {
+			defer func() { <-slots }()
+			if err := w.RunLeasedJob(leased); err != nil {
+				utils.ErrorF("Error running the job %v: %v", leased.ID, err)
+			}
+		}(*leased)
+	}
+}
@@ -0,0 +1,163 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/whoamikiddie/vulnx/api"
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// fakeJobServer the worker endpoints of the server, the heartbeat of the job is rejected once lost is set
+type fakeJobServer struct {
+	mu       sync.Mutex
+	jobs     []LeasedJob
+	lost     bool
+	finished map[uint]string
+	uploaded []string
+	requests []api.WorkerRequest
+}
+
+func (s *fakeJobServer) handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/login", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"token":"secret"}`)
+	})
+	mux.HandleFunc("/api/osmp/jobs/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Osmedeus secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req api.WorkerRequest
+		if r.Header.Get("Content-Type") == "application/json" {
+			jsoniter.NewDecoder(r.Body).Decode(&req)
+		} else {
+			req.Worker = r.FormValue("worker")
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests = append(s.requests, req)
+
+		var id uint
+		var action string
+		if r.URL.Path == "/api/osmp/jobs/lease" {
+			response := api.Response{Status: 200}
+			if len(s.jobs) > 0 {
+				response.Data = s.jobs[0]
+				s.jobs = s.jobs[1:]
+			}
+			jsoniter.NewEncoder(w).Encode(response)
+			return
+		}
+		fmt.Sscanf(r.URL.Path, "/api/osmp/jobs/%d/%s", &id, &action)
+		switch action {
+		case "heartbeat":
+			if s.lost {
+				w.WriteHeader(http.StatusConflict)
+				fmt.Fprintf(w, `{"error":%q}`, database.ErrJobLost.Error())
+				return
+			}
+		case "finish":
+			s.finished[id] = req.Error
+		case "upload":
+			file, _, err := r.FormFile("archive")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			data, _ := io.ReadAll(file)
+			s.uploaded = append(s.uploaded, fmt.Sprintf("%v:%v:%v", id, r.FormValue("workspace"), len(data) > 0))
+		default:
+			t.Errorf("Error unexpected request %v", r.URL.Path)
+		}
+		jsoniter.NewEncoder(w).Encode(api.Response{Status: 200})
+	})
+	return mux
+}
+
+func newTestWorker(t *testing.T, fake *fakeJobServer) *Worker {
+	server := httptest.NewServer(fake.handler(t))
+	t.Cleanup(server.Close)
+	var options libs.Options
+	options.Worker.Server = server.URL + "/"
+	options.Worker.Name = "worker-1"
+	options.Worker.Username = "osmedeus"
+	options.Worker.Password = "password"
+	options.Worker.Lease = 1
+	options.Env.WorkspacesFolder = t.TempDir()
+	worker, err := NewWorker(options)
+	if err != nil {
+		t.Fatalf("Error NewWorker: %v", err)
+	}
+	// a short lease so the heartbeat is sent a few times per second
+	worker.Lease = 300 * time.Millisecond
+	if err := worker.Login(); err != nil {
+		t.Fatalf("Error Login: %v", err)
+	}
+	return worker
+}
+
+func TestWorkerLeaseJob(t *testing.T) {
+	fake := &fakeJobServer{finished: make(map[uint]string)}
+	worker := newTestWorker(t, fake)
+
+	if _, err := worker.LeaseJob(); err != errNoJob {
+		t.Errorf("Error LeaseJob should return errNoJob on the empty queue: %v", err)
+	}
+
+	fake.jobs = []LeasedJob{{Job: database.Job{Model: database.Model{ID: 7}, Input: "example.com", Command: "exit 3 # {{.input}}"}}}
+	leased, err := worker.LeaseJob()
+	if err != nil || leased.ID != 7 || leased.Input != "example.com" {
+		t.Fatalf("Error LeaseJob: %+v %v", leased, err)
+	}
+	if err := worker.RunLeasedJob(*leased); err != nil {
+		t.Fatalf("Error RunLeasedJob: %v", err)
+	}
+	if msg, ok := fake.finished[7]; !ok || msg == "" {
+		t.Errorf("Error the failure of the job should be reported: %v %v", ok, msg)
+	}
+	utils.MakeDir(path.Join(worker.Opt.Env.WorkspacesFolder, "example.com"))
+	utils.WriteToFile(path.Join(worker.Opt.Env.WorkspacesFolder, "example.com", "http.txt"), "https://example.com")
+	if err := worker.UploadWorkspace(7, "example.com"); err != nil {
+		t.Fatalf("Error UploadWorkspace: %v", err)
+	}
+	if len(fake.uploaded) != 1 || fake.uploaded[0] != "7:example.com:true" {
+		t.Errorf("Error the workspace archive should be uploaded: %v", fake.uploaded)
+	}
+
+	for _, req := range fake.requests {
+		if req.Worker != "worker-1" {
+			t.Errorf("Error the worker name should be sent: %+v", req)
+		}
+	}
+}
+
+func TestWorkerJobLost(t *testing.T) {
+	fake := &fakeJobServer{finished: make(map[uint]string), lost: true}
+	worker := newTestWorker(t, fake)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- worker.RunLeasedJob(LeasedJob{Job: database.Job{Model: database.Model{ID: 8}, Input: "example.com", Command: "sleep 30 # {{.input}}"}})
+	}()
+	select {
+	case err := <-done:
+		if err != database.ErrJobLost {
+			t.Errorf("Error RunLeasedJob: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Error the job should be killed once the lease is lost")
+	}
+	if _, ok := fake.finished[8]; ok {
+		t.Errorf("Error the lost job should not be reported as finished")
+	}
+}
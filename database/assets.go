@@ -0,0 +1,192 @@
+package database
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Asset a host (subdomain or IP address) that belongs to the target
+type Asset struct {
+	Model
+	Tracking
+	AssetValue string `gorm:"type:varchar(255);not null;uniqueIndex:idx_asset_value" json:"asset_value"`
+	IsAlive    bool   `json:"is_alive"`
+	Technology string `gorm:"type:longtext" json:"technology"`
+
+	ScanRefer   uint `json:"scan_id"`
+	TargetRefer uint `gorm:"uniqueIndex:idx_asset_value" json:"target_id"`
+}
+
+// Dns a DNS record of a host
+type Dns struct {
+	Model
+	Domain      string `gorm:"type:varchar(255);index" json:"domain"`
+	DnsType     string `gorm:"type:varchar(32)" json:"dns_type"`
+	DnsValue    string `gorm:"type:varchar(255)" json:"dns_value"`
+	DnsChecksum string `gorm:"type:varchar(64);uniqueIndex:idx_dns_checksum" json:"dns_checksum"`
+
+	ScanRefer   uint `json:"scan_id"`
+	TargetRefer uint `gorm:"uniqueIndex:idx_dns_checksum" json:"target_id"`
+}
+
+// Port an open port and the service running on it
+type Port struct {
+	Model
+	Tracking
+	Host      string `gorm:"type:varchar(255)" json:"host"`
+	IPAddress string `gorm:"type:varchar(64);uniqueIndex:idx_port" json:"ip_address"`
+	PortID    int    `gorm:"uniqueIndex:idx_port" json:"port"`
+	Protocol  string `gorm:"type:varchar(16);uniqueIndex:idx_port" json:"protocol"`
+	State     string `gorm:"type:varchar(32)" json:"state"`
+	Service   string `gorm:"type:varchar(255)" json:"service"`
+	Product   string `gorm:"type:varchar(255)" json:"product"`
+	Cpe       string `gorm:"type:varchar(255)" json:"cpe"`
+
+	ScanRefer   uint `json:"scan_id"`
+	TargetRefer uint `gorm:"uniqueIndex:idx_port" json:"target_id"`
+}
+
+// HTTP a live HTTP endpoint with its fingerprint
+type HTTP struct {
+	Model
+	Tracking
+	URL           string `gorm:"type:varchar(2048);not null;uniqueIndex:idx_http_url" json:"url"`
+	Host          string `gorm:"type:varchar(255);index" json:"host"`
+	Title         string `gorm:"type:varchar(1024)" json:"title"`
+	StatusCode    int    `json:"status_code"`
+	ContentLength int    `json:"content_length"`
+	Checksum      string `gorm:"type:varchar(64)" json:"checksum"`
+	Redirect      string `gorm:"type:varchar(2048)" json:"redirect"`
+	Technology    string `gorm:"type:longtext" json:"technology"`
+	ContentFile   string `gorm:"type:varchar(2048)" json:"content_file"`
+	ScreenShot    string `gorm:"type:varchar(2048)" json:"screenshot"`
+
+	ScanRefer   uint `json:"scan_id"`
+	TargetRefer uint `gorm:"uniqueIndex:idx_http_url" json:"target_id"`
+}
+
+// Credential a leaked credential related to the target
+type Credential struct {
+	Model
+	CredID         string `gorm:"type:varchar(255)" json:"cred_id"`
+	Email          string `gorm:"type:varchar(255);index" json:"email"`
+	Username       string `gorm:"type:varchar(255)" json:"username"`
+	Password       string `gorm:"type:varchar(255)" json:"password"`
+	HashedPassword string `gorm:"type:varchar(255)" json:"hashed_password"`
+	Name           string `gorm:"type:varchar(255)" json:"name"`
+	Phone          string `gorm:"type:varchar(64)" json:"phone"`
+	IPAddress      string `gorm:"type:varchar(64)" json:"ip_address"`
+	Source         string `gorm:"type:varchar(255)" json:"source"`
+	CredChecksum   string `gorm:"type:varchar(64);uniqueIndex:idx_cred_checksum" json:"checksum"`
+
+	ScanRefer   uint `json:"scan_id"`
+	TargetRefer uint `gorm:"uniqueIndex:idx_cred_checksum" json:"target_id"`
+}
+
+// upsertRecords insert the records, the ones already seen in the target are refreshed with the update columns
+func upsertRecords(records interface{}, total int, tracked bool, conflicts []string, updates []string) error {
+	if DB == nil || total == 0 {
+		return nil
+	}
+
+	var columns []clause.Column
+	for _, conflict := range conflicts {
+		columns = append(columns, clause.Column{Name: conflict})
+	}
+	assignments := clause.AssignmentColumns(append(updates, "updated_at"))
+	if tracked {
+		assignments = append(assignments, clause.AssignmentColumns([]string{"last_seen"})...)
+		// the scan_refer still holds the last scan that saw the record at this point
+		assignments = append(assignments, clause.Assignment{
+			Column: clause.Column{Name: "status"},
+			Value:  gorm.Expr("CASE WHEN status = ? AND scan_refer = excluded.scan_refer THEN ? ELSE ? END", StatusNew, StatusNew, StatusPresent),
+		}, clause.Assignment{
+			Column: clause.Column{Name: "reappeared_at"},
+			Value:  gorm.Expr("CASE WHEN status = ? THEN excluded.last_seen ELSE reappeared_at END", StatusDisappeared),
+		})
+	}
+	assignments = append(assignments, clause.AssignmentColumns([]string{"scan_refer"})...)
+
+	return DB.Clauses(clause.OnConflict{
+		Columns:   columns,
+		DoUpdates: assignments,
+	}).CreateInBatches(records, 200).Error
+}
+
+// ImportAssets store the hosts of the target
+func ImportAssets(assets []Asset) error {
+	return upsertRecords(&assets, len(assets), true, []string{"target_refer", "asset_value"}, nil)
+}
+
+// UpdateAssetTech store the hosts of the target along with their technologies
+func UpdateAssetTech(assets []Asset) error {
+	return upsertRecords(&assets, len(assets), true, []string{"target_refer", "asset_value"}, []string{"technology", "is_alive"})
+}
+
+// ImportDns store the DNS records of the target
+func ImportDns(records []Dns) error {
+	return upsertRecords(&records, len(records), false, []string{"target_refer", "dns_checksum"}, nil)
+}
+
+// ImportPorts store the open ports of the target
+func ImportPorts(ports []Port) error {
+	return upsertRecords(&ports, len(ports), true, []string{"target_refer", "ip_address", "port_id", "protocol"}, []string{"host", "state", "service", "product", "cpe"})
+}
+
+// ImportHTTP store the HTTP endpoints of the target
+func ImportHTTP(records []HTTP) error {
+	return upsertRecords(&records, len(records), true, []string{"target_refer", "url"},
+		[]string{"host", "title", "status_code", "content_length", "checksum", "redirect", "technology", "content_file"})
+}
+
+// UpdateHTTPScreenShot store the screenshot path of the HTTP endpoints
+func UpdateHTTPScreenShot(records []HTTP) error {
+	return upsertRecords(&records, len(records), true, []string{"target_refer", "url"}, []string{"screenshot"})
+}
+
+// ImportCredentials store the leaked credentials of the target
+func ImportCredentials(creds []Credential) error {
+	return upsertRecords(&creds, len(creds), false, []string{"target_refer", "cred_checksum"}, nil)
+}
+
+// GetAssets get all the hosts of the target
+func GetAssets(targetID uint) (assets []Asset, err error) {
+	if DB == nil {
+		return assets, nil
+	}
+	err = DB.Where("target_refer = ?", targetID).Order("asset_value").Find(&assets).Error
+	return assets, err
+}
+
+// GetAssetCounters count the records of each asset type of the target, the disappeared ones are excluded
+func GetAssetCounters(targetID uint) (counters map[string]int, err error) {
+	counters = make(map[string]int)
+	if DB == nil {
+		return counters, nil
+	}
+
+	models := []struct {
+		name    string
+		model   interface{}
+		tracked bool
+	}{
+		{"hosts", &Asset{}, true},
+		{"dns", &Dns{}, false},
+		{"ports", &Port{}, true},
+		{"http", &HTTP{}, true},
+		{"findings", &Finding{}, true},
+		{"credentials", &Credential{}, false},
+	}
+	for _, item := range models {
+		var total int64
+		tx := DB.Model(item.model).Where("target_refer = ?", targetID)
+		if item.tracked {
+			tx = tx.Where("status <> ?", StatusDisappeared)
+		}
+		if err := tx.Count(&total).Error; err != nil {
+			return counters, err
+		}
+		counters[item.name] = int(total)
+	}
+	return counters, nil
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"errors"
+	"time"
+)
+
+// the sources of the audit entries
+const (
+	AuditSourceAPI = "api"
+	AuditSourceCLI = "cli"
+)
+
+// AuditLog the record of the action done through the API or the CLI
+// the entries are append-only, there is no function to update or delete them
+type AuditLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	Source    string    `gorm:"type:varchar(255)" json:"source"`
+	Actor     string    `gorm:"type:varchar(255);index" json:"actor"`
+	SourceIP  string    `gorm:"type:varchar(255)" json:"source_ip"`
+	Action    string    `gorm:"type:varchar(255);index" json:"action"`
+	Target    string    `gorm:"type:varchar(255)" json:"target,omitempty"`
+	Workspace string    `gorm:"type:varchar(255)" json:"workspace,omitempty"`
+	Params    string    `gorm:"type:text" json:"params,omitempty"`
+	Status    int       `json:"status,omitempty"`
+	Result    string    `gorm:"type:varchar(255)" json:"result"`
+	Error     string    `gorm:"type:text" json:"error,omitempty"`
+}
+
+// AuditFilter the conditions to query the audit entries, the zero values are ignored
+type AuditFilter struct {
+	Since  time.Time
+	Until  time.Time
+	Actor  string
+	Action string
+	Limit  int
+	Offset int
+}
+
+// Match the entry satisfies the conditions of the filter
+func (f AuditFilter) Match(entry AuditLog) bool {
+	if !f.Since.IsZero() && entry.CreatedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && entry.CreatedAt.After(f.Until) {
+		return false
+	}
+	if f.Actor != "" && entry.Actor != f.Actor {
+		return false
+	}
+	if f.Action != "" && entry.Action != f.Action {
+		return false
+	}
+	return true
+}
+
+// RecordAudit append the entry to the audit log
+func RecordAudit(entry *AuditLog) error {
+	if DB == nil {
+		return errors.New("database is not initialized")
+	}
+	entry.ID = 0
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	entry.CreatedAt = entry.CreatedAt.In(time.Local)
+	return DB.Create(entry).Error
+}
+
+// QueryAudit get the entries matching the filter, the newest first, with the total number of the matching entries
+func QueryAudit(filter AuditFilter) (entries []AuditLog, total int64, err error) {
+	if DB == nil {
+		return entries, 0, errors.New("database is not initialized")
+	}
+	query := DB.Model(&AuditLog{})
+	// the time is stored as the text in the local zone, so the range has to be in the same zone to be compared
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since.In(time.Local))
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at <= ?", filter.Until.In(time.Local))
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if err = query.Count(&total).Error; err != nil {
+		return entries, total, err
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	err = query.Order("created_at desc, id desc").Find(&entries).Error
+	return entries, total, err
+}
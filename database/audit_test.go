@@ -0,0 +1,40 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueryAudit(t *testing.T) {
+	initTestDB(t)
+
+	now := time.Now()
+	RecordAudit(&AuditLog{Actor: "alice", Action: "POST /api/osmp/execute", Result: "success", CreatedAt: now.Add(-2 * time.Hour)})
+	RecordAudit(&AuditLog{Actor: "bob", Action: "DELETE /api/osmp/delete/:wsname/", Result: "success", CreatedAt: now.Add(-time.Hour)})
+	RecordAudit(&AuditLog{Actor: "alice", Action: "cli scan", Result: "failed", CreatedAt: now})
+
+	entries, total, err := QueryAudit(AuditFilter{Actor: "alice"})
+	if err != nil || total != 2 {
+		t.Fatalf("Error QueryAudit by actor: %v %v", total, err)
+	}
+	if entries[0].Action != "cli scan" {
+		t.Errorf("Error the newest entry should be first: %v", entries[0].Action)
+	}
+
+	entries, total, _ = QueryAudit(AuditFilter{Since: now.Add(-90 * time.Minute), Until: now.Add(-30 * time.Minute)})
+	if total != 1 || entries[0].Actor != "bob" {
+		t.Errorf("Error QueryAudit by time range: %v", total)
+	}
+
+	// the range given in another zone, e.g. parsed from RFC3339 with an offset
+	zone := time.FixedZone("UTC+5", 5*3600)
+	entries, total, _ = QueryAudit(AuditFilter{Since: now.Add(-90 * time.Minute).In(zone), Until: now.Add(-30 * time.Minute).In(zone)})
+	if total != 1 || entries[0].Actor != "bob" {
+		t.Errorf("Error QueryAudit by time range in another zone: %v", total)
+	}
+
+	entries, total, _ = QueryAudit(AuditFilter{Limit: 1, Offset: 1})
+	if total != 3 || len(entries) != 1 || entries[0].Actor != "bob" {
+		t.Errorf("Error QueryAudit with limit and offset: %v %v", total, len(entries))
+	}
+}
@@ -0,0 +1,164 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// the status of an asset across the scans of the target
+const (
+	StatusNew         = "new"
+	StatusPresent     = "present"
+	StatusDisappeared = "disappeared"
+)
+
+// Tracking keep track of when an asset was seen across the scans
+type Tracking struct {
+	FirstSeen time.Time `gorm:"index" json:"first_seen"`
+	LastSeen  time.Time `gorm:"index" json:"last_seen"`
+	Status    string    `gorm:"type:varchar(16);index;default:'new'" json:"status"`
+	// the last time the disappeared asset was seen again
+	ReappearedAt *time.Time `gorm:"index" json:"reappeared_at,omitempty"`
+}
+
+// BeforeCreate set the first seen and last seen time of the new record
+func (t *Tracking) BeforeCreate(_ *gorm.DB) error {
+	now := time.Now()
+	if t.FirstSeen.IsZero() {
+		t.FirstSeen = now
+	}
+	t.LastSeen = now
+	if t.Status == "" {
+		t.Status = StatusNew
+	}
+	return nil
+}
+
+// MarkDisappeared mark the assets that were not seen in the scan as disappeared
+// only the asset types imported by the scan are checked, so a flow that skip the port scan won't wipe out the ports
+func MarkDisappeared(targetID uint, scanID uint) error {
+	if DB == nil || targetID == 0 || scanID == 0 {
+		return nil
+	}
+
+	for _, model := range []interface{}{&Asset{}, &Port{}, &HTTP{}, &Finding{}} {
+		var seen int64
+		if err := DB.Model(model).Where("target_refer = ? AND scan_refer = ?", targetID, scanID).Count(&seen).Error; err != nil {
+			return err
+		}
+		if seen == 0 {
+			continue
+		}
+
+		err := DB.Model(model).
+			Where("target_refer = ? AND scan_refer <> ? AND status <> ?", targetID, scanID, StatusDisappeared).
+			Update("status", StatusDisappeared).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ChangeSet the assets appeared and vanished in a time window
+type ChangeSet struct {
+	Appeared []string `json:"appeared"`
+	Vanished []string `json:"vanished"`
+}
+
+// Changes summary of what changed in the assets of a target
+type Changes struct {
+	Workspace string    `json:"workspace"`
+	Since     time.Time `json:"since"`
+	Until     time.Time `json:"until"`
+	Hosts     ChangeSet `json:"hosts"`
+	Ports     ChangeSet `json:"ports"`
+	URLs      ChangeSet `json:"urls"`
+	Findings  ChangeSet `json:"findings"`
+}
+
+// Total number of changes
+func (c Changes) Total() int {
+	total := 0
+	for _, set := range []ChangeSet{c.Hosts, c.Ports, c.URLs, c.Findings} {
+		total += len(set.Appeared) + len(set.Vanished)
+	}
+	return total
+}
+
+// GetChanges get the assets of the workspace appeared or vanished between since and until
+// the latest scan of the workspace is used as the start time if since is zero
+func GetChanges(wsName string, since time.Time, until time.Time) (changes Changes, err error) {
+	target, err := GetTargetByWorkspace(wsName)
+	if err != nil {
+		return changes, err
+	}
+
+	if since.IsZero() {
+		scan, err := GetLatestScan(wsName)
+		if err != nil {
+			return changes, fmt.Errorf("no scan found in workspace %v", wsName)
+		}
+		since = scan.CreatedAt
+	}
+	if until.IsZero() {
+		until = time.Now()
+	}
+	changes.Workspace = wsName
+	changes.Since = since
+	changes.Until = until
+
+	var hosts []Asset
+	if err = changedRecords(&hosts, target.ID, since, until); err != nil {
+		return changes, err
+	}
+	for _, host := range hosts {
+		changes.Hosts.add(host.Tracking, host.AssetValue)
+	}
+
+	var ports []Port
+	if err = changedRecords(&ports, target.ID, since, until); err != nil {
+		return changes, err
+	}
+	for _, port := range ports {
+		changes.Ports.add(port.Tracking, fmt.Sprintf("%s:%d/%s", port.IPAddress, port.PortID, port.Protocol))
+	}
+
+	var records []HTTP
+	if err = changedRecords(&records, target.ID, since, until); err != nil {
+		return changes, err
+	}
+	for _, record := range records {
+		changes.URLs.add(record.Tracking, record.URL)
+	}
+
+	var findings []Finding
+	if err = changedRecords(&findings, target.ID, since, until); err != nil {
+		return changes, err
+	}
+	for _, finding := range findings {
+		changes.Findings.add(finding.Tracking, fmt.Sprintf("[%s] %s %s", finding.Severity, finding.CheckID, finding.MatchedAt))
+	}
+
+	return changes, nil
+}
+
+func (s *ChangeSet) add(tracking Tracking, value string) {
+	if tracking.Status == StatusDisappeared {
+		s.Vanished = append(s.Vanished, value)
+		return
+	}
+	s.Appeared = append(s.Appeared, value)
+}
+
+// changedRecords get the records first seen or reappeared in the window and still there
+// or the ones marked as disappeared in the window (the updated time is when it was marked)
+func changedRecords(records interface{}, targetID uint, since time.Time, until time.Time) error {
+	return DB.Where("target_refer = ?", targetID).
+		Where(DB.Where("status <> ? AND first_seen BETWEEN ? AND ?", StatusDisappeared, since, until).
+			Or("status <> ? AND reappeared_at BETWEEN ? AND ?", StatusDisappeared, since, until).
+			Or("status = ? AND updated_at BETWEEN ? AND ?", StatusDisappeared, since, until)).
+		Order("id").Find(records).Error
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAssetChanges(t *testing.T) {
+	initTestDB(t)
+
+	target := Target{InputName: "example.com", Workspace: "example.com"}
+	SaveTarget(&target)
+
+	first := Scan{InputName: "example.com", Target: target}
+	SaveScan(&first)
+	ImportAssets([]Asset{
+		{AssetValue: "a.example.com", ScanRefer: first.ID, TargetRefer: target.ID},
+		{AssetValue: "b.example.com", ScanRefer: first.ID, TargetRefer: target.ID},
+	})
+	ImportPorts([]Port{{IPAddress: "1.2.3.4", PortID: 22, Protocol: "tcp", ScanRefer: first.ID, TargetRefer: target.ID}})
+	if err := MarkDisappeared(target.ID, first.ID); err != nil {
+		t.Fatalf("Error MarkDisappeared: %v", err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	second := Scan{InputName: "example.com", Target: target}
+	SaveScan(&second)
+	ImportAssets([]Asset{
+		{AssetValue: "b.example.com", ScanRefer: second.ID, TargetRefer: target.ID},
+		{AssetValue: "c.example.com", ScanRefer: second.ID, TargetRefer: target.ID},
+		{AssetValue: "c.example.com", ScanRefer: second.ID, TargetRefer: target.ID},
+	})
+	MarkDisappeared(target.ID, second.ID)
+
+	assets, _ := GetAssets(target.ID)
+	status := make(map[string]string)
+	for _, asset := range assets {
+		status[asset.AssetValue] = asset.Status
+	}
+	if status["a.example.com"] != StatusDisappeared || status["b.example.com"] != StatusPresent || status["c.example.com"] != StatusNew {
+		t.Errorf("Error tracking asset status: %v", status)
+	}
+
+	changes, err := GetChanges("example.com", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Error GetChanges: %v", err)
+	}
+	if len(changes.Hosts.Appeared) != 1 || changes.Hosts.Appeared[0] != "c.example.com" {
+		t.Errorf("Error appeared hosts: %v", changes.Hosts.Appeared)
+	}
+	if len(changes.Hosts.Vanished) != 1 || changes.Hosts.Vanished[0] != "a.example.com" {
+		t.Errorf("Error vanished hosts: %v", changes.Hosts.Vanished)
+	}
+	// the second scan didn't scan the ports so they are kept as is
+	if len(changes.Ports.Vanished) != 0 {
+		t.Errorf("Error vanished ports: %v", changes.Ports.Vanished)
+	}
+
+	// the disappeared host seen again is reported as appeared
+	time.Sleep(10 * time.Millisecond)
+	third := Scan{InputName: "example.com", Target: target}
+	SaveScan(&third)
+	ImportAssets([]Asset{
+		{AssetValue: "a.example.com", ScanRefer: third.ID, TargetRefer: target.ID},
+		{AssetValue: "b.example.com", ScanRefer: third.ID, TargetRefer: target.ID},
+		{AssetValue: "c.example.com", ScanRefer: third.ID, TargetRefer: target.ID},
+	})
+	MarkDisappeared(target.ID, third.ID)
+	changes, _ = GetChanges("example.com", time.Time{}, time.Time{})
+	if len(changes.Hosts.Appeared) != 1 || changes.Hosts.Appeared[0] != "a.example.com" || len(changes.Hosts.Vanished) != 0 {
+		t.Errorf("Error the reappeared host should be appeared: %+v", changes.Hosts)
+	}
+	assets, _ = GetAssets(target.ID)
+	for _, asset := range assets {
+		if reappeared := asset.ReappearedAt != nil; reappeared != (asset.AssetValue == "a.example.com") || asset.Status != StatusPresent {
+			t.Errorf("Error tracking the reappeared host: %v %v %v", asset.AssetValue, asset.Status, asset.ReappearedAt)
+		}
+	}
+}
@@ -1,63 +1,100 @@
 package database
 
-// load driver
-
-// // DB gorm connector
-// var DB *gorm.DB
-
-// // InitDB connect to db
-// func InitDB(options libs.Options) (*gorm.DB, error) {
-// 	newLogger := logger.New(
-// 		log.New(ioutil.Discard, "\r\n", log.LstdFlags), // io writer
-// 		logger.Config{
-// 			SlowThreshold:             time.Second, // Slow SQL threshold
-// 			LogLevel:                  logger.Info, // Log level
-// 			IgnoreRecordNotFoundError: true,        // Ignore ErrRecordNotFound error for logger
-// 			Colorful:                  false,       // Disable color
-// 		},
-// 	)
-
-// 	config := gorm.Config{
-// 		SkipDefaultTransaction:                   false,
-// 		NamingStrategy:                           nil,
-// 		FullSaveAssociations:                     false,
-// 		Logger:                                   newLogger,
-// 		NowFunc:                                  nil,
-// 		DryRun:                                   false,
-// 		PrepareStmt:                              false,
-// 		DisableAutomaticPing:                     false,
-// 		DisableForeignKeyConstraintWhenMigrating: false,
-// 		DisableNestedTransaction:                 false,
-// 		AllowGlobalUpdate:                        false,
-// 		QueryFields:                              false,
-// 		CreateBatchSize:                          0,
-// 		ClauseBuilders:                           nil,
-// 		ConnPool:                                 nil,
-// 		Dialector:                                nil,
-// 		Plugins:                                  nil,
-// 	}
-
-// 	var err error
-// 	if options.Server.DBType == "mysql" {
-// 		utils.InforF("Connect Database at %v:%v", options.Server.DBHost, options.Server.DBPort)
-// 		utils.InforF("Use Database: %v", options.Server.DBName)
-// 		DB, err = gorm.Open(mysql.Open(options.Server.DBConnection), &config)
-// 	} else {
-// 		DB, err = gorm.Open(sqlite.Open(options.Server.DBPath), &config)
-// 	}
-
-// 	if err != nil {
-// 		fmt.Printf("Error Database connect at %v:%v -- %v\n", options.Server.DBHost, options.Server.DBPort, err)
-// 		return nil, err
-// 	}
-
-// 	// scanning data
-// 	// DB.AutoMigrate(&User{})
-// 	//DB.AutoMigrate(&Org{})
-// 	DB.AutoMigrate(&Target{})
-// 	DB.AutoMigrate(&Scan{})
-// 	DB.AutoMigrate(&Report{})
-// 	DB.AutoMigrate(&CloudInstance{})
-// 	// asset data
-// 	return DB, nil
-// }
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"time"
+
+	// pure Go sqlite driver so the binary can still be built with CGO_ENABLED=0
+	"github.com/glebarez/sqlite"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// DB gorm connector
+var DB *gorm.DB
+
+// InitDB connect to the embedded sqlite database
+func InitDB(options libs.Options) (*gorm.DB, error) {
+	if options.NoDB || options.Server.DBType == "none" {
+		return nil, nil
+	}
+
+	if options.Server.DBType == "mysql" {
+		return nil, fmt.Errorf("database type %v is no longer supported, please switch to sqlite", options.Server.DBType)
+	}
+
+	dbPath := utils.NormalizePath(options.Server.DBPath)
+	if dbPath == "" {
+		dbPath = path.Join(utils.NormalizePath(options.Env.RootFolder), "sqlite.db")
+	}
+	utils.MakeDir(path.Dir(dbPath))
+
+	logLevel := logger.Silent
+	if options.Debug {
+		logLevel = logger.Warn
+	}
+	newLogger := logger.New(
+		log.New(os.Stderr, "\r\n", log.LstdFlags), // io writer
+		logger.Config{
+			SlowThreshold:             time.Second, // Slow SQL threshold
+			LogLevel:                  logLevel,
+			IgnoreRecordNotFoundError: true, // Ignore ErrRecordNotFound error for logger
+			Colorful:                  false,
+		},
+	)
+
+	config := gorm.Config{
+		Logger: newLogger,
+	}
+
+	// the scan process and the api server share the same file so wait for the lock instead of failing
+	dsn := fmt.Sprintf("%s?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)", dbPath)
+	db, err := gorm.Open(sqlite.Open(dsn), &config)
+	if err != nil {
+		utils.ErrorF("Error connecting to the database at %v -- %v", dbPath, err)
+		return nil, err
+	}
+
+	// scanning data
+	err = db.AutoMigrate(
+		&Target{},
+		&Scan{},
+		&Report{},
+		&Schedule{},
+		&Job{},
+		&User{},
+		&APIToken{},
+		&AuditLog{},
+		// asset inventory
+		&Asset{},
+		&Dns{},
+		&Port{},
+		&HTTP{},
+		&Finding{},
+		&Credential{},
+	)
+	if err != nil {
+		utils.ErrorF("Error migrating the database at %v -- %v", dbPath, err)
+		return nil, err
+	}
+
+	utils.DebugF("Connected to the database at %v", dbPath)
+	DB = db
+	return DB, nil
+}
+
+// CloseDB close the underlying connection
+func CloseDB() {
+	if DB == nil {
+		return
+	}
+	if sqlDB, err := DB.DB(); err == nil {
+		sqlDB.Close()
+	}
+	DB = nil
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"path"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+func initTestDB(t *testing.T) libs.Options {
+	var opt libs.Options
+	opt.Server.DBPath = path.Join(t.TempDir(), "sqlite.db")
+	opt.Env.WorkspacesFolder = t.TempDir()
+	if _, err := InitDB(opt); err != nil {
+		t.Fatalf("Error InitDB: %v", err)
+	}
+	t.Cleanup(CloseDB)
+	return opt
+}
+
+func TestSaveScan(t *testing.T) {
+	opt := initTestDB(t)
+
+	target := Target{InputName: "example.com", Workspace: "example.com"}
+	if err := SaveTarget(&target); err != nil || target.ID == 0 {
+		t.Fatalf("Error SaveTarget: %v", err)
+	}
+
+	scan := Scan{InputName: "example.com", TaskName: "general", Target: target, IsRunning: true}
+	if err := SaveScan(&scan); err != nil || scan.TargetRefer != target.ID {
+		t.Fatalf("Error SaveScan: %v", err)
+	}
+
+	target.Reports = append(target.Reports, Report{ReportPath: "/tmp/example.com/subdomain.txt", Module: "subdomain"})
+	if err := SaveReports(&target, scan.ID); err != nil {
+		t.Fatalf("Error SaveReports: %v", err)
+	}
+	// same report path must not be duplicated
+	target.Reports = append(target.Reports, Report{ReportPath: "/tmp/example.com/subdomain.txt", Module: "subdomain"})
+	SaveReports(&target, scan.ID)
+
+	// a new target record in the same workspace is the same target
+	again := Target{InputName: "example.com", Workspace: "example.com"}
+	SaveTarget(&again)
+	if again.ID != target.ID {
+		t.Errorf("Error SaveTarget should reuse the record of the workspace")
+	}
+
+	scans := GetAllScan(opt)
+	if len(scans) != 1 {
+		t.Fatalf("Error GetAllScan: %v", len(scans))
+	}
+	if len(scans[0].Target.Reports) != 1 {
+		t.Errorf("Error report records: %v", len(scans[0].Target.Reports))
+	}
+
+	single := GetSingleScan("example.com", opt)
+	if single.ID != scan.ID || single.TaskName != "general" {
+		t.Errorf("Error GetSingleScan: %v", single.ID)
+	}
+}
+
+func TestImportWorkspaces(t *testing.T) {
+	opt := initTestDB(t)
+
+	scan := Scan{
+		InputName: "sample.com",
+		TaskName:  "general",
+		Target: Target{
+			InputName: "sample.com",
+			Workspace: "sample.com",
+			Reports:   []Report{{ReportPath: "/tmp/sample.com/vuln.txt", Module: "vulnscan"}},
+		},
+	}
+	scan.ID = 42
+	content, _ := jsoniter.MarshalToString(scan)
+	utils.MakeDir(path.Join(opt.Env.WorkspacesFolder, "sample.com"))
+	utils.WriteToFile(path.Join(opt.Env.WorkspacesFolder, "sample.com", "runtime"), content)
+
+	if imported := ImportWorkspaces(opt); imported != 1 {
+		t.Fatalf("Error ImportWorkspaces: %v", imported)
+	}
+	// import again should be a no-op
+	if imported := ImportWorkspaces(opt); imported != 0 {
+		t.Errorf("Error ImportWorkspaces imported twice: %v", imported)
+	}
+
+	dbScan, err := GetLatestScan("sample.com")
+	if err != nil {
+		t.Fatalf("Error GetLatestScan: %v", err)
+	}
+	if len(dbScan.Target.Reports) != 1 || dbScan.Target.Reports[0].Module != "vulnscan" {
+		t.Errorf("Error importing reports: %v", dbScan.Target.Reports)
+	}
+}
+
+func TestCountScans(t *testing.T) {
+	initTestDB(t)
+
+	target := Target{InputName: "example.com", Workspace: "example.com"}
+	SaveTarget(&target)
+	SaveScan(&Scan{InputName: "example.com", TaskName: "general", Target: target, IsRunning: true})
+	SaveScan(&Scan{InputName: "example.com", TaskName: "general", Target: target, IsDone: true})
+	SaveScan(&Scan{InputName: "example.com", TaskName: "general", Target: target, IsDone: true, IsError: true})
+	SaveScan(&Scan{InputName: "example.com", TaskName: "fast", Target: target, IsRunning: true, IsCancelled: true})
+
+	counts, err := CountScans()
+	if err != nil {
+		t.Fatalf("Error CountScans: %v", err)
+	}
+	got := make(map[string]int)
+	for _, count := range counts {
+		got[count.Flow+"/"+count.Status] = count.Total
+	}
+	for key, total := range map[string]int{"general/running": 1, "general/finished": 1, "general/failed": 1, "fast/cancelled": 1} {
+		if got[key] != total {
+			t.Errorf("Error CountScans %v: %v", key, got)
+		}
+	}
+}
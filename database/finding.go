@@ -0,0 +1,184 @@
+package database
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// the severity of the findings
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+	SeverityInfo     = "info"
+	SeverityUnknown  = "unknown"
+)
+
+// Finding an issue reported by the vulnerability scanners or the custom modules
+// the same issue reported again in later scans is the same record as they share the fingerprint
+type Finding struct {
+	Model
+	Tracking
+	CheckID     string `gorm:"type:varchar(255);index" json:"check_id"`
+	Title       string `gorm:"type:varchar(1024)" json:"title"`
+	Severity    string `gorm:"type:varchar(16);index" json:"severity"`
+	Confidence  string `gorm:"type:varchar(32)" json:"confidence"`
+	Host        string `gorm:"type:varchar(255);index" json:"host"`
+	MatchedAt   string `gorm:"type:varchar(2048)" json:"matched_at"`
+	Evidence    string `gorm:"type:longtext" json:"evidence"`
+	Request     string `gorm:"type:longtext" json:"request,omitempty"`
+	Response    string `gorm:"type:longtext" json:"response,omitempty"`
+	Source      string `gorm:"type:varchar(64)" json:"source"`
+	Fingerprint string `gorm:"type:varchar(64);uniqueIndex:idx_finding_fingerprint" json:"fingerprint"`
+
+	ScanRefer   uint `json:"scan_id"`
+	TargetRefer uint `gorm:"uniqueIndex:idx_finding_fingerprint" json:"target_id"`
+}
+
+// NormalizeSeverity map the severity from different tools to the same set of values
+func NormalizeSeverity(raw string) string {
+	severity := strings.ToLower(strings.TrimSpace(raw))
+	switch severity {
+	case "critical", "crit":
+		return SeverityCritical
+	case "high":
+		return SeverityHigh
+	case "medium", "moderate":
+		return SeverityMedium
+	case "low":
+		return SeverityLow
+	case "info", "informational", "information", "potential":
+		return SeverityInfo
+	}
+	return SeverityUnknown
+}
+
+// GenFingerprint generate a stable fingerprint of the finding
+// so the same issue is recognized across scans, the check ID is tool specific so
+// the same issue reported by two tools is kept as two findings
+func GenFingerprint(checkID string, matchedAt string) string {
+	return utils.GenHash(fmt.Sprintf("%s|%s", strings.ToLower(strings.TrimSpace(checkID)), normalizeLocation(matchedAt)))
+}
+
+// normalizeLocation lower the scheme and host, strip the default port, trailing slash and sort the query
+func normalizeLocation(raw string) string {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return strings.ToLower(strings.TrimSuffix(raw, "/"))
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port != "" && !(scheme == "http" && port == "80") && !(scheme == "https" && port == "443") {
+		host = host + ":" + port
+	}
+
+	query := u.Query()
+	var keys []string
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var params []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			params = append(params, key+"="+value)
+		}
+	}
+
+	location := fmt.Sprintf("%s://%s%s", scheme, host, strings.TrimSuffix(u.EscapedPath(), "/"))
+	if len(params) > 0 {
+		location += "?" + strings.Join(params, "&")
+	}
+	return location
+}
+
+// ImportFindings store the findings of the target, deduplicated by the fingerprint
+func ImportFindings(findings []Finding) error {
+	var records []Finding
+	seen := make(map[string]int)
+	for _, finding := range findings {
+		finding.Severity = NormalizeSeverity(finding.Severity)
+		if finding.Host == "" {
+			if u, err := url.Parse(finding.MatchedAt); err == nil && u.Host != "" {
+				finding.Host = u.Hostname()
+			} else {
+				finding.Host = strings.Split(finding.MatchedAt, ":")[0]
+			}
+		}
+		if finding.Fingerprint == "" {
+			finding.Fingerprint = GenFingerprint(finding.CheckID, finding.MatchedAt)
+		}
+
+		// keep the one with the highest severity if the same issue was reported twice in the same batch
+		if index, ok := seen[finding.Fingerprint]; ok {
+			if severityRanks[finding.Severity] > severityRanks[records[index].Severity] {
+				records[index] = finding
+			}
+			continue
+		}
+		seen[finding.Fingerprint] = len(records)
+		records = append(records, finding)
+	}
+
+	return upsertRecords(&records, len(records), true, []string{"target_refer", "fingerprint"},
+		[]string{"title", "severity", "confidence", "host", "evidence", "request", "response", "source"})
+}
+
+// GetFindings get the findings of the target which are still present
+func GetFindings(targetID uint) (findings []Finding, err error) {
+	if DB == nil {
+		return findings, nil
+	}
+	err = DB.Where("target_refer = ? AND status <> ?", targetID, StatusDisappeared).Order("id").Find(&findings).Error
+	return findings, err
+}
+
+// UpdateFindingCounters derive the counters of the target from its findings
+// the informational findings are not counted as a vulnerability
+func UpdateFindingCounters(target *Target) error {
+	if DB == nil || target.ID == 0 {
+		return nil
+	}
+
+	var rows []struct {
+		Severity string
+		Total    int
+	}
+	err := DB.Model(&Finding{}).Select("severity, count(*) as total").
+		Where("target_refer = ? AND status <> ?", target.ID, StatusDisappeared).
+		Group("severity").Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	target.TotalCritical, target.TotalHigh, target.TotalMedium, target.TotalLow, target.TotalInfo = 0, 0, 0, 0, 0
+	for _, row := range rows {
+		switch row.Severity {
+		case SeverityCritical:
+			target.TotalCritical = row.Total
+		case SeverityHigh:
+			target.TotalHigh = row.Total
+		case SeverityMedium:
+			target.TotalMedium = row.Total
+		case SeverityLow:
+			target.TotalLow = row.Total
+		default:
+			target.TotalInfo += row.Total
+		}
+	}
+	target.TotalVulnerability = target.TotalCritical + target.TotalHigh + target.TotalMedium + target.TotalLow
+	return nil
+}
+
+// SeverityRank the rank of the severity, higher is more severe
+func SeverityRank(severity string) int {
+	return severityRanks[NormalizeSeverity(severity)]
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"testing"
+)
+
+func TestGenFingerprint(t *testing.T) {
+	fingerprint := GenFingerprint("git-config", "https://Example.com:443/.git/config/?b=2&a=1")
+	if fingerprint != GenFingerprint("GIT-CONFIG", "https://example.com/.git/config?a=1&b=2") {
+		t.Errorf("Error GenFingerprint should be stable with the same location")
+	}
+	if fingerprint == GenFingerprint("git-config", "https://example.com:8443/.git/config?a=1&b=2") {
+		t.Errorf("Error GenFingerprint should be different with different port")
+	}
+}
+
+func TestImportFindings(t *testing.T) {
+	initTestDB(t)
+
+	target := Target{InputName: "example.com", Workspace: "example.com"}
+	SaveTarget(&target)
+
+	first := Scan{InputName: "example.com", Target: target}
+	SaveScan(&first)
+	ImportFindings([]Finding{
+		{CheckID: "git-config", MatchedAt: "https://example.com/.git/config", Severity: "Medium", Source: "nuclei", ScanRefer: first.ID, TargetRefer: target.ID},
+		{CheckID: "git-config", MatchedAt: "https://EXAMPLE.com/.git/config/", Severity: "high", Source: "custom", ScanRefer: first.ID, TargetRefer: target.ID},
+		{CheckID: "tech-detect", MatchedAt: "https://example.com", Severity: "informational", ScanRefer: first.ID, TargetRefer: target.ID},
+	})
+
+	second := Scan{InputName: "example.com", Target: target}
+	SaveScan(&second)
+	ImportFindings([]Finding{
+		{CheckID: "git-config", MatchedAt: "https://example.com/.git/config", Severity: "high", ScanRefer: second.ID, TargetRefer: target.ID},
+		{CheckID: "CVE-2021-44228", MatchedAt: "https://api.example.com/login", Severity: "critical", ScanRefer: second.ID, TargetRefer: target.ID},
+	})
+	MarkDisappeared(target.ID, second.ID)
+
+	findings, _ := GetFindings(target.ID)
+	if len(findings) != 2 {
+		t.Fatalf("Error ImportFindings dedup: %v", len(findings))
+	}
+	if findings[0].Host != "example.com" || findings[0].Severity != SeverityHigh || findings[0].Status != StatusPresent {
+		t.Errorf("Error ImportFindings: %v", findings[0])
+	}
+
+	if err := UpdateFindingCounters(&target); err != nil {
+		t.Fatalf("Error UpdateFindingCounters: %v", err)
+	}
+	if target.TotalVulnerability != 2 || target.TotalCritical != 1 || target.TotalHigh != 1 || target.TotalInfo != 0 {
+		t.Errorf("Error UpdateFindingCounters: %v/%v/%v", target.TotalVulnerability, target.TotalCritical, target.TotalHigh)
+	}
+}
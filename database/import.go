@@ -0,0 +1,92 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// ImportWorkspaces import the runtime file of existing workspaces to the database
+// all workspaces will be imported if no workspace name was given
+func ImportWorkspaces(opt libs.Options, workspaces ...string) (imported int) {
+	if DB == nil {
+		utils.WarnF("Database is not initialized, skip importing workspaces")
+		return imported
+	}
+
+	if len(workspaces) == 0 {
+		workspaces = GetAllWorkspaces(opt)
+	}
+
+	for _, wsName := range workspaces {
+		runtimeFile := filepath.Join(opt.Env.WorkspacesFolder, wsName, "runtime")
+		if !utils.FileExists(runtimeFile) {
+			continue
+		}
+
+		ok, err := ImportRuntime(wsName, runtimeFile)
+		if err != nil {
+			utils.ErrorF("Error importing workspace %v -- %v", wsName, err)
+			continue
+		}
+		if ok {
+			utils.DebugF("Imported workspace: %v", wsName)
+			imported++
+		}
+	}
+	return imported
+}
+
+// ImportRuntime import a runtime file, return false if the scan was already imported before
+func ImportRuntime(wsName string, runtimeFile string) (bool, error) {
+	scan, err := ParseRuntimeFile(runtimeFile)
+	if err != nil {
+		return false, err
+	}
+
+	// IDs in the runtime file might come from another machine
+	target := scan.Target
+	target.ID = 0
+	if target.Workspace == "" {
+		target.Workspace = wsName
+	}
+	if target.InputName == "" {
+		target.InputName = scan.InputName
+	}
+	if target.InputName == "" {
+		return false, fmt.Errorf("missing input name in %v", runtimeFile)
+	}
+	for i := range target.Reports {
+		target.Reports[i].ID = 0
+	}
+
+	if err := SaveTarget(&target); err != nil {
+		return false, err
+	}
+
+	// the creation time is used to detect if the scan was imported before
+	if scan.CreatedAt.IsZero() {
+		if info, err := os.Stat(runtimeFile); err == nil {
+			scan.CreatedAt = info.ModTime()
+		}
+	}
+
+	var total int64
+	DB.Model(&Scan{}).Where("target_refer = ? AND created_at = ?", target.ID, scan.CreatedAt).Count(&total)
+	if total > 0 {
+		return false, nil
+	}
+
+	scan.ID = 0
+	scan.Target = target
+	if err := SaveScan(&scan); err != nil {
+		return false, err
+	}
+	if err := SaveReports(&target, scan.ID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
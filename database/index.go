@@ -0,0 +1,353 @@
+package database
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// the statuses of the scan used to filter and sort the workspaces
+const (
+	ScanStatusPending   = "pending"
+	ScanStatusRunning   = "running"
+	ScanStatusPaused    = "paused"
+	ScanStatusFinished  = "finished"
+	ScanStatusFailed    = "failed"
+	ScanStatusCancelled = "cancelled"
+)
+
+const (
+	// indexFlushInterval how often the changed workspaces are loaded again, the runtime file is written many times per scan
+	indexFlushInterval = time.Second
+	// indexResyncInterval the whole index is rebuilt this often in case some events were dropped
+	indexResyncInterval = 10 * time.Minute
+)
+
+// ScanStatus the status of the scan, it's the same as the status used by CountScans
+func ScanStatus(scan Scan) string {
+	switch {
+	case scan.IsCancelled:
+		return ScanStatusCancelled
+	case scan.IsError:
+		return ScanStatusFailed
+	case scan.IsDone:
+		return ScanStatusFinished
+	case scan.IsPaused:
+		return ScanStatusPaused
+	case scan.IsRunning:
+		return ScanStatusRunning
+	}
+	return ScanStatusPending
+}
+
+// WorkspaceQuery the filters, the sorting and the page of the workspaces, the zero values are ignored
+type WorkspaceQuery struct {
+	Flow   string
+	Status string
+	// case insensitive search in the workspace, the input and the flow
+	Search string
+	// date, name, status or findings, default is date
+	Sort string
+	Desc bool
+	// no limit means all the workspaces
+	Offset int
+	Limit  int
+}
+
+// Match the scan satisfies the filters of the query
+func (q WorkspaceQuery) Match(scan Scan) bool {
+	if q.Flow != "" && scan.TaskName != q.Flow {
+		return false
+	}
+	if q.Status != "" && ScanStatus(scan) != q.Status {
+		return false
+	}
+	if q.Search != "" {
+		search := strings.ToLower(q.Search)
+		if !strings.Contains(strings.ToLower(scan.Target.Workspace), search) &&
+			!strings.Contains(strings.ToLower(scan.InputName), search) &&
+			!strings.Contains(strings.ToLower(scan.TaskName), search) {
+			return false
+		}
+	}
+	return true
+}
+
+// less compare the scans with the sort field of the query, ascending
+func (q WorkspaceQuery) less(a Scan, b Scan) bool {
+	switch q.Sort {
+	case "name":
+		return workspaceName(a) < workspaceName(b)
+	case "status":
+		return ScanStatus(a) < ScanStatus(b)
+	case "findings":
+		return a.Target.TotalVulnerability < b.Target.TotalVulnerability
+	}
+	return a.UpdatedAt.Before(b.UpdatedAt)
+}
+
+// Apply filter, sort and paginate the scans, the total number of the matching scans is returned too
+func (q WorkspaceQuery) Apply(scans []Scan) (results []Scan, total int) {
+	for _, scan := range scans {
+		if q.Match(scan) {
+			results = append(results, scan)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if q.Desc {
+			return q.less(results[j], results[i])
+		}
+		return q.less(results[i], results[j])
+	})
+
+	total = len(results)
+	if q.Offset > 0 {
+		if q.Offset >= len(results) {
+			return []Scan{}, total
+		}
+		results = results[q.Offset:]
+	}
+	if q.Limit > 0 && len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return results, total
+}
+
+// workspaceName the name of the workspace of the scan, the input is used if the target is missing
+func workspaceName(scan Scan) string {
+	if scan.Target.Workspace != "" {
+		return scan.Target.Workspace
+	}
+	return utils.CleanPath(scan.InputName)
+}
+
+// WorkspaceIndex the latest scan of every workspace kept in memory
+// it's updated from the runtime files with fsnotify so the scans of the other processes show up too
+type WorkspaceIndex struct {
+	opt libs.Options
+
+	mu    sync.RWMutex
+	scans map[string]Scan
+
+	dirtyMu sync.Mutex
+	dirty   map[string]bool
+
+	watcher *fsnotify.Watcher
+}
+
+var (
+	workspaceIndex   *WorkspaceIndex
+	workspaceIndexMu sync.Mutex
+)
+
+// StartWorkspaceIndex build the index of the workspaces folder and keep it fresh until the process exits
+func StartWorkspaceIndex(opt libs.Options) *WorkspaceIndex {
+	workspaceIndexMu.Lock()
+	defer workspaceIndexMu.Unlock()
+	if workspaceIndex != nil {
+		return workspaceIndex
+	}
+
+	index := NewWorkspaceIndex(opt)
+	index.Rebuild()
+	if err := index.Watch(); err != nil {
+		utils.WarnF("Error watching the workspaces folder, the index is only rebuilt every %v: %v", indexResyncInterval, err)
+	}
+	go index.run()
+	workspaceIndex = index
+	return index
+}
+
+// CurrentWorkspaceIndex the index started by StartWorkspaceIndex, nil if it's not started
+func CurrentWorkspaceIndex() *WorkspaceIndex {
+	workspaceIndexMu.Lock()
+	defer workspaceIndexMu.Unlock()
+	return workspaceIndex
+}
+
+// NewWorkspaceIndex create the empty index of the workspaces folder
+func NewWorkspaceIndex(opt libs.Options) *WorkspaceIndex {
+	return &WorkspaceIndex{
+		opt:   opt,
+		scans: make(map[string]Scan),
+		dirty: make(map[string]bool),
+	}
+}
+
+// Rebuild load all the workspaces again
+func (w *WorkspaceIndex) Rebuild() {
+	var all []Scan
+	if DB != nil {
+		all = getAllScanFromDB(w.opt)
+	}
+	scans := make(map[string]Scan)
+	for _, scan := range all {
+		scans[workspaceName(scan)] = scan
+	}
+	// the workspaces which are not in the database yet
+	for _, wsName := range GetAllWorkspaces(w.opt) {
+		if _, ok := scans[wsName]; ok {
+			continue
+		}
+		if scan, err := w.parseWorkspace(wsName); err == nil {
+			scans[wsName] = scan
+		}
+	}
+
+	w.mu.Lock()
+	w.scans = scans
+	w.mu.Unlock()
+}
+
+func (w *WorkspaceIndex) parseWorkspace(wsName string) (Scan, error) {
+	scan, err := ParseRuntimeFile(filepath.Join(w.opt.Env.WorkspacesFolder, wsName, "runtime"))
+	if err != nil {
+		return scan, err
+	}
+	if scan.Target.Workspace == "" {
+		scan.Target.Workspace = wsName
+	}
+	return staticScanPath(scan, w.opt), nil
+}
+
+// Refresh load the workspace again from its runtime file, it's removed from the index if the workspace is gone
+func (w *WorkspaceIndex) Refresh(wsName string) {
+	scan, err := w.parseWorkspace(wsName)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		if !utils.FolderExists(filepath.Join(w.opt.Env.WorkspacesFolder, wsName)) {
+			delete(w.scans, wsName)
+		}
+		return
+	}
+	w.scans[wsName] = scan
+}
+
+// Remove drop the workspace from the index
+func (w *WorkspaceIndex) Remove(wsName string) {
+	w.mu.Lock()
+	delete(w.scans, wsName)
+	w.mu.Unlock()
+}
+
+// All the latest scan of every workspace, the newest first
+func (w *WorkspaceIndex) All() []Scan {
+	scans, _ := w.Query(WorkspaceQuery{Desc: true})
+	return scans
+}
+
+// Query filter, sort and paginate the workspaces
+func (w *WorkspaceIndex) Query(query WorkspaceQuery) ([]Scan, int) {
+	w.mu.RLock()
+	scans := make([]Scan, 0, len(w.scans))
+	for _, scan := range w.scans {
+		scans = append(scans, scan)
+	}
+	w.mu.RUnlock()
+	return query.Apply(scans)
+}
+
+// Len the number of the workspaces in the index
+func (w *WorkspaceIndex) Len() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return len(w.scans)
+}
+
+// Watch watch the workspaces folder and every workspace in it
+func (w *WorkspaceIndex) Watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	utils.MakeDir(w.opt.Env.WorkspacesFolder)
+	if err := watcher.Add(w.opt.Env.WorkspacesFolder); err != nil {
+		watcher.Close()
+		return err
+	}
+	for _, wsName := range GetAllWorkspaces(w.opt) {
+		wsDir := filepath.Join(w.opt.Env.WorkspacesFolder, wsName)
+		if utils.FolderExists(wsDir) {
+			watcher.Add(wsDir)
+		}
+	}
+	w.watcher = watcher
+	return nil
+}
+
+// handleEvent mark the workspace of the event as changed, the new workspaces are watched too
+func (w *WorkspaceIndex) handleEvent(event fsnotify.Event) {
+	rel, err := filepath.Rel(w.opt.Env.WorkspacesFolder, event.Name)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return
+	}
+	parts := strings.Split(rel, string(filepath.Separator))
+	wsName := parts[0]
+	switch {
+	case len(parts) == 1:
+		// the workspace itself is created, removed or renamed
+		if event.Has(fsnotify.Create) && utils.FolderExists(event.Name) {
+			w.watcher.Add(event.Name)
+		}
+	case parts[1] != "runtime":
+		return
+	}
+
+	w.dirtyMu.Lock()
+	w.dirty[wsName] = true
+	w.dirtyMu.Unlock()
+}
+
+// flush load the changed workspaces again
+func (w *WorkspaceIndex) flush() {
+	w.dirtyMu.Lock()
+	dirty := w.dirty
+	w.dirty = make(map[string]bool)
+	w.dirtyMu.Unlock()
+	for wsName := range dirty {
+		w.Refresh(wsName)
+	}
+}
+
+func (w *WorkspaceIndex) run() {
+	flush := time.NewTicker(indexFlushInterval)
+	defer flush.Stop()
+	resync := time.NewTicker(indexResyncInterval)
+	defer resync.Stop()
+
+	var events chan fsnotify.Event
+	var errs chan error
+	if w.watcher != nil {
+		events = w.watcher.Events
+		errs = w.watcher.Errors
+	}
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			w.handleEvent(event)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			// the events could be dropped when the queue overflows so the index is rebuilt
+			utils.DebugF("Error watching the workspaces: %v", err)
+			w.Rebuild()
+		case <-flush.C:
+			w.flush()
+		case <-resync.C:
+			w.Rebuild()
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+func TestWorkspaceQuery(t *testing.T) {
+	now := time.Now()
+	scans := []Scan{
+		{InputName: "a.com", TaskName: "general", IsDone: true, Target: Target{Workspace: "a.com", TotalVulnerability: 3}},
+		{InputName: "b.com", TaskName: "fast", IsRunning: true, Target: Target{Workspace: "b.com", TotalVulnerability: 10}},
+		{InputName: "c.org", TaskName: "general", IsRunning: true, Target: Target{Workspace: "c.org"}},
+	}
+	for i := range scans {
+		scans[i].UpdatedAt = now.Add(time.Duration(i) * time.Minute)
+	}
+
+	results, total := WorkspaceQuery{Flow: "general", Desc: true}.Apply(scans)
+	if total != 2 || results[0].InputName != "c.org" {
+		t.Errorf("Error filter by flow: %v", total)
+	}
+	results, total = WorkspaceQuery{Status: ScanStatusRunning, Search: "B.C"}.Apply(scans)
+	if total != 1 || results[0].InputName != "b.com" {
+		t.Errorf("Error filter by status and search: %v", total)
+	}
+	results, _ = WorkspaceQuery{Sort: "findings", Desc: true}.Apply(scans)
+	if results[0].InputName != "b.com" || results[2].InputName != "c.org" {
+		t.Errorf("Error sort by findings: %v", results[0].InputName)
+	}
+	results, total = WorkspaceQuery{Sort: "name", Offset: 1, Limit: 1}.Apply(scans)
+	if total != 3 || len(results) != 1 || results[0].InputName != "b.com" {
+		t.Errorf("Error paginate: %v %v", total, len(results))
+	}
+	if results, _ = (WorkspaceQuery{Offset: 5}).Apply(scans); len(results) != 0 {
+		t.Errorf("Error offset past the end: %v", len(results))
+	}
+}
+
+func TestWorkspaceIndex(t *testing.T) {
+	var opt libs.Options
+	opt.Env.WorkspacesFolder = t.TempDir()
+	writeRuntime := func(wsName string, scan Scan) {
+		utils.MakeDir(filepath.Join(opt.Env.WorkspacesFolder, wsName))
+		data, _ := jsoniter.MarshalToString(scan)
+		os.WriteFile(filepath.Join(opt.Env.WorkspacesFolder, wsName, "runtime"), []byte(data), 0644)
+	}
+	writeRuntime("a.com", Scan{InputName: "a.com", TaskName: "general", IsDone: true})
+
+	index := NewWorkspaceIndex(opt)
+	index.Rebuild()
+	if index.Len() != 1 {
+		t.Fatalf("Error Rebuild: %v", index.Len())
+	}
+	if err := index.Watch(); err != nil {
+		t.Skipf("fsnotify is not available: %v", err)
+	}
+	go index.run()
+
+	waitFor := func(check func() bool) bool {
+		for i := 0; i < 50; i++ {
+			if check() {
+				return true
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		return false
+	}
+
+	// the new workspace and the change of the runtime file are picked up
+	writeRuntime("b.com", Scan{InputName: "b.com", TaskName: "fast", IsRunning: true})
+	if !waitFor(func() bool { return index.Len() == 2 }) {
+		t.Fatalf("Error the new workspace is not indexed")
+	}
+	writeRuntime("b.com", Scan{InputName: "b.com", TaskName: "fast", IsDone: true})
+	if !waitFor(func() bool {
+		scans, _ := index.Query(WorkspaceQuery{Status: ScanStatusFinished})
+		return len(scans) == 2
+	}) {
+		t.Errorf("Error the updated runtime file is not indexed")
+	}
+
+	os.RemoveAll(filepath.Join(opt.Env.WorkspacesFolder, "a.com"))
+	if !waitFor(func() bool { return index.Len() == 1 }) {
+		t.Errorf("Error the removed workspace is still indexed")
+	}
+}
@@ -0,0 +1,328 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/whoamikiddie/vulnx/utils"
+	"gorm.io/gorm"
+)
+
+// the states of the job in the queue
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobDone      = "done"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// DefaultJobAttempts the job is marked as failed after that
+const DefaultJobAttempts = 3
+
+// Job a scan waiting in the queue or processed by a worker
+type Job struct {
+	Model
+
+	Input       string   `gorm:"type:longtext;not null" json:"input"`
+	InputAsFile bool     `json:"input_as_file"`
+	Flow        string   `gorm:"type:varchar(255)" json:"flow"`
+	Modules     []string `gorm:"serializer:json" json:"modules"`
+	Params      []string `gorm:"serializer:json" json:"params"`
+	Workspace   string   `gorm:"type:varchar(255)" json:"workspace"`
+	// raw command with the {{.input}} placeholder, only accepted from the CLI
+	Command        string `gorm:"type:longtext" json:"command,omitempty"`
+	CallbackURL    string `gorm:"type:longtext" json:"callback_url,omitempty"`
+	CallbackSecret string `gorm:"type:varchar(255)" json:"-"`
+
+	// the identical target+flow is not queued twice while it's pending or running
+	DedupKey string `gorm:"type:varchar(64);index" json:"dedup_key"`
+	// higher priority is picked first
+	Priority    int    `gorm:"index" json:"priority"`
+	State       string `gorm:"type:varchar(32);index;default:'pending'" json:"state"`
+	Attempts    int    `json:"attempts"`
+	MaxAttempts int    `json:"max_attempts"`
+	LastError   string `gorm:"type:longtext" json:"last_error,omitempty"`
+
+	// the process which is running the job
+	Worker    string     `gorm:"type:varchar(255)" json:"worker,omitempty"`
+	Hostname  string     `gorm:"type:varchar(255)" json:"hostname,omitempty"`
+	ProcessID int        `json:"process_id,omitempty"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	DoneAt    *time.Time `json:"done_at,omitempty"`
+	// the remote worker has to send the heartbeat before the lease expires or the job is re-queued
+	LeaseExpires *time.Time `gorm:"index" json:"lease_expires,omitempty"`
+}
+
+// ErrJobLost the job is no longer running by the worker, it was cancelled or re-queued
+var ErrJobLost = errors.New("job is no longer leased by the worker")
+
+// JobDedupKey the key of the target+flow pair, the modules are part of it when running modules directly
+func JobDedupKey(job Job) string {
+	modules := append([]string{}, job.Modules...)
+	sort.Strings(modules)
+	return utils.GenHash(strings.Join([]string{strings.TrimSpace(job.Input), job.Flow, strings.Join(modules, ","), job.Workspace, job.Command}, "|"))
+}
+
+// EnqueueJob add the job to the queue
+// if the same target+flow is already pending or running the existing job is returned and created is false
+func EnqueueJob(job *Job) (created bool, err error) {
+	if DB == nil {
+		return false, errors.New("database is not initialized")
+	}
+	if strings.TrimSpace(job.Input) == "" {
+		return false, errors.New("input is empty")
+	}
+	job.DedupKey = JobDedupKey(*job)
+	job.State = JobPending
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = DefaultJobAttempts
+	}
+
+	err = DB.Transaction(func(tx *gorm.DB) error {
+		var existing Job
+		err := tx.Where("dedup_key = ? AND state IN ?", job.DedupKey, []string{JobPending, JobRunning}).First(&existing).Error
+		if err == nil {
+			*job = existing
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		created = true
+		return tx.Create(job).Error
+	})
+	return created, err
+}
+
+// ClaimJob pick the pending job with the highest priority and mark it as running by the worker
+// the job is leased for the duration if it's set, it returns nil if there is no pending job
+func ClaimJob(worker string, hostname string, pid int, lease time.Duration) (*Job, error) {
+	if DB == nil {
+		return nil, errors.New("database is not initialized")
+	}
+	for {
+		var job Job
+		err := DB.Where("state = ?", JobPending).Order("priority desc, id asc").First(&job).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		var leaseExpires *time.Time
+		if lease > 0 {
+			expires := now.Add(lease)
+			leaseExpires = &expires
+		}
+		// only one worker could move the job out of the pending state
+		result := DB.Model(&Job{}).Where("id = ? AND state = ?", job.ID, JobPending).Updates(map[string]interface{}{
+			"state":         JobRunning,
+			"attempts":      gorm.Expr("attempts + 1"),
+			"worker":        worker,
+			"hostname":      hostname,
+			"process_id":    pid,
+			"started_at":    now,
+			"done_at":       nil,
+			"lease_expires": leaseExpires,
+			"updated_at":    now,
+		})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		return GetJob(job.ID)
+	}
+}
+
+// HeartbeatJob extend the lease of the job running by the worker
+func HeartbeatJob(id uint, worker string, lease time.Duration) error {
+	if DB == nil {
+		return errors.New("database is not initialized")
+	}
+	now := time.Now()
+	result := DB.Model(&Job{}).Where("id = ? AND state = ? AND worker = ?", id, JobRunning, worker).Updates(map[string]interface{}{
+		"lease_expires": now.Add(lease),
+		"updated_at":    now,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrJobLost
+	}
+	return nil
+}
+
+// RequeueExpiredJobs put the running jobs whose lease expired back to the queue
+// the job is marked as failed instead if it reaches the max attempts
+func RequeueExpiredJobs() (int, error) {
+	if DB == nil {
+		return 0, errors.New("database is not initialized")
+	}
+	var jobs []Job
+	if err := DB.Where("state = ? AND lease_expires IS NOT NULL AND lease_expires < ?", JobRunning, time.Now()).Find(&jobs).Error; err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, job := range jobs {
+		state := JobPending
+		if job.Attempts >= job.MaxAttempts {
+			state = JobFailed
+		}
+		err := DB.Model(&Job{}).Where("id = ? AND state = ?", job.ID, JobRunning).Updates(map[string]interface{}{
+			"state":         state,
+			"last_error":    fmt.Sprintf("the lease of worker %v expired", job.Worker),
+			"lease_expires": nil,
+			"updated_at":    time.Now(),
+		}).Error
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// FinishJob mark the running job as done, or failed when there is an error
+// the failed job goes back to the queue until it reaches the max attempts
+func FinishJob(id uint, jobErr error) error {
+	job, err := GetJob(id)
+	if err != nil {
+		return err
+	}
+	// the job was cancelled while running
+	if job.State != JobRunning {
+		return nil
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"state":         JobDone,
+		"last_error":    "",
+		"done_at":       now,
+		"lease_expires": nil,
+		"updated_at":    now,
+	}
+	if jobErr != nil {
+		updates["last_error"] = jobErr.Error()
+		updates["state"] = JobFailed
+		if job.Attempts < job.MaxAttempts {
+			updates["state"] = JobPending
+			updates["done_at"] = nil
+		}
+	}
+	return DB.Model(&Job{}).Where("id = ? AND state = ?", id, JobRunning).Updates(updates).Error
+}
+
+// GetJob get the job by its ID
+func GetJob(id uint) (*Job, error) {
+	if DB == nil {
+		return nil, errors.New("database is not initialized")
+	}
+	var job Job
+	if err := DB.First(&job, id).Error; err != nil {
+		return nil, fmt.Errorf("job %v not found: %v", id, err)
+	}
+	return &job, nil
+}
+
+// ListJobs get the jobs in the order they would be picked, filtered by the states if any
+func ListJobs(states []string, limit int) (jobs []Job, err error) {
+	if DB == nil {
+		return jobs, errors.New("database is not initialized")
+	}
+	query := DB.Model(&Job{})
+	if len(states) > 0 {
+		query = query.Where("state IN ?", states)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err = query.Order("CASE state WHEN 'running' THEN 0 WHEN 'pending' THEN 1 ELSE 2 END, priority desc, id asc").Find(&jobs).Error
+	return jobs, err
+}
+
+// CountJobs the number of jobs per state
+func CountJobs() (map[string]int, error) {
+	counts := make(map[string]int)
+	if DB == nil {
+		return counts, errors.New("database is not initialized")
+	}
+	var rows []struct {
+		State string
+		Total int
+	}
+	err := DB.Model(&Job{}).Select("state, count(*) as total").Group("state").Scan(&rows).Error
+	for _, row := range rows {
+		counts[row.State] = row.Total
+	}
+	return counts, err
+}
+
+// CancelJob cancel the pending or running job, the finished job is kept as is
+func CancelJob(id uint) (*Job, error) {
+	job, err := GetJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.State != JobPending && job.State != JobRunning {
+		return job, fmt.Errorf("job %v is already %v", id, job.State)
+	}
+	now := time.Now()
+	err = DB.Model(job).Updates(map[string]interface{}{"state": JobCancelled, "done_at": now, "updated_at": now}).Error
+	return job, err
+}
+
+// RetryJob put the failed, cancelled or done job back to the queue with a fresh attempt count
+func RetryJob(id uint) (*Job, error) {
+	job, err := GetJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.State == JobPending || job.State == JobRunning {
+		return job, fmt.Errorf("job %v is already %v", id, job.State)
+	}
+	var active int64
+	DB.Model(&Job{}).Where("dedup_key = ? AND state IN ? AND id <> ?", job.DedupKey, []string{JobPending, JobRunning}, id).Count(&active)
+	if active > 0 {
+		return job, fmt.Errorf("the same target and flow of job %v is already in the queue", id)
+	}
+	err = DB.Model(job).Updates(map[string]interface{}{"state": JobPending, "attempts": 0, "last_error": "", "done_at": nil, "updated_at": time.Now()}).Error
+	return job, err
+}
+
+// RecoverJobs put the running jobs of the dead processes on the host back to the queue
+// the process is considered dead when the alive check returns false, the leased jobs are left to RequeueExpiredJobs
+func RecoverJobs(hostname string, alive func(pid int) bool) (int, error) {
+	if DB == nil {
+		return 0, errors.New("database is not initialized")
+	}
+	var jobs []Job
+	if err := DB.Where("state = ? AND hostname = ? AND lease_expires IS NULL", JobRunning, hostname).Find(&jobs).Error; err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, job := range jobs {
+		if alive != nil && alive(job.ProcessID) {
+			continue
+		}
+		err := DB.Model(&Job{}).Where("id = ? AND state = ?", job.ID, JobRunning).Updates(map[string]interface{}{
+			"state":      JobPending,
+			"last_error": "recovered after the worker process stopped",
+			"updated_at": time.Now(),
+		}).Error
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
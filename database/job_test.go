@@ -0,0 +1,112 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJobQueue(t *testing.T) {
+	initTestDB(t)
+
+	low := Job{Input: "low.example.com", Flow: "general"}
+	high := Job{Input: "high.example.com", Flow: "general", Priority: 10, MaxAttempts: 2}
+	for _, job := range []*Job{&low, &high} {
+		if created, err := EnqueueJob(job); err != nil || !created {
+			t.Fatalf("Error EnqueueJob: %v -- %v", created, err)
+		}
+	}
+	duplicated := Job{Input: "low.example.com", Flow: "general"}
+	if created, err := EnqueueJob(&duplicated); err != nil || created || duplicated.ID != low.ID {
+		t.Errorf("Error the same target and flow should not be queued twice: %v -- %v", duplicated.ID, err)
+	}
+	other := Job{Input: "low.example.com", Flow: "vuln"}
+	if created, _ := EnqueueJob(&other); !created {
+		t.Errorf("Error the same target with another flow should be queued")
+	}
+
+	job, err := ClaimJob("worker-1", "host-a", 100, 0)
+	if err != nil || job == nil || job.ID != high.ID || job.State != JobRunning || job.Attempts != 1 {
+		t.Fatalf("Error ClaimJob should pick the highest priority: %+v -- %v", job, err)
+	}
+
+	// failed job goes back to the queue until it reaches the max attempts
+	FinishJob(job.ID, errors.New("boom"))
+	if job, _ = GetJob(high.ID); job.State != JobPending || job.LastError != "boom" {
+		t.Errorf("Error failed job should be retried: %v", job.State)
+	}
+	job, _ = ClaimJob("worker-1", "host-a", 100, 0)
+	FinishJob(job.ID, errors.New("boom"))
+	if job, _ = GetJob(high.ID); job.State != JobFailed || job.Attempts != 2 {
+		t.Errorf("Error job should fail after the max attempts: %v -- %v", job.State, job.Attempts)
+	}
+	if job, err = RetryJob(high.ID); err != nil {
+		t.Errorf("Error RetryJob: %v", err)
+	}
+	if job, _ = GetJob(high.ID); job.State != JobPending || job.Attempts != 0 {
+		t.Errorf("Error retried job should be pending: %v", job.State)
+	}
+
+	// running jobs of the dead process are recovered
+	job, _ = ClaimJob("worker-2", "host-a", 200, 0)
+	ClaimJob("worker-3", "host-b", 300, 0)
+	recovered, err := RecoverJobs("host-a", func(pid int) bool { return false })
+	if err != nil || recovered != 1 {
+		t.Errorf("Error RecoverJobs: %v -- %v", recovered, err)
+	}
+	if job, _ = GetJob(job.ID); job.State != JobPending {
+		t.Errorf("Error recovered job should be pending: %v", job.State)
+	}
+
+	if _, err := CancelJob(other.ID); err != nil {
+		t.Errorf("Error CancelJob: %v", err)
+	}
+	if _, err := CancelJob(other.ID); err == nil {
+		t.Errorf("Error cancelled job should not be cancelled again")
+	}
+	counts, _ := CountJobs()
+	if counts[JobPending] != 1 || counts[JobRunning] != 1 || counts[JobCancelled] != 1 {
+		t.Errorf("Error CountJobs: %v", counts)
+	}
+	if jobs, _ := ListJobs([]string{JobRunning}, 0); len(jobs) != 1 || jobs[0].Hostname != "host-b" {
+		t.Errorf("Error ListJobs by state: %v", jobs)
+	}
+}
+
+func TestJobLease(t *testing.T) {
+	initTestDB(t)
+
+	job := Job{Input: "example.com", Flow: "general", MaxAttempts: 2}
+	EnqueueJob(&job)
+	leased, err := ClaimJob("remote-1", "box-1", 0, time.Minute)
+	if err != nil || leased == nil || leased.LeaseExpires == nil {
+		t.Fatalf("Error ClaimJob with lease: %+v -- %v", leased, err)
+	}
+	if err := HeartbeatJob(job.ID, "remote-1", time.Minute); err != nil {
+		t.Errorf("Error HeartbeatJob: %v", err)
+	}
+	if err := HeartbeatJob(job.ID, "remote-2", time.Minute); !errors.Is(err, ErrJobLost) {
+		t.Errorf("Error HeartbeatJob of another worker should be rejected: %v", err)
+	}
+	// the leased job is not touched by the recovery of the local processes
+	if recovered, _ := RecoverJobs("box-1", nil); recovered != 0 {
+		t.Errorf("Error RecoverJobs should skip the leased jobs: %v", recovered)
+	}
+
+	// the worker died, the job is re-queued after the lease expired
+	DB.Model(&Job{}).Where("id = ?", job.ID).Update("lease_expires", time.Now().Add(-time.Second))
+	if requeued, err := RequeueExpiredJobs(); err != nil || requeued != 1 {
+		t.Errorf("Error RequeueExpiredJobs: %v -- %v", requeued, err)
+	}
+	if leased, _ = GetJob(job.ID); leased.State != JobPending {
+		t.Errorf("Error expired job should be pending: %v", leased.State)
+	}
+
+	// the last attempt is marked as failed
+	ClaimJob("remote-2", "box-2", 0, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	RequeueExpiredJobs()
+	if leased, _ = GetJob(job.ID); leased.State != JobFailed || leased.Attempts != 2 {
+		t.Errorf("Error expired job should fail after the max attempts: %v -- %v", leased.State, leased.Attempts)
+	}
+}
@@ -5,17 +5,33 @@ import (
 )
 
 type Model struct {
+	ID        uint      `gorm:"primarykey" json:"id,omitempty"`
 	CreatedAt time.Time `json:"created_at,omitempty"`
 	UpdatedAt time.Time `json:"updated_at,omitempty"`
 }
 
-// Schedule store task to do every single time
+// Schedule a named cron schedule, the job of the target is added to the queue on every run
 type Schedule struct {
 	Model
-	TaskName       string `gorm:"type:varchar(255)" json:"task_name"`
-	RefreshSeconds string `gorm:"type:varchar(255)" json:"refresh_seconds"`
-	Command        string `gorm:"type:longtext" json:"command"`
-	Status         string `gorm:"type:varchar(255)" json:"status"`
+	Name     string `gorm:"type:varchar(255);uniqueIndex" json:"name"`
+	CronExpr string `gorm:"type:varchar(255)" json:"cron"`
+	Timezone string `gorm:"type:varchar(255)" json:"timezone,omitempty"`
+	// random delay in seconds added to every run so the schedules don't start at the same second
+	Jitter int `json:"jitter,omitempty"`
+
+	Input       string   `gorm:"type:longtext" json:"input"`
+	InputAsFile bool     `json:"input_as_file,omitempty"`
+	Flow        string   `gorm:"type:varchar(255)" json:"flow,omitempty"`
+	Modules     []string `gorm:"serializer:json" json:"modules,omitempty"`
+	Params      []string `gorm:"serializer:json" json:"params,omitempty"`
+	Workspace   string   `gorm:"type:varchar(255)" json:"workspace,omitempty"`
+	Priority    int      `json:"priority,omitempty"`
+
+	Paused     bool       `json:"paused"`
+	NextRun    *time.Time `gorm:"index" json:"next_run,omitempty"`
+	LastRun    *time.Time `json:"last_run,omitempty"`
+	LastJobID  uint       `json:"last_job_id,omitempty"`
+	LastStatus string     `gorm:"type:varchar(255)" json:"last_status,omitempty"`
 }
 
 ///////////
@@ -49,18 +65,25 @@ type Scan struct {
 	IsNew     bool `json:"is_new"`
 	IsError   bool `json:"is_error"`
 	IsStarted bool `json:"is_started"`
+	// set by the runner when the scan is paused or cancelled by the control file
+	IsPaused    bool `json:"is_paused"`
+	IsCancelled bool `json:"is_cancelled"`
 
 	// if the task is running by cloud provider
 	IsPrepared bool   `json:"is_prepared"`
 	IsCloud    bool   `json:"is_cloud"`
 	CloudInfo  string `json:"cloud_info"`
 
-	Target Target `json:"target"`
+	TargetRefer uint   `gorm:"index" json:"target_refer,omitempty"`
+	Target      Target `gorm:"foreignKey:TargetRefer" json:"target"`
 }
 
 // runtime object
 type Target struct {
-	InputName string `gorm:"type:varchar(255);unique;not null" json:"input_name"`
+	Model
+
+	// the same input can be scanned into multiple workspaces with the -w flag
+	InputName string `gorm:"type:varchar(255);not null" json:"input_name"`
 	// @NOTE: below field shouldn't be show in UI
 	// Workspace == InputName but strip out '/'
 	Workspace string `gorm:"type:varchar(255);unique;not null" json:"workspace"`
@@ -72,6 +95,11 @@ type Target struct {
 	TotalTech          int `json:"total_tech"`
 	TotalScreenShot    int `json:"total_screenshot"`
 	TotalVulnerability int `json:"total_vulnerability"`
+	TotalCritical      int `json:"total_critical"`
+	TotalHigh          int `json:"total_high"`
+	TotalMedium        int `json:"total_medium"`
+	TotalLow           int `json:"total_low"`
+	TotalInfo          int `json:"total_info"`
 	TotalDirb          int `json:"total_dirb"`
 	TotalLink          int `json:"total_link"`
 	TotalArchive       int `json:"total_archive"`
@@ -83,17 +111,22 @@ type Target struct {
 	IsNew      bool `json:"is_new"`
 	IsWildCard bool `json:"is_wildcard"`
 
-	Reports []Report `json:"reports"`
+	Reports []Report `gorm:"foreignKey:TargetRefer" json:"reports"`
 }
 
 // Report store reports file record
 type Report struct {
+	Model
+
 	ReportName string `gorm:"type:varchar(255)" json:"report_name"`
-	ReportPath string `gorm:"type:longtext" json:"report_path"`
+	ReportPath string `gorm:"type:longtext;uniqueIndex:idx_target_report" json:"report_path"`
 
 	Module     string `gorm:"type:varchar(255)" json:"module"`
 	ModulePath string `gorm:"type:longtext" json:"module_path"`
 
 	WorkspaceName string `gorm:"type:varchar(255)" json:"workspace_name"`
 	ReportType    string `gorm:"type:varchar(255);default:'text'" json:"report_type"`
+
+	TargetRefer uint `gorm:"uniqueIndex:idx_target_report" json:"target_refer,omitempty"`
+	ScanRefer   uint `gorm:"index" json:"scan_refer,omitempty"`
 }
@@ -0,0 +1,314 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/spf13/cast"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// the record types could be used in the query
+const (
+	TypeHost       = "host"
+	TypeDns        = "dns"
+	TypePort       = "port"
+	TypeHTTP       = "http"
+	TypeVuln       = "vuln"
+	TypeCredential = "cred"
+)
+
+var queryTypes = []string{TypeHost, TypeDns, TypePort, TypeHTTP, TypeVuln, TypeCredential}
+
+var typeAliases = map[string]string{
+	"host": TypeHost, "hosts": TypeHost, "asset": TypeHost, "assets": TypeHost, "subdomain": TypeHost,
+	"dns":  TypeDns,
+	"port": TypePort, "ports": TypePort, "service": TypePort,
+	"http": TypeHTTP, "url": TypeHTTP, "urls": TypeHTTP,
+	"vuln": TypeVuln, "vulns": TypeVuln, "vulnerability": TypeVuln, "finding": TypeVuln, "findings": TypeVuln,
+	"cred": TypeCredential, "creds": TypeCredential, "credential": TypeCredential,
+}
+
+// fieldAliases short names of the record fields
+var fieldAliases = map[string]string{
+	"tech":     "technology",
+	"template": "check_id",
+	"check":    "check_id",
+	"code":     "status_code",
+	"ip":       "ip_address",
+	"name":     "title",
+}
+
+// the summary columns of each record type
+var summaryFields = map[string][]string{
+	TypeHost:       {"host", "is_alive", "technology", "status"},
+	TypeDns:        {"domain", "dns_type", "dns_value"},
+	TypePort:       {"ip_address", "port", "protocol", "service", "product", "status"},
+	TypeHTTP:       {"url", "status_code", "title", "technology", "status"},
+	TypeVuln:       {"severity", "check_id", "title", "matched_at", "status"},
+	TypeCredential: {"email", "username", "source"},
+}
+
+var severityRanks = map[string]int{
+	"unknown": 0, "info": 1, "informational": 1, "low": 2, "medium": 3, "high": 4, "critical": 5,
+}
+
+// Condition a single term of the query, e.g: severity>=high
+type Condition struct {
+	Field    string
+	Operator string
+	Value    string
+}
+
+// QueryResult a record matched by the query
+type QueryResult map[string]interface{}
+
+// Get get the field value as string
+func (q QueryResult) Get(field string) string {
+	return cast.ToString(q[field])
+}
+
+// Summary columns of the record
+func (q QueryResult) Summary() []string {
+	var values []string
+	for _, field := range summaryFields[q.Get("type")] {
+		values = append(values, q.Get(field))
+	}
+	return values
+}
+
+// SummaryFields the summary column names of the record type
+func SummaryFields(recordType string) []string {
+	return summaryFields[recordType]
+}
+
+var conditionRegex = regexp.MustCompile(`^([a-zA-Z_\.\-]+)\s*(!=|>=|<=|!~|=|~|>|<)\s*(.*)$`)
+
+// ParseQuery parse the filter, e.g: type=vuln severity>=high host~"*.api.*"
+func ParseQuery(raw string) (conditions []Condition, err error) {
+	for _, term := range splitTerms(raw) {
+		matches := conditionRegex.FindStringSubmatch(term)
+		if len(matches) != 4 {
+			return nil, fmt.Errorf("invalid condition: %v", term)
+		}
+
+		condition := Condition{
+			Field:    strings.ToLower(matches[1]),
+			Operator: matches[2],
+			Value:    strings.Trim(matches[3], `"'`),
+		}
+		if alias, ok := fieldAliases[condition.Field]; ok {
+			condition.Field = alias
+		}
+		if condition.Field == "type" {
+			recordType, ok := typeAliases[strings.ToLower(condition.Value)]
+			if !ok {
+				return nil, fmt.Errorf("invalid type %v, should be one of %v", condition.Value, strings.Join(queryTypes, ","))
+			}
+			condition.Value = recordType
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions, nil
+}
+
+// splitTerms split the query by spaces except the one in the quotes
+func splitTerms(raw string) (terms []string) {
+	var term strings.Builder
+	var quote rune
+	for _, c := range raw {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+			term.WriteRune(c)
+		case c == '"' || c == '\'':
+			quote = c
+			term.WriteRune(c)
+		case c == ' ' || c == '\t':
+			if term.Len() > 0 {
+				terms = append(terms, term.String())
+				term.Reset()
+			}
+		default:
+			term.WriteRune(c)
+		}
+	}
+	if term.Len() > 0 {
+		terms = append(terms, term.String())
+	}
+	return terms
+}
+
+// QueryAssets run the query over the workspaces, all the workspaces in the database will be used if no workspace was given
+func QueryAssets(raw string, workspaces ...string) (results []QueryResult, err error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database is not initialized")
+	}
+
+	conditions, err := ParseQuery(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	recordTypes := queryTypes
+	for _, condition := range conditions {
+		if condition.Field == "type" && condition.Operator == "=" {
+			recordTypes = []string{condition.Value}
+		}
+	}
+
+	var targets []Target
+	tx := DB.Order("workspace")
+	if len(workspaces) > 0 {
+		tx = tx.Where("workspace IN ?", workspaces)
+	}
+	if err := tx.Find(&targets).Error; err != nil {
+		return nil, err
+	}
+
+	for _, target := range targets {
+		for _, recordType := range recordTypes {
+			records, err := loadRecords(target, recordType)
+			if err != nil {
+				return nil, err
+			}
+			for _, record := range records {
+				if matchConditions(record, conditions) {
+					results = append(results, record)
+				}
+			}
+		}
+	}
+	return results, nil
+}
+
+func loadRecords(target Target, recordType string) (records []QueryResult, err error) {
+	var data interface{}
+	switch recordType {
+	case TypeHost:
+		data = &[]Asset{}
+	case TypeDns:
+		data = &[]Dns{}
+	case TypePort:
+		data = &[]Port{}
+	case TypeHTTP:
+		data = &[]HTTP{}
+	case TypeVuln:
+		data = &[]Finding{}
+	case TypeCredential:
+		data = &[]Credential{}
+	}
+	if err := DB.Where("target_refer = ?", target.ID).Order("id").Find(data).Error; err != nil {
+		return nil, err
+	}
+
+	// convert the records to the plain map so the fields can be used by their json names
+	raw, err := jsoniter.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := jsoniter.Unmarshal(raw, &records); err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		record["workspace"] = target.Workspace
+		record["type"] = recordType
+		switch recordType {
+		case TypeHost:
+			record["host"] = record["asset_value"]
+		case TypeVuln:
+			record["url"] = record["matched_at"]
+		}
+	}
+	return records, nil
+}
+
+func matchConditions(record QueryResult, conditions []Condition) bool {
+	for _, condition := range conditions {
+		if !matchCondition(record, condition) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchCondition(record QueryResult, condition Condition) bool {
+	raw, ok := record[condition.Field]
+	if !ok {
+		return condition.Operator == "!=" || condition.Operator == "!~"
+	}
+	value := strings.ToLower(cast.ToString(raw))
+	expected := strings.ToLower(condition.Value)
+
+	switch condition.Operator {
+	case "=":
+		return globMatch(value, expected, false)
+	case "!=":
+		return !globMatch(value, expected, false)
+	case "~":
+		return globMatch(value, expected, true)
+	case "!~":
+		return !globMatch(value, expected, true)
+	}
+
+	// compare operators
+	var result int
+	switch {
+	case condition.Field == "severity":
+		result = severityRanks[value] - severityRanks[expected]
+	case strings.HasSuffix(condition.Field, "_seen") || strings.HasSuffix(condition.Field, "_at"):
+		expectedTime, err := utils.ParseTime(condition.Value)
+		if err != nil {
+			return false
+		}
+		result = cast.ToTime(raw).Compare(expectedTime)
+	default:
+		left, lerr := cast.ToFloat64E(raw)
+		right, rerr := cast.ToFloat64E(condition.Value)
+		if lerr != nil || rerr != nil {
+			result = strings.Compare(value, expected)
+		} else {
+			result = compareFloat(left, right)
+		}
+	}
+
+	switch condition.Operator {
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	}
+	return false
+}
+
+func compareFloat(left float64, right float64) int {
+	switch {
+	case left > right:
+		return 1
+	case left < right:
+		return -1
+	}
+	return 0
+}
+
+// globMatch match the value with the pattern, * is the wildcard
+// the value only need to contain the pattern if it's a partial match without any wildcard
+func globMatch(value string, pattern string, partial bool) bool {
+	if !strings.Contains(pattern, "*") {
+		if partial {
+			return strings.Contains(value, pattern)
+		}
+		return value == pattern
+	}
+	expression := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	matched, _ := regexp.MatchString(expression, value)
+	return matched
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	conditions, err := ParseQuery(`type=finding severity>=high host~"*.api.*" tech!~"apache tomcat"`)
+	if err != nil {
+		t.Fatalf("Error ParseQuery: %v", err)
+	}
+	if len(conditions) != 4 {
+		t.Fatalf("Error ParseQuery: %v", conditions)
+	}
+	if conditions[0].Value != TypeVuln || conditions[2].Value != "*.api.*" || conditions[3].Field != "technology" || conditions[3].Value != "apache tomcat" {
+		t.Errorf("Error ParseQuery: %v", conditions)
+	}
+
+	if _, err := ParseQuery("type=unknown"); err == nil {
+		t.Errorf("Error ParseQuery should reject the unknown type")
+	}
+	if _, err := ParseQuery("severity"); err == nil {
+		t.Errorf("Error ParseQuery should reject the invalid condition")
+	}
+}
+
+func TestQueryAssets(t *testing.T) {
+	initTestDB(t)
+
+	for _, ws := range []string{"example.com", "sample.com"} {
+		target := Target{InputName: ws, Workspace: ws}
+		SaveTarget(&target)
+		ImportFindings([]Finding{
+			{MatchedAt: "https://v1.api." + ws + "/", CheckID: "jenkins-panel", Severity: "high", TargetRefer: target.ID},
+			{MatchedAt: "https://www." + ws + "/", CheckID: "tech-detect", Severity: "info", TargetRefer: target.ID},
+			{MatchedAt: "https://dev.api." + ws + "/", CheckID: "exposed-git", Severity: "critical", TargetRefer: target.ID},
+		})
+		ImportPorts([]Port{{IPAddress: "1.2.3.4", PortID: 8080, Protocol: "tcp", TargetRefer: target.ID}})
+	}
+
+	results, err := QueryAssets(`type=vuln severity>=high host~"*.api.*"`)
+	if err != nil {
+		t.Fatalf("Error QueryAssets: %v", err)
+	}
+	if len(results) != 4 {
+		t.Errorf("Error QueryAssets: %v", len(results))
+	}
+
+	results, _ = QueryAssets(`type=vuln template=jenkins-*`, "sample.com")
+	if len(results) != 1 || results[0].Get("workspace") != "sample.com" {
+		t.Errorf("Error QueryAssets with workspace: %v", results)
+	}
+
+	results, _ = QueryAssets(`port>=8000`)
+	if len(results) != 2 || results[0].Get("type") != TypePort {
+		t.Errorf("Error QueryAssets numeric compare: %v", results)
+	}
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SaveTarget create a new target record or refresh the existing one in the same workspace
+func SaveTarget(target *Target) error {
+	if DB == nil {
+		return nil
+	}
+
+	if target.ID == 0 {
+		var existing Target
+		err := DB.Where("workspace = ?", target.Workspace).First(&existing).Error
+		if err == nil {
+			target.ID = existing.ID
+			target.CreatedAt = existing.CreatedAt
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+
+	return DB.Omit(clause.Associations).Save(target).Error
+}
+
+// SaveScan create or update a scan record and link it to its target
+func SaveScan(scan *Scan) error {
+	if DB == nil {
+		return nil
+	}
+
+	if scan.Target.ID != 0 {
+		scan.TargetRefer = scan.Target.ID
+	}
+	return DB.Omit(clause.Associations).Save(scan).Error
+}
+
+// SaveReports store the new report records of the target, the report path is unique per target
+func SaveReports(target *Target, scanID uint) error {
+	if DB == nil || target.ID == 0 {
+		return nil
+	}
+
+	for i := range target.Reports {
+		report := &target.Reports[i]
+		if report.ID != 0 {
+			continue
+		}
+		report.TargetRefer = target.ID
+		report.ScanRefer = scanID
+		report.WorkspaceName = target.Workspace
+
+		err := DB.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "target_refer"}, {Name: "report_path"}},
+			DoUpdates: clause.AssignmentColumns([]string{"report_name", "module", "module_path", "report_type", "scan_refer", "updated_at"}),
+		}).Create(report).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTargetByWorkspace get the target record with all of its reports
+func GetTargetByWorkspace(wsName string) (target Target, err error) {
+	if DB == nil {
+		return target, errors.New("database is not initialized")
+	}
+	err = DB.Preload("Reports").Where("workspace = ?", wsName).First(&target).Error
+	return target, err
+}
+
+// GetScan get the scan with its target by the ID
+func GetScan(id uint) (scan Scan, err error) {
+	if DB == nil {
+		return scan, errors.New("database is not initialized")
+	}
+	err = DB.Preload("Target").First(&scan, id).Error
+	return scan, err
+}
+
+// GetLatestScan get the latest scan of the workspace
+func GetLatestScan(wsName string) (scan Scan, err error) {
+	target, err := GetTargetByWorkspace(wsName)
+	if err != nil {
+		return scan, err
+	}
+
+	err = DB.Where("target_refer = ?", target.ID).Order("created_at desc").First(&scan).Error
+	scan.Target = target
+	return scan, err
+}
+
+// ScanCount the number of the scans of the flow in the status
+type ScanCount struct {
+	Flow   string
+	Status string
+	Total  int
+}
+
+// CountScans the number of the scans per flow and status (running, paused, finished, failed, cancelled)
+func CountScans() (counts []ScanCount, err error) {
+	if DB == nil {
+		return counts, errors.New("database is not initialized")
+	}
+	status := "CASE WHEN is_cancelled THEN 'cancelled' WHEN is_error THEN 'failed' WHEN is_done THEN 'finished' " +
+		"WHEN is_paused THEN 'paused' WHEN is_running THEN 'running' ELSE 'pending' END"
+	err = DB.Model(&Scan{}).Select("task_name as flow, " + status + " as status, count(*) as total").
+		Group("flow, status").Scan(&counts).Error
+	return counts, err
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// the status of the last run of the schedule
+const (
+	ScheduleQueued  = "queued"
+	ScheduleSkipped = "skipped"
+	ScheduleError   = "error"
+)
+
+// SaveSchedule create the schedule, the name must be unique
+func SaveSchedule(schedule *Schedule) error {
+	if DB == nil {
+		return errors.New("database is not initialized")
+	}
+	if schedule.Name == "" {
+		return errors.New("schedule name is required")
+	}
+	if schedule.ID == 0 {
+		var total int64
+		DB.Model(&Schedule{}).Where("name = ?", schedule.Name).Count(&total)
+		if total > 0 {
+			return fmt.Errorf("schedule %v already exists", schedule.Name)
+		}
+	}
+	return DB.Save(schedule).Error
+}
+
+// GetSchedule get the schedule by its name
+func GetSchedule(name string) (*Schedule, error) {
+	if DB == nil {
+		return nil, errors.New("database is not initialized")
+	}
+	var schedule Schedule
+	if err := DB.Where("name = ?", name).First(&schedule).Error; err != nil {
+		return nil, fmt.Errorf("schedule %v not found", name)
+	}
+	return &schedule, nil
+}
+
+// ListSchedules get all the schedules ordered by name
+func ListSchedules() (schedules []Schedule, err error) {
+	if DB == nil {
+		return schedules, errors.New("database is not initialized")
+	}
+	err = DB.Order("name asc").Find(&schedules).Error
+	return schedules, err
+}
+
+// DeleteSchedule remove the schedule by its name
+func DeleteSchedule(name string) error {
+	if DB == nil {
+		return errors.New("database is not initialized")
+	}
+	result := DB.Where("name = ?", name).Delete(&Schedule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("schedule %v not found", name)
+	}
+	return nil
+}
+
+// DueSchedules get the active schedules whose next run is due
+func DueSchedules(now time.Time) (schedules []Schedule, err error) {
+	if DB == nil {
+		return schedules, errors.New("database is not initialized")
+	}
+	err = DB.Where("paused = ? AND next_run IS NOT NULL AND next_run <= ?", false, now).Order("next_run asc").Find(&schedules).Error
+	return schedules, err
+}
+
+// ClaimScheduleRun move the next run of the schedule forward
+// it returns false if another scheduler already took this run
+func ClaimScheduleRun(schedule Schedule, nextRun time.Time) (bool, error) {
+	if DB == nil {
+		return false, errors.New("database is not initialized")
+	}
+	now := time.Now()
+	result := DB.Model(&Schedule{}).Where("id = ? AND next_run = ?", schedule.ID, schedule.NextRun).Updates(map[string]interface{}{
+		"next_run":   nextRun,
+		"last_run":   now,
+		"updated_at": now,
+	})
+	return result.RowsAffected == 1, result.Error
+}
+
+// UpdateScheduleStatus record the result of the last run
+func UpdateScheduleStatus(id uint, status string, jobID uint) error {
+	if DB == nil {
+		return errors.New("database is not initialized")
+	}
+	return DB.Model(&Schedule{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_status": status,
+		"last_job_id": jobID,
+	}).Error
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"fmt"
 	"os"
 	"path"
 	"path/filepath"
@@ -34,42 +35,72 @@ func GetAllWorkspaces(opt libs.Options) (directories []string) {
 }
 
 func GetAllScan(opt libs.Options) (scans []Scan) {
+	// the server keeps all the workspaces in memory
+	if index := CurrentWorkspaceIndex(); index != nil {
+		return index.All()
+	}
+	if DB != nil {
+		return getAllScanFromDB(opt)
+	}
+
 	wss := GetAllWorkspaces(opt)
 	for _, wsName := range wss {
-		runtimeFile := filepath.Join(opt.Env.WorkspacesFolder, wsName, "runtime")
-		if !utils.FileExists(runtimeFile) {
+		wsData, err := ParseRuntimeFile(filepath.Join(opt.Env.WorkspacesFolder, wsName, "runtime"))
+		if err != nil {
 			continue
 		}
+		scans = append(scans, staticScanPath(wsData, opt))
+	}
+	return scans
+}
 
-		// parse the content
-		runtimeContent := utils.GetFileContent(runtimeFile)
-		wsData := Scan{}
-		if err := jsoniter.UnmarshalFromString(runtimeContent, &wsData); err == nil {
+// getAllScanFromDB only take the latest scan of each workspace
+func getAllScanFromDB(opt libs.Options) (scans []Scan) {
+	var rawScans []Scan
+	if err := DB.Preload("Target.Reports").Order("created_at desc").Find(&rawScans).Error; err != nil {
+		utils.ErrorF("Error reading scans from database: %v", err)
+		return scans
+	}
 
-			// replace the filepath with static prefix
-			wsData.MarkDownReport = strings.ReplaceAll(wsData.MarkDownReport, opt.Env.WorkspacesFolder, path.Join("/", opt.Server.StaticPrefix, "workspaces"))
-			wsData.MarkDownSunmmary = strings.ReplaceAll(wsData.MarkDownSunmmary, opt.Env.WorkspacesFolder, path.Join("/", opt.Server.StaticPrefix, "workspaces"))
-			scans = append(scans, wsData)
+	seen := make(map[uint]bool)
+	for _, scan := range rawScans {
+		if seen[scan.TargetRefer] {
+			continue
 		}
-
+		seen[scan.TargetRefer] = true
+		scans = append(scans, staticScanPath(scan, opt))
 	}
 	return scans
 }
 
 func GetSingleScan(wsName string, opt libs.Options) (scan Scan) {
-	runtimeFile := filepath.Join(opt.Env.WorkspacesFolder, wsName, "runtime")
-	if !utils.FileExists(runtimeFile) {
-		return scan
+	if DB != nil {
+		if dbScan, err := GetLatestScan(wsName); err == nil {
+			return staticScanPath(dbScan, opt)
+		}
 	}
 
-	// parse the content
-	runtimeContent := utils.GetFileContent(runtimeFile)
-	if err := jsoniter.UnmarshalFromString(runtimeContent, &scan); err == nil {
-		// replace the filepath with static prefix
-		scan.MarkDownReport = strings.ReplaceAll(scan.MarkDownReport, opt.Env.WorkspacesFolder, path.Join("/", opt.Server.StaticPrefix, "workspaces"))
-		scan.MarkDownSunmmary = strings.ReplaceAll(scan.MarkDownSunmmary, opt.Env.WorkspacesFolder, path.Join("/", opt.Server.StaticPrefix, "workspaces"))
+	scan, err := ParseRuntimeFile(filepath.Join(opt.Env.WorkspacesFolder, wsName, "runtime"))
+	if err != nil {
 		return scan
 	}
+	return staticScanPath(scan, opt)
+}
+
+// ParseRuntimeFile parse the runtime file which is an export of the scan record
+func ParseRuntimeFile(runtimeFile string) (scan Scan, err error) {
+	if !utils.FileExists(runtimeFile) {
+		return scan, fmt.Errorf("runtime file not found: %v", runtimeFile)
+	}
+	runtimeContent := utils.GetFileContent(runtimeFile)
+	err = jsoniter.UnmarshalFromString(runtimeContent, &scan)
+	return scan, err
+}
+
+// staticScanPath replace the filepath with static prefix
+func staticScanPath(scan Scan, opt libs.Options) Scan {
+	scan.MarkDownReport = strings.ReplaceAll(scan.MarkDownReport, opt.Env.WorkspacesFolder, path.Join("/", opt.Server.StaticPrefix, "workspaces"))
+	scan.MarkDownSunmmary = strings.ReplaceAll(scan.MarkDownSunmmary, opt.Env.WorkspacesFolder, path.Join("/", opt.Server.StaticPrefix, "workspaces"))
 	return scan
 }
 
@@ -0,0 +1,265 @@
+package database
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// the roles of the users, each role could do everything the previous one could
+const (
+	RoleViewer   = "viewer"
+	RoleOperator = "operator"
+	RoleAdmin    = "admin"
+)
+
+// APITokenPrefix the prefix of the API tokens so they're not confused with the JWT
+const APITokenPrefix = "vx_"
+
+var roleLevels = map[string]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// ErrInvalidCredentials the username, password or token is wrong
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// User the account of the API server
+type User struct {
+	Model
+	Username     string `gorm:"type:varchar(255);uniqueIndex;not null" json:"username"`
+	PasswordHash string `gorm:"type:varchar(255)" json:"-"`
+	Role         string `gorm:"type:varchar(255);default:'viewer'" json:"role"`
+	Disabled     bool   `json:"disabled"`
+}
+
+// APIToken the long-lived token of the user, only the hash of the token is stored
+type APIToken struct {
+	Model
+	UserID     uint       `gorm:"index" json:"user_id"`
+	Name       string     `gorm:"type:varchar(255)" json:"name"`
+	Prefix     string     `gorm:"type:varchar(255)" json:"prefix"`
+	TokenHash  string     `gorm:"type:varchar(255);uniqueIndex" json:"-"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	Revoked    bool       `json:"revoked"`
+}
+
+// ValidRole the role is one of the known roles
+func ValidRole(role string) bool {
+	_, ok := roleLevels[role]
+	return ok
+}
+
+// RoleAllowed the role has at least the privileges of the required role
+func RoleAllowed(role string, required string) bool {
+	return roleLevels[role] >= roleLevels[required] && roleLevels[role] > 0
+}
+
+// CreateUser create the user with the hashed password
+func CreateUser(username string, password string, role string) (*User, error) {
+	if DB == nil {
+		return nil, errors.New("database is not initialized")
+	}
+	username = strings.TrimSpace(username)
+	if username == "" || password == "" {
+		return nil, errors.New("username and password are required")
+	}
+	if role == "" {
+		role = RoleViewer
+	}
+	if !ValidRole(role) {
+		return nil, fmt.Errorf("invalid role %v", role)
+	}
+	var total int64
+	DB.Model(&User{}).Where("username = ?", username).Count(&total)
+	if total > 0 {
+		return nil, fmt.Errorf("user %v already exists", username)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	user := User{Username: username, PasswordHash: string(hash), Role: role}
+	return &user, DB.Create(&user).Error
+}
+
+// GetUser get the user by the username
+func GetUser(username string) (*User, error) {
+	if DB == nil {
+		return nil, errors.New("database is not initialized")
+	}
+	var user User
+	if err := DB.Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user %v not found", username)
+	}
+	return &user, nil
+}
+
+// ListUsers get all the users ordered by the username
+func ListUsers() (users []User, err error) {
+	if DB == nil {
+		return users, errors.New("database is not initialized")
+	}
+	err = DB.Order("username asc").Find(&users).Error
+	return users, err
+}
+
+// CountUsers number of the users
+func CountUsers() int64 {
+	var total int64
+	if DB != nil {
+		DB.Model(&User{}).Count(&total)
+	}
+	return total
+}
+
+// UpdateUser change the password, the role or the disabled flag of the user, the empty values are kept
+func UpdateUser(username string, password string, role string, disabled *bool) (*User, error) {
+	user, err := GetUser(username)
+	if err != nil {
+		return nil, err
+	}
+	if password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		user.PasswordHash = string(hash)
+	}
+	if role != "" {
+		if !ValidRole(role) {
+			return nil, fmt.Errorf("invalid role %v", role)
+		}
+		user.Role = role
+	}
+	if disabled != nil {
+		user.Disabled = *disabled
+	}
+	return user, DB.Save(user).Error
+}
+
+// DeleteUser remove the user and all of its tokens
+func DeleteUser(username string) error {
+	user, err := GetUser(username)
+	if err != nil {
+		return err
+	}
+	if err := DB.Where("user_id = ?", user.ID).Delete(&APIToken{}).Error; err != nil {
+		return err
+	}
+	return DB.Delete(user).Error
+}
+
+// AuthenticateUser check the password of the user
+func AuthenticateUser(username string, password string) (*User, error) {
+	user, err := GetUser(username)
+	if err != nil || user.Disabled {
+		return nil, ErrInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIToken create the new token of the user, the raw token is only returned here
+// the token never expires if the ttl is zero
+func CreateAPIToken(username string, name string, ttl time.Duration) (string, *APIToken, error) {
+	user, err := GetUser(username)
+	if err != nil {
+		return "", nil, err
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, err
+	}
+	token := APITokenPrefix + hex.EncodeToString(raw)
+	apiToken := APIToken{
+		UserID:    user.ID,
+		Name:      name,
+		Prefix:    token[:len(APITokenPrefix)+8],
+		TokenHash: hashToken(token),
+	}
+	if ttl > 0 {
+		expires := time.Now().Add(ttl)
+		apiToken.ExpiresAt = &expires
+	}
+	if err := DB.Create(&apiToken).Error; err != nil {
+		return "", nil, err
+	}
+	return token, &apiToken, nil
+}
+
+// VerifyAPIToken get the user of the token, the revoked and expired tokens are rejected
+func VerifyAPIToken(token string) (*User, error) {
+	if DB == nil {
+		return nil, errors.New("database is not initialized")
+	}
+	var apiToken APIToken
+	if err := DB.Where("token_hash = ?", hashToken(token)).First(&apiToken).Error; err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	now := time.Now()
+	if apiToken.Revoked || (apiToken.ExpiresAt != nil && apiToken.ExpiresAt.Before(now)) {
+		return nil, ErrInvalidCredentials
+	}
+	var user User
+	if err := DB.First(&user, apiToken.UserID).Error; err != nil || user.Disabled {
+		return nil, ErrInvalidCredentials
+	}
+	DB.Model(&apiToken).Update("last_used_at", now)
+	return &user, nil
+}
+
+// ListAPITokens get the tokens of the user, all the tokens are returned if the username is empty
+func ListAPITokens(username string) (tokens []APIToken, err error) {
+	if DB == nil {
+		return tokens, errors.New("database is not initialized")
+	}
+	query := DB.Order("id asc")
+	if username != "" {
+		user, err := GetUser(username)
+		if err != nil {
+			return tokens, err
+		}
+		query = query.Where("user_id = ?", user.ID)
+	}
+	err = query.Find(&tokens).Error
+	return tokens, err
+}
+
+// RevokeAPIToken revoke the token, only the token of the user is revoked if the username is set
+func RevokeAPIToken(id uint, username string) error {
+	if DB == nil {
+		return errors.New("database is not initialized")
+	}
+	query := DB.Model(&APIToken{}).Where("id = ?", id)
+	if username != "" {
+		user, err := GetUser(username)
+		if err != nil {
+			return err
+		}
+		query = query.Where("user_id = ?", user.ID)
+	}
+	result := query.Update("revoked", true)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("token %v not found", id)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuthenticateUser(t *testing.T) {
+	initTestDB(t)
+
+	if _, err := CreateUser("alice", "secret", RoleOperator); err != nil {
+		t.Fatalf("Error CreateUser: %v", err)
+	}
+	if _, err := CreateUser("alice", "other", RoleViewer); err == nil {
+		t.Errorf("Error CreateUser should reject the duplicate username")
+	}
+	if _, err := CreateUser("bob", "secret", "root"); err == nil {
+		t.Errorf("Error CreateUser should reject the unknown role")
+	}
+
+	user, err := AuthenticateUser("alice", "secret")
+	if err != nil || user.Role != RoleOperator {
+		t.Fatalf("Error AuthenticateUser: %v", err)
+	}
+	if user.PasswordHash == "secret" {
+		t.Errorf("Error the password must be hashed")
+	}
+	if _, err := AuthenticateUser("alice", "wrong"); err != ErrInvalidCredentials {
+		t.Errorf("Error AuthenticateUser should reject the wrong password")
+	}
+
+	disabled := true
+	UpdateUser("alice", "", "", &disabled)
+	if _, err := AuthenticateUser("alice", "secret"); err == nil {
+		t.Errorf("Error AuthenticateUser should reject the disabled user")
+	}
+}
+
+func TestRoleAllowed(t *testing.T) {
+	if !RoleAllowed(RoleAdmin, RoleOperator) || !RoleAllowed(RoleOperator, RoleViewer) {
+		t.Errorf("Error the higher role should be allowed")
+	}
+	if RoleAllowed(RoleViewer, RoleOperator) || RoleAllowed(RoleOperator, RoleAdmin) {
+		t.Errorf("Error the lower role should not be allowed")
+	}
+	if RoleAllowed("", RoleViewer) {
+		t.Errorf("Error the empty role should not be allowed")
+	}
+}
+
+func TestAPIToken(t *testing.T) {
+	initTestDB(t)
+	CreateUser("alice", "secret", RoleViewer)
+
+	raw, token, err := CreateAPIToken("alice", "ci", 0)
+	if err != nil {
+		t.Fatalf("Error CreateAPIToken: %v", err)
+	}
+	if token.TokenHash == raw || token.ExpiresAt != nil {
+		t.Errorf("Error the token must be hashed and never expire: %v", token.ExpiresAt)
+	}
+	user, err := VerifyAPIToken(raw)
+	if err != nil || user.Username != "alice" {
+		t.Fatalf("Error VerifyAPIToken: %v", err)
+	}
+	if _, err := VerifyAPIToken(APITokenPrefix + "wrong"); err == nil {
+		t.Errorf("Error VerifyAPIToken should reject the unknown token")
+	}
+
+	CreateUser("bob", "secret", RoleViewer)
+	if err := RevokeAPIToken(token.ID, "bob"); err == nil {
+		t.Errorf("Error RevokeAPIToken should not revoke the token of the other user")
+	}
+	if err := RevokeAPIToken(token.ID, "alice"); err != nil {
+		t.Fatalf("Error RevokeAPIToken: %v", err)
+	}
+	if _, err := VerifyAPIToken(raw); err == nil {
+		t.Errorf("Error VerifyAPIToken should reject the revoked token")
+	}
+
+	expired, _, _ := CreateAPIToken("alice", "old", time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	if _, err := VerifyAPIToken(expired); err == nil {
+		t.Errorf("Error VerifyAPIToken should reject the expired token")
+	}
+
+	DeleteUser("alice")
+	if tokens, _ := ListAPITokens(""); len(tokens) != 0 {
+		t.Errorf("Error DeleteUser should remove the tokens: %v", len(tokens))
+	}
+}
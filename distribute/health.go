@@ -157,3 +157,24 @@ func (c *CloudRunner) IsPanic() bool {
 
 	return false
 }
+
+// CountInstances the number of the cloud instances alive per provider, the instances marked as error are skipped
+func CountInstances(opt libs.Options) map[string]int {
+	counts := make(map[string]int)
+	entries, err := os.ReadDir(opt.Env.InstancesFolder)
+	if err != nil {
+		return counts
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		var instance CloudRunner
+		content := utils.GetFileContent(filepath.Join(opt.Env.InstancesFolder, entry.Name()))
+		if err := jsoniter.UnmarshalFromString(content, &instance); err != nil || instance.IsError {
+			continue
+		}
+		counts[instance.Provider.ProviderName]++
+	}
+	return counts
+}
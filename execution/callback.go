@@ -0,0 +1,148 @@
+package execution
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// the status of the scan sent to the callback URL
+const (
+	CallbackDone   = "done"
+	CallbackFailed = "failed"
+)
+
+const (
+	// CallbackSignatureHeader the HMAC-SHA256 of the body, in the form of sha256=<hex>
+	CallbackSignatureHeader = "X-Vulnx-Signature"
+	CallbackEventHeader     = "X-Vulnx-Event"
+	// callbackMaxElapsed stop retrying the callback after that
+	callbackMaxElapsed = 2 * time.Hour
+	// CallbackExitWait how long the scan process waits for the pending callbacks before exiting
+	CallbackExitWait = 10 * time.Minute
+)
+
+// CallbackPayload the summary of the scan posted to the callback URL when the scan is finished or failed
+type CallbackPayload struct {
+	Workspace  string         `json:"workspace"`
+	Target     string         `json:"target"`
+	Flow       string         `json:"flow,omitempty"`
+	Modules    []string       `json:"modules,omitempty"`
+	ScanID     uint           `json:"scan_id,omitempty"`
+	Status     string         `json:"status"`
+	Error      string         `json:"error,omitempty"`
+	StartedAt  time.Time      `json:"started_at"`
+	FinishedAt time.Time      `json:"finished_at"`
+	Duration   int            `json:"duration"` // as seconds
+	Counters   map[string]int `json:"counters"`
+	Reports    []string       `json:"reports"`
+}
+
+// callbackBackOff the retry policy of the callback, it's replaced in the tests
+var callbackBackOff = func() backoff.BackOff {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = 5 * time.Second
+	b.MaxInterval = 5 * time.Minute
+	b.MaxElapsedTime = callbackMaxElapsed
+	return b
+}
+
+// CallbackEnv the environment variables passing the callback to the scan started as a command
+func CallbackEnv(url string, secret string) []string {
+	if url == "" {
+		return nil
+	}
+	env := []string{fmt.Sprintf("%v=%v", libs.CallbackURLEnv, url)}
+	if secret != "" {
+		env = append(env, fmt.Sprintf("%v=%v", libs.CallbackSecretEnv, secret))
+	}
+	return env
+}
+
+// SignCallback the hex encoded HMAC-SHA256 of the body with the secret
+func SignCallback(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyCallback check the signature header sent with the body
+func VerifyCallback(secret string, body []byte, signature string) bool {
+	expected := "sha256=" + SignCallback(secret, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// SendCallback post the payload to the URL and retry with the backoff until it gets a 2xx response
+// the signature header is only sent when the secret is set
+func SendCallback(url string, secret string, payload CallbackPayload) error {
+	body, err := jsoniter.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	headers := map[string]string{
+		"Content-Type":      "application/json",
+		"User-Agent":        fmt.Sprintf("%s/%s", libs.BINARY, libs.VERSION),
+		CallbackEventHeader: "scan." + payload.Status,
+	}
+	if secret != "" {
+		headers[CallbackSignatureHeader] = "sha256=" + SignCallback(secret, body)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	attempts := 0
+	operation := func() error {
+		attempts++
+		return sendRequest(client, http.MethodPost, url, headers, bytes.NewReader(body))
+	}
+	notify := func(err error, delay time.Duration) {
+		utils.WarnF("Error sending the callback to %v (attempt %v), retrying in %v: %v", url, attempts, delay, err)
+	}
+	if err := backoff.RetryNotify(operation, callbackBackOff(), notify); err != nil {
+		return fmt.Errorf("callback to %v failed after %v attempts: %v", url, attempts, err)
+	}
+	utils.InforF("Sent the %v callback to %v", payload.Status, url)
+	return nil
+}
+
+// callbacks the callbacks which are still sent or retried in the background
+var callbacks sync.WaitGroup
+
+// ScanCallback send the summary to the callback URL of the scan options in the background if it's set
+// the scan doesn't wait for the receiver, call WaitCallbacks before the process exits
+func ScanCallback(options libs.Options, payload CallbackPayload) {
+	if options.Scan.CallbackURL == "" {
+		return
+	}
+	callbacks.Add(1)
+	go func() {
+		defer callbacks.Done()
+		if err := SendCallback(options.Scan.CallbackURL, options.Scan.CallbackSecret, payload); err != nil {
+			utils.ErrorF("%v", err)
+		}
+	}()
+}
+
+// WaitCallbacks wait for the callbacks in the background, false is returned if some are still retrying after the timeout
+func WaitCallbacks(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		callbacks.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
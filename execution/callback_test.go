@@ -0,0 +1,108 @@
+package execution
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/whoamikiddie/vulnx/libs"
+)
+
+func TestSendCallback(t *testing.T) {
+	callbackBackOff = func() backoff.BackOff {
+		return backoff.WithMaxRetries(backoff.NewConstantBackOff(10*time.Millisecond), 5)
+	}
+
+	var mu sync.Mutex
+	var attempts int
+	var body []byte
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		// fail the first two attempts to check the retry
+		if attempts < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(CallbackSignatureHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	payload := CallbackPayload{
+		Workspace: "example.com",
+		Target:    "example.com",
+		Flow:      "general",
+		Status:    CallbackDone,
+		Duration:  42,
+		Counters:  map[string]int{"dns": 3},
+		Reports:   []string{"/tmp/example.com/summary.html"},
+	}
+	if err := SendCallback(server.URL, "s3cret", payload); err != nil {
+		t.Fatalf("Error SendCallback: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Error retrying the callback: %v attempts", attempts)
+	}
+	if !VerifyCallback("s3cret", body, signature) {
+		t.Errorf("Error verifying the signature: %v", signature)
+	}
+	if VerifyCallback("wrong", body, signature) {
+		t.Errorf("Error signature should not match with the wrong secret")
+	}
+
+	var received CallbackPayload
+	if err := json.Unmarshal(body, &received); err != nil || received.Status != CallbackDone || received.Counters["dns"] != 3 || received.Duration != 42 {
+		t.Errorf("Error parsing the callback body: %s -- %v", body, err)
+	}
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+	if err := SendCallback(down.URL, "", payload); err == nil {
+		t.Errorf("Error SendCallback should fail when the retries are exhausted")
+	}
+}
+
+func TestScanCallback(t *testing.T) {
+	callbackBackOff = func() backoff.BackOff {
+		return backoff.WithMaxRetries(backoff.NewConstantBackOff(300*time.Millisecond), 3)
+	}
+	var mu sync.Mutex
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	var options libs.Options
+	options.Scan.CallbackURL = server.URL
+	start := time.Now()
+	ScanCallback(options, CallbackPayload{Workspace: "example.com", Status: CallbackDone})
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("Error ScanCallback should not wait for the receiver: %v", elapsed)
+	}
+	if WaitCallbacks(10 * time.Millisecond) {
+		t.Errorf("Error WaitCallbacks should time out while the callback is retried")
+	}
+	if !WaitCallbacks(5 * time.Second) {
+		t.Fatalf("Error WaitCallbacks should return once the retries are exhausted")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 4 {
+		t.Errorf("Error the callback should be retried in the background: %v attempts", attempts)
+	}
+}
@@ -2,7 +2,6 @@ package execution
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"math/rand"
 	"path/filepath"
@@ -10,9 +9,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/spf13/cast"
-
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/robertkrimen/otto"
 	"github.com/slack-go/slack"
 	"github.com/whoamikiddie/vulnx/libs"
@@ -21,43 +17,51 @@ import (
 
 // StatusNoti send to when module is done with report file
 func StatusNoti(notiType string, options libs.Options) {
-	SendAttachment(notiType, "", options)
+	verb := "Start to run"
+	if notiType == "done" {
+		verb = "Done run"
+	}
+	title := fmt.Sprintf("%v %v on %v", verb, options.Module.Name, options.Scan.ROptions["Workspace"])
+	if err := Notify(options, NewMessage(notiType, title, "", options)); err != nil {
+		utils.DebugF("Error sending status notification: %v", err)
+	}
 }
 
 // ReportNoti send to notification when module is done with report file
 func ReportNoti(arguments []otto.Value, options libs.Options) {
+	files := options.Module.Report.Noti
+	// if doesn't provide report file send all file in noti section
 	if len(arguments) >= 1 {
+		files = []string{}
 		for _, argument := range arguments {
-			SendFile(argument.String(), options.Noti.SlackReportChannel, options)
+			files = append(files, argument.String())
 		}
-		return
 	}
-	// if doesn't provide report file send all file in noti section
-	for _, file := range options.Module.Report.Noti {
-		SendFile(file, options.Noti.SlackReportChannel, options)
+
+	for _, file := range files {
+		if !utils.FileExists(file) {
+			continue
+		}
+		msg := NewMessage(ClassReport, fmt.Sprintf("%v - Report file for %v on %v", filepath.Base(file), options.Module.Name, options.Scan.ROptions["Workspace"]), "", options)
+		msg.File = file
+		if err := Notify(options, msg); err != nil {
+			utils.DebugF("Error sending report notification: %v", err)
+		}
 	}
 }
 
 // DiffNoti send to notification based on diff content
 func DiffNoti(arguments []otto.Value, options libs.Options) {
+	files := options.Module.Report.Diff
+	// if doesn't provide report file send all file in noti section
 	if len(arguments) >= 1 {
+		files = []string{}
 		for _, argument := range arguments {
-			filename := argument.String()
-			if !utils.FileExists(filename) {
-				continue
-			}
-			data := getNewContent(utils.ReadingLines(filename))
-			if strings.TrimSpace(data) == "" {
-				continue
-			}
-			// messageContent := fmt.Sprintf("%v \n ```%v```", filename, data)
-			// SendAttachment("diff", messageContent, options)
-			SendFile(filename, options.Noti.SlackDiffChannel, options)
+			files = append(files, argument.String())
 		}
-		return
 	}
-	// if doesn't provide report file send all file in noti section
-	for _, filename := range options.Module.Report.Diff {
+
+	for _, filename := range files {
 		if !utils.FileExists(filename) {
 			continue
 		}
@@ -65,9 +69,16 @@ func DiffNoti(arguments []otto.Value, options libs.Options) {
 		if strings.TrimSpace(data) == "" {
 			continue
 		}
-		// messageContent := fmt.Sprintf("%v \n ```%v```", filename, data)
-		// SendAttachment("diff", messageContent, options)
-		SendFile(filename, options.Noti.SlackDiffChannel, options)
+
+		msg := NewMessage(ClassDiff, fmt.Sprintf("Diff content of %v on %v", filepath.Base(filename), options.Scan.ROptions["Workspace"]), data, options)
+		// send the whole file when the diff is too big to be a message
+		if len(data) > inlineFileLimit {
+			msg.Content = ""
+			msg.File = filename
+		}
+		if err := Notify(options, msg); err != nil {
+			utils.DebugF("Error sending diff notification: %v", err)
+		}
 	}
 }
 
@@ -81,58 +92,55 @@ func getNewContent(data []string) string {
 	return result
 }
 
-// SendAttachment send attach message to specific channel
-func SendAttachment(messType string, messContent string, options libs.Options) error {
-	if options.Noti.SlackToken == "" {
-		return errors.New("Slack config improperly")
+// the classes of the legacy Telegram channels
+const (
+	ClassSensitive = "#sensitive"
+	ClassDirb      = "#dirb"
+	ClassMics      = "#mics"
+)
+
+// LegacyClass the message class of the channel alias used by the legacy Slack and Telegram functions
+func LegacyClass(channel string) string {
+	switch strings.ToLower(strings.TrimSpace(channel)) {
+	case "", "general", "#general", "#default", "custom", "start", "done":
+		return ClassStatus
+	case "#s", "#sensitive", "#sen":
+		return ClassSensitive
+	case "#dirb", "#dirscan":
+		return ClassDirb
+	case "#m", "#mics":
+		return ClassMics
 	}
+	return NormalizeClass(channel)
+}
+
+// legacyNoti send the message of the legacy functions through the rule engine
+func legacyNoti(options libs.Options, class string, event string, content string, file string) error {
+	msg := NewMessage(class, "", content, options)
+	msg.Event = event
+	msg.File = file
+	return Notify(options, msg)
+}
 
-	// choose method
-	var channel, color, mess string
+// legacyContent the content of the legacy start, done and diff messages
+func legacyContent(messType string, messContent string, options libs.Options) string {
 	switch messType {
 	case "start":
-		channel = options.Noti.SlackStatusChannel
-		color = "#005b9f"
-		mess = fmt.Sprintf("%v Start to run *%v* on *%v*", GetEmoji(), options.Module.Name, options.Scan.ROptions["Workspace"])
-		break
+		return fmt.Sprintf("%v Start to run *%v* on *%v*", GetEmoji(), options.Module.Name, options.Scan.ROptions["Workspace"])
 	case "done":
-		channel = options.Noti.SlackStatusChannel
-		color = "#32cb00"
-		mess = fmt.Sprintf("%v Done run *%v* on *%v*", GetEmoji(), options.Module.Name, options.Scan.ROptions["Workspace"])
-		break
+		return fmt.Sprintf("%v Done run *%v* on *%v*", GetEmoji(), options.Module.Name, options.Scan.ROptions["Workspace"])
 	case "diff":
-		channel = options.Noti.SlackDiffChannel
-		color = "#5E35B1"
-		mess = fmt.Sprintf("%v Diff content on %v: \n %v", GetEmoji(), options.Scan.ROptions["Workspace"], messContent)
-		break
-	case "custom":
-		channel = options.Noti.SlackStatusChannel
-		color = "#1ABC9C"
-		mess = messContent
-		break
-	}
-
-	if channel == "" || mess == "" {
-		return errors.New("Slack channel config improperly")
-	}
-	utils.DebugF("Sending %v message to %v", messType, channel)
-
-	api := slack.New(options.Noti.SlackToken)
-	// message config
-	attachment := slack.Attachment{
-		Color: color,
-		Text:  mess,
-		// sender name
-		Footer:     options.Noti.ClientName,
-		FooterIcon: GetIcon(),
-		Ts:         json.Number(strconv.FormatInt(time.Now().Unix(), 10)),
+		return fmt.Sprintf("%v Diff content on %v: \n %v", GetEmoji(), options.Scan.ROptions["Workspace"], messContent)
 	}
+	return messContent
+}
 
-	_, _, err := api.PostMessage(channel, slack.MsgOptionAttachments(attachment))
-	if err != nil {
-		return err
+// SendAttachment send attach message to specific channel
+func SendAttachment(messType string, messContent string, options libs.Options) error {
+	if messType == "" {
+		messType = "custom"
 	}
-	return nil
+	return legacyNoti(options, LegacyClass(messType), messType, legacyContent(messType, messContent, options), "")
 }
 
 // SlackWebHook send message with webhook
@@ -156,169 +164,33 @@ func SlackWebHook(webhookURL string, content string) error {
 
 // WebHookSendAttachment send attach message to specific channel
 func WebHookSendAttachment(options libs.Options, messType string, messContent string) error {
-	if options.NoNoti {
-		return fmt.Errorf("noti disabled")
-	}
-	if options.Noti.SlackWebHook == "" {
-		return errors.New("slack webhook config improperly")
-	}
-
-	// choose method
-	var color, mess string
-	switch messType {
-	case "start":
-		color = "#005b9f"
-		mess = fmt.Sprintf("%v Start to run *%v* on *%v*", GetEmoji(), options.Module.Name, options.Scan.ROptions["Workspace"])
-		break
-	case "done":
-		color = "#32cb00"
-		mess = fmt.Sprintf("%v Done run *%v* on *%v*", GetEmoji(), options.Module.Name, options.Scan.ROptions["Workspace"])
-		break
-	case "diff":
-		color = "#5E35B1"
-		mess = fmt.Sprintf("%v Diff content on %v: \n %v", GetEmoji(), options.Scan.ROptions["Workspace"], messContent)
-		break
-	case "custom":
-		color = "#1ABC9C"
-		mess = messContent
-		break
-	default:
-		color = "#1ABC9C"
-		mess = messContent
-	}
-
-	// message config
-	attachment := slack.Attachment{
-		Color: color,
-		Text:  mess,
-		// sender name
-		Footer:     options.Noti.ClientName,
-		FooterIcon: GetIcon(),
-		Ts:         json.Number(strconv.FormatInt(time.Now().Unix(), 10)),
-	}
-
-	msg := slack.WebhookMessage{
-		Attachments: []slack.Attachment{attachment},
-	}
-	err := slack.PostWebhook(options.Noti.SlackWebHook, &msg)
-	if err != nil {
-		return err
-	}
-	return nil
+	return SendAttachment(messType, messContent, options)
 }
 
 // SendFile send file to specific channel
 func SendFile(filename string, channel string, options libs.Options) error {
-	if options.Noti.SlackToken == "" || options.Noti.SlackReportChannel == "" {
-		return fmt.Errorf("Slack config improperly")
-	}
-
 	if !utils.FileExists(filename) {
 		return fmt.Errorf("report file not found: %v", filename)
 	}
-
-	baseName := filepath.Base(filename)
-	mess := fmt.Sprintf("%v - %v - Report file for *%v* on *%v*", GetEmoji(), baseName, options.Module.Name, options.Scan.ROptions["Workspace"])
-	utils.DebugF("Sending %v message to %v", filename, channel)
-
-	// sending file
-	api := slack.New(options.Noti.SlackToken)
-	params := slack.FileUploadParameters{
-		Channels: []string{channel},
-		Title:    mess,
-		Filetype: "txt",
-		File:     filename,
-	}
-	_, err := api.UploadFile(params)
-	if err != nil {
-		return err
-	}
-	return nil
+	mess := fmt.Sprintf("%v - %v - Report file for *%v* on *%v*", GetEmoji(), filepath.Base(filename), options.Module.Name, options.Scan.ROptions["Workspace"])
+	return legacyNoti(options, ClassReport, "report", mess, filename)
 }
 
 // TeleSendMess send message to telegram
 func TeleSendMess(options libs.Options, content string, channel string, wrap bool) error {
-
-	if options.NoNoti {
-		return fmt.Errorf("noti disabled")
-	}
-	bot, err := tgbotapi.NewBotAPI(options.Noti.TelegramToken)
-	content = tgbotapi.EscapeText(tgbotapi.ModeMarkdown, content)
 	if wrap {
 		content = fmt.Sprintf("```\n%s\n```", content)
 	}
-	if err != nil {
-		utils.DebugF("error init telegram: %v", err)
-		return err
-	}
-
-	if channel == "" || channel == "general" {
-		channel = options.Noti.TelegramChannel
-	}
-	switch channel {
-	case "#status":
-		channel = options.Noti.TelegramStatusChannel
-	case "#r", "#report", "#reports", "#vuln":
-		channel = options.Noti.TelegramReportChannel
-	case "#s", "#sensitive", "#sen":
-		channel = options.Noti.TelegramSensitiveChannel
-	case "#dirb", "#dirscan":
-		channel = options.Noti.TelegramDirbChannel
-	case "#m", "#mics":
-		channel = options.Noti.TelegramMicsChannel
-	case "#default", "#general":
-		channel = options.Noti.TelegramChannel
-	}
-	telechannel := cast.ToInt64(channel)
-	utils.DebugF("send message to channel %v", channel)
-	msg := tgbotapi.NewMessage(telechannel, content)
-	msg.ParseMode = "markdown"
-	_, err = bot.Send(msg)
-	if err != nil {
-		utils.DebugF("error sending telegram to %v -- %v", channel, err)
-	}
-	return err
+	return legacyNoti(options, LegacyClass(channel), "custom", content, "")
 }
 
 // TeleSendFile send message to telegram
 func TeleSendFile(options libs.Options, filename string, channel string) error {
-	if options.NoNoti {
-		return fmt.Errorf("noti disabled")
-	}
-	bot, err := tgbotapi.NewBotAPI(options.Noti.TelegramToken)
-	if err != nil {
-		utils.DebugF("error init telegram: %v", err)
-		return err
-	}
-
-	if channel == "" || channel == "general" {
-		channel = options.Noti.TelegramChannel
-	}
-	switch channel {
-	case "#status":
-		channel = options.Noti.TelegramStatusChannel
-	case "#r", "#report", "#reports", "#vuln":
-		channel = options.Noti.TelegramReportChannel
-	case "#sensitive", "#sen":
-		channel = options.Noti.TelegramSensitiveChannel
-	case "#dirb", "#dirscan":
-		channel = options.Noti.TelegramDirbChannel
-	case "#m", "#mics":
-		channel = options.Noti.TelegramMicsChannel
-	case "#default":
-		channel = options.Noti.TelegramChannel
-	}
-	telechannel := cast.ToInt64(channel)
-
 	filename = utils.NormalizePath(filename)
-
-	msg := tgbotapi.NewDocument(telechannel, tgbotapi.FilePath(filename))
-	utils.DebugF("send file %v to channel %v", filename, channel)
-	_, err = bot.Send(msg)
-	if err != nil {
-		utils.DebugF("error sending telegram to %v -- %v", channel, err)
+	if !utils.FileExists(filename) {
+		return fmt.Errorf("file not found: %v", filename)
 	}
-	return err
+	return legacyNoti(options, LegacyClass(channel), "file", "", filename)
 }
 
 /////// utils for slack message
@@ -0,0 +1,336 @@
+package execution
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// the actions of the notification rules
+const (
+	ActionSend   = "send"
+	ActionDrop   = "drop"
+	ActionDigest = "digest"
+)
+
+// RuleEngine decide whether the message is sent, dropped or batched into the digest
+// it also suppresses the duplicated messages and enforces the rate limit per notifier
+type RuleEngine struct {
+	mu             sync.Mutex
+	config         libs.Notification
+	dedupWindow    time.Duration
+	rateWindow     time.Duration
+	digestInterval time.Duration
+
+	seen        map[string]time.Time
+	sent        map[string][]time.Time
+	digest      map[string][]Message
+	digestTimer *time.Timer
+	outbox      *Outbox
+	now         func() time.Time
+}
+
+var (
+	defaultEngine   *RuleEngine
+	defaultEngineMu sync.Mutex
+)
+
+// NewRuleEngine create the rule engine from the notification config
+func NewRuleEngine(noti libs.Notification) *RuleEngine {
+	return &RuleEngine{
+		config:         noti,
+		dedupWindow:    parseWindow(noti.DedupWindow, 0),
+		rateWindow:     parseWindow(noti.RateWindow, time.Minute),
+		digestInterval: parseWindow(noti.DigestInterval, time.Hour),
+		seen:           make(map[string]time.Time),
+		sent:           make(map[string][]time.Time),
+		digest:         make(map[string][]Message),
+		now:            time.Now,
+	}
+}
+
+func parseWindow(raw string, fallback time.Duration) time.Duration {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return fallback
+	}
+	if window, err := time.ParseDuration(raw); err == nil {
+		return window
+	}
+	if seconds := utils.CalcTimeout(raw); seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return fallback
+}
+
+// Evaluate get the action of the first rule matched the message, the message is sent if there is no matched rule
+func (e *RuleEngine) Evaluate(msg Message) string {
+	for _, rule := range e.config.Rules {
+		if !matchRule(rule, msg) {
+			continue
+		}
+		action := strings.ToLower(strings.TrimSpace(rule.Action))
+		if action == "" {
+			action = ActionSend
+		}
+		utils.DebugF("Notification rule %v matched: %v", rule.Name, action)
+		return action
+	}
+	return ActionSend
+}
+
+func matchRule(rule libs.NotificationRule, msg Message) bool {
+	if len(rule.Events) > 0 && !matchAny(rule.Events, msg.Event, strings.TrimPrefix(msg.Class, "#")) {
+		return false
+	}
+	if len(rule.Flows) > 0 && !matchAny(rule.Flows, msg.Flow) {
+		return false
+	}
+	if len(rule.Modules) > 0 && !matchAny(rule.Modules, msg.Module) {
+		return false
+	}
+	if rule.MinSeverity != "" && database.SeverityRank(msg.Severity) < database.SeverityRank(rule.MinSeverity) {
+		return false
+	}
+	return true
+}
+
+// matchAny check if any of the value matches any of the glob patterns, case insensitive
+func matchAny(patterns []string, values ...string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(pattern), "#"))
+		for _, value := range values {
+			if value == "" {
+				continue
+			}
+			if ok, _ := filepath.Match(pattern, strings.ToLower(value)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isDuplicate check if the same content was sent within the dedup window, the message is recorded if not
+func (e *RuleEngine) isDuplicate(msg Message) bool {
+	if e.dedupWindow <= 0 {
+		return false
+	}
+	key := utils.GenHash(strings.Join([]string{msg.Class, msg.Title, msg.Content, msg.File}, "|"))
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := e.now()
+	for hash, at := range e.seen {
+		if now.Sub(at) >= e.dedupWindow {
+			delete(e.seen, hash)
+		}
+	}
+	if _, ok := e.seen[key]; ok {
+		return true
+	}
+	e.seen[key] = now
+	return false
+}
+
+// allow check the rate limit of the notifier, the send is recorded if allowed
+func (e *RuleEngine) allow(name string) bool {
+	if e.config.RateLimit <= 0 {
+		return true
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := e.now()
+	var recent []time.Time
+	for _, at := range e.sent[name] {
+		if now.Sub(at) < e.rateWindow {
+			recent = append(recent, at)
+		}
+	}
+	if len(recent) >= e.config.RateLimit {
+		e.sent[name] = recent
+		return false
+	}
+	e.sent[name] = append(recent, now)
+	return true
+}
+
+// addDigest batch the message for the notifier, the digest is sent after the digest interval
+func (e *RuleEngine) addDigest(name string, msg Message) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.digest[name] = append(e.digest[name], msg)
+	if e.digestTimer == nil {
+		e.digestTimer = time.AfterFunc(e.digestInterval, func() {
+			e.FlushDigest()
+		})
+	}
+}
+
+// Notify apply the rules then send the message to the notifiers routed for its class
+// the messages over the rate limit are not lost but batched into the digest
+func (e *RuleEngine) Notify(options libs.Options, msg Message) error {
+	if options.NoNoti {
+		return fmt.Errorf("noti disabled")
+	}
+	notifiers := RouteNotifiers(options.Noti, msg.Class)
+	if len(notifiers) == 0 {
+		return fmt.Errorf("no notifier for %v", msg.Class)
+	}
+
+	switch e.Evaluate(msg) {
+	case ActionDrop:
+		utils.DebugF("Dropped %v notification: %v", msg.Class, msg.Title)
+		return nil
+	case ActionDigest:
+		for _, notifier := range notifiers {
+			e.addDigest(notifier.Name(), msg)
+		}
+		return nil
+	}
+	if e.isDuplicate(msg) {
+		utils.DebugF("Suppressed duplicated %v notification: %v", msg.Class, msg.Title)
+		return nil
+	}
+
+	e.mu.Lock()
+	e.outbox = GetOutbox(options)
+	e.mu.Unlock()
+
+	var errs []string
+	for _, notifier := range notifiers {
+		if !e.allow(notifier.Name()) {
+			utils.DebugF("Rate limit of %v exceeded, batching the message into the digest", notifier.Name())
+			e.addDigest(notifier.Name(), msg)
+			continue
+		}
+		utils.DebugF("Sending %v message via %v", msg.Class, notifier.Name())
+		if err := e.deliver(notifier.Name(), options.Noti.Notifiers[notifier.Name()], msg); err != nil {
+			utils.DebugF("Error sending notification via %v: %v", notifier.Name(), err)
+			errs = append(errs, fmt.Sprintf("%v: %v", notifier.Name(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// FlushDigest send the batched messages as one digest message per notifier
+func (e *RuleEngine) FlushDigest() error {
+	e.mu.Lock()
+	pending := e.digest
+	e.digest = make(map[string][]Message)
+	if e.digestTimer != nil {
+		e.digestTimer.Stop()
+		e.digestTimer = nil
+	}
+	e.mu.Unlock()
+
+	var names []string
+	for name := range pending {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []string
+	for _, name := range names {
+		if err := e.deliver(name, e.config.Notifiers[name], DigestMessage(pending[name], e.config.ClientName)); err != nil {
+			errs = append(errs, fmt.Sprintf("%v: %v", name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// deliver send the message through the outbox so it's retried in the background if the notifier is unreachable
+func (e *RuleEngine) deliver(name string, config libs.NotifierConfig, msg Message) error {
+	e.mu.Lock()
+	outbox := e.outbox
+	e.mu.Unlock()
+
+	if outbox != nil {
+		// the entry is claimed before it's written so the background sender doesn't pick it up at the same time
+		entry, err := outbox.Claim(name, msg)
+		if err == nil {
+			if err := outbox.Send(entry); err != nil {
+				outbox.StartSender(outboxInterval)
+				return err
+			}
+			return nil
+		}
+		utils.ErrorF("Error writing the notification to the outbox: %v", err)
+	}
+
+	notifier, err := NewNotifier(name, config)
+	if err != nil {
+		return err
+	}
+	return notifier.Send(msg)
+}
+
+// DigestMessage summarize the messages into one
+func DigestMessage(messages []Message, client string) Message {
+	var lines []string
+	for _, msg := range messages {
+		// only the first line of the content is kept
+		content := strings.TrimSpace(msg.Content)
+		if index := strings.Index(content, "\n"); index > 0 {
+			content = content[:index] + " ..."
+		}
+		text := strings.TrimSpace(strings.Join([]string{msg.Title, content}, ": "))
+		text = strings.TrimSuffix(strings.TrimPrefix(text, ": "), ":")
+		if msg.File != "" {
+			text += fmt.Sprintf(" (%v)", filepath.Base(msg.File))
+		}
+		lines = append(lines, fmt.Sprintf("- %v %v %v", msg.Time, msg.Class, text))
+	}
+	return Message{
+		Class:   ClassStatus,
+		Event:   "digest",
+		Title:   fmt.Sprintf("Digest of %v notifications", len(messages)),
+		Content: strings.Join(lines, "\n"),
+		Client:  client,
+		Time:    time.Now().Format(time.RFC3339),
+	}
+}
+
+// Notify send the message through the rule engine of the process
+func Notify(options libs.Options, msg Message) error {
+	defaultEngineMu.Lock()
+	if defaultEngine == nil {
+		defaultEngine = NewRuleEngine(options.Noti)
+	}
+	engine := defaultEngine
+	defaultEngineMu.Unlock()
+	return engine.Notify(options, msg)
+}
+
+// FlushNotifications send the pending digest and the due outbox entries of the process, call it before exiting
+// the entries which are still failed stay in the outbox for the next sender
+func FlushNotifications() {
+	defaultEngineMu.Lock()
+	engine := defaultEngine
+	defaultEngineMu.Unlock()
+	if engine == nil {
+		return
+	}
+	if err := engine.FlushDigest(); err != nil {
+		utils.DebugF("Error sending the notification digest: %v", err)
+	}
+	engine.mu.Lock()
+	outbox := engine.outbox
+	engine.mu.Unlock()
+	if outbox != nil {
+		outbox.ProcessDue()
+	}
+}
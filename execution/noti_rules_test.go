@@ -0,0 +1,114 @@
+package execution
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/whoamikiddie/vulnx/libs"
+)
+
+func TestRuleEngine(t *testing.T) {
+	server, requests := newCaptureServer(t)
+	var opt libs.Options
+	opt.Noti.Notifiers = map[string]libs.NotifierConfig{
+		"ops": {Type: "webhook", URL: server.URL + "/ops"},
+	}
+	opt.Noti.Rules = []libs.NotificationRule{
+		{Name: "quiet-modules", Events: []string{"start", "done"}, Action: ActionDrop},
+		{Name: "important", Events: []string{"#report"}, MinSeverity: "high", Action: ActionSend},
+		{Name: "low-findings", Events: []string{"report"}, Action: ActionDigest},
+		{Name: "no-recon", Flows: []string{"recon*"}, Action: ActionDigest},
+	}
+	opt.Noti.DedupWindow = "10m"
+	opt.Noti.RateLimit = 2
+	opt.Noti.RateWindow = "1m"
+	engine := NewRuleEngine(opt.Noti)
+	now := time.Now()
+	engine.now = func() time.Time { return now }
+
+	opt.Scan.Flow = "general"
+	engine.Notify(opt, NewMessage("start", "Start to run probing", "", opt))
+	if len(*requests) != 0 {
+		t.Errorf("Error module status should be dropped")
+	}
+
+	critical := NewMessage(ClassReport, "New finding", "CVE-2021-44228", opt)
+	critical.Severity = "critical"
+	engine.Notify(opt, critical)
+	engine.Notify(opt, critical)
+	if len(*requests) != 1 {
+		t.Errorf("Error high severity should be sent once: %v", len(*requests))
+	}
+
+	low := NewMessage(ClassReport, "New finding", "missing header", opt)
+	low.Severity = "low"
+	engine.Notify(opt, low)
+	opt.Scan.Flow = "recon-lite"
+	engine.Notify(opt, NewMessage("scan-start", "Start new scan", "", opt))
+	if len(*requests) != 1 || len(engine.digest["ops"]) != 2 {
+		t.Errorf("Error batching into the digest: %v %v", len(*requests), len(engine.digest["ops"]))
+	}
+
+	// the second message reach the rate limit, the third one goes to the digest
+	opt.Scan.Flow = "general"
+	engine.Notify(opt, NewMessage(ClassDiff, "Diff", "+a.example.com", opt))
+	engine.Notify(opt, NewMessage(ClassDiff, "Diff", "+b.example.com", opt))
+	if len(*requests) != 2 || len(engine.digest["ops"]) != 3 {
+		t.Errorf("Error rate limit: %v %v", len(*requests), len(engine.digest["ops"]))
+	}
+
+	// the duplicated content is sent again after the window
+	now = now.Add(11 * time.Minute)
+	engine.Notify(opt, critical)
+	if len(*requests) != 3 {
+		t.Errorf("Error dedup window: %v", len(*requests))
+	}
+
+	if err := engine.FlushDigest(); err != nil {
+		t.Fatalf("Error FlushDigest: %v", err)
+	}
+	last := (*requests)[len(*requests)-1]
+	if len(*requests) != 4 || !strings.Contains(last.Body, "Digest of 3 notifications") || !strings.Contains(last.Body, "+b.example.com") {
+		t.Errorf("Error sending the digest: %v", last.Body)
+	}
+	if len(engine.digest) != 0 {
+		t.Errorf("Error the digest should be empty after flushing")
+	}
+}
+
+// the engine of the process is flushed while the messages are still sent, run with -race
+func TestFlushNotifications(t *testing.T) {
+	server, _ := newCaptureServer(t)
+	var opt libs.Options
+	opt.Env.RootFolder = t.TempDir()
+	opt.Noti.Notifiers = map[string]libs.NotifierConfig{
+		"ops": {Type: "webhook", URL: server.URL + "/ops"},
+	}
+	defaultEngineMu.Lock()
+	saved := defaultEngine
+	defaultEngine = nil
+	defaultEngineMu.Unlock()
+	t.Cleanup(func() {
+		defaultEngineMu.Lock()
+		defaultEngine = saved
+		defaultEngineMu.Unlock()
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			Notify(opt, NewMessage(ClassStatus, "", fmt.Sprintf("status %v", i), opt))
+		}(i)
+		go func() {
+			defer wg.Done()
+			FlushNotifications()
+		}()
+	}
+	wg.Wait()
+	FlushNotifications()
+}
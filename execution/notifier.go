@@ -0,0 +1,527 @@
+package execution
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// the message classes which could be routed to different notifiers
+const (
+	ClassStatus = "#status"
+	ClassReport = "#report"
+	ClassDiff   = "#diff"
+)
+
+// the maximum size of the file content inlined in the message when the notifier can't upload files
+const inlineFileLimit = 3000
+
+var classColors = map[string]string{
+	ClassStatus: "#32cb00",
+	ClassReport: "#005b9f",
+	ClassDiff:   "#5E35B1",
+}
+
+// Message the notification sent to the notifiers
+type Message struct {
+	Class     string `json:"class"`
+	Event     string `json:"event,omitempty"`
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	File      string `json:"file,omitempty"`
+	Workspace string `json:"workspace,omitempty"`
+	Module    string `json:"module,omitempty"`
+	Flow      string `json:"flow,omitempty"`
+	Severity  string `json:"severity,omitempty"`
+	Client    string `json:"client,omitempty"`
+	Time      string `json:"time"`
+}
+
+// Text the plain text of the message
+func (m Message) Text() string {
+	if m.Title == "" {
+		return m.Content
+	}
+	if m.Content == "" {
+		return m.Title
+	}
+	return m.Title + "\n" + m.Content
+}
+
+// Color the color of the message class
+func (m Message) Color() string {
+	if color, ok := classColors[m.Class]; ok {
+		return color
+	}
+	return "#1ABC9C"
+}
+
+// Notifier send the message to a channel
+type Notifier interface {
+	Name() string
+	Send(msg Message) error
+}
+
+// NewMessage create a message of the class with the scan information
+// the class could be an event alias like #start, it's kept as the event of the message
+func NewMessage(class string, title string, content string, options libs.Options) Message {
+	return Message{
+		Class:     NormalizeClass(class),
+		Event:     strings.ToLower(strings.TrimPrefix(strings.TrimSpace(class), "#")),
+		Title:     title,
+		Content:   content,
+		Workspace: options.Scan.ROptions["Workspace"],
+		Module:    options.Module.Name,
+		Flow:      options.Scan.Flow,
+		Client:    options.Noti.ClientName,
+		Time:      time.Now().Format(time.RFC3339),
+	}
+}
+
+// NormalizeClass map the aliases of the message class
+func NormalizeClass(class string) string {
+	class = strings.ToLower(strings.TrimSpace(class))
+	if !strings.HasPrefix(class, "#") {
+		class = "#" + class
+	}
+	switch class {
+	case "#r", "#reports", "#vuln":
+		return ClassReport
+	case "#start", "#done", "#scan-start", "#scan-done":
+		return ClassStatus
+	}
+	return class
+}
+
+// NewNotifier create the notifier from the config
+func NewNotifier(name string, config libs.NotifierConfig) (Notifier, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	switch strings.ToLower(config.Type) {
+	case "slack":
+		return &SlackNotifier{name: name, config: config, client: client}, nil
+	case "telegram":
+		return &TelegramNotifier{name: name, config: config, client: client}, nil
+	case "discord":
+		return &DiscordNotifier{name: name, config: config, client: client}, nil
+	case "teams":
+		return &TeamsNotifier{name: name, config: config, client: client}, nil
+	case "matrix":
+		return &MatrixNotifier{name: name, config: config, client: client}, nil
+	case "smtp", "email":
+		return &SMTPNotifier{name: name, config: config}, nil
+	case "webhook":
+		return &WebhookNotifier{name: name, config: config, client: client}, nil
+	}
+	return nil, fmt.Errorf("unknown notifier type %v of %v", config.Type, name)
+}
+
+// RouteNotifiers get the notifiers routed for the message class
+func RouteNotifiers(noti libs.Notification, class string) (notifiers []Notifier) {
+	class = NormalizeClass(class)
+	var names []string
+	for route, routeNames := range noti.Routes {
+		if NormalizeClass(route) == class {
+			names = append(names, routeNames...)
+		}
+	}
+	if len(noti.Routes) == 0 {
+		for name := range noti.Notifiers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	for _, name := range names {
+		config, ok := noti.Notifiers[name]
+		if !ok {
+			utils.WarnF("Notifier %v not found", name)
+			continue
+		}
+		notifier, err := NewNotifier(name, config)
+		if err != nil {
+			utils.ErrorF("%v", err)
+			continue
+		}
+		notifiers = append(notifiers, notifier)
+	}
+	return notifiers
+}
+
+// channelOf get the channel of the message class, fall back to the default channel
+func channelOf(config libs.NotifierConfig, class string) string {
+	for key, channel := range config.Channels {
+		if NormalizeClass(key) == class && channel != "" {
+			return channel
+		}
+	}
+	return config.Channel
+}
+
+// inlineFile append the file content to the message for the notifiers which can't upload files
+func inlineFile(msg Message) string {
+	text := msg.Text()
+	if msg.File == "" {
+		return text
+	}
+	content := utils.GetFileContent(msg.File)
+	if len(content) > inlineFileLimit {
+		content = content[:inlineFileLimit] + "\n..."
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%s\n%s\n```\n%s\n```", text, filepath.Base(msg.File), strings.TrimSpace(content)), "\n")
+}
+
+// sendJSON send the JSON body and check the response status
+func sendJSON(client *http.Client, method string, endpoint string, headers map[string]string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	if _, ok := headers["Content-Type"]; !ok {
+		headers["Content-Type"] = "application/json"
+	}
+	return sendRequest(client, method, endpoint, headers, bytes.NewReader(data))
+}
+
+func sendRequest(client *http.Client, method string, endpoint string, headers map[string]string, body io.Reader) error {
+	req, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %v: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// sendMultipart upload the file with the form fields
+func sendMultipart(client *http.Client, endpoint string, headers map[string]string, fields map[string]string, fileField string, filename string) error {
+	f, err := os.Open(utils.NormalizePath(filename))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for key, value := range fields {
+		writer.WriteField(key, value)
+	}
+	part, err := writer.CreateFormFile(fileField, filepath.Base(filename))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return err
+	}
+	writer.Close()
+
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	headers["Content-Type"] = writer.FormDataContentType()
+	return sendRequest(client, http.MethodPost, endpoint, headers, &body)
+}
+
+// SlackNotifier send the message with the webhook URL or with the API token to the channel
+type SlackNotifier struct {
+	name   string
+	config libs.NotifierConfig
+	client *http.Client
+}
+
+// Name of the notifier
+func (n *SlackNotifier) Name() string { return n.name }
+
+// Send the message
+func (n *SlackNotifier) Send(msg Message) error {
+	attachment := map[string]interface{}{
+		"color":  msg.Color(),
+		"title":  msg.Title,
+		"text":   msg.Content,
+		"footer": msg.Client,
+		"ts":     time.Now().Unix(),
+	}
+	if n.config.Token == "" {
+		if msg.File != "" {
+			attachment["text"] = inlineFile(Message{Content: msg.Content, File: msg.File})
+		}
+		return sendJSON(n.client, http.MethodPost, n.config.URL, nil, map[string]interface{}{
+			"attachments": []interface{}{attachment},
+		})
+	}
+
+	api := strings.TrimSuffix(n.config.URL, "/")
+	if api == "" {
+		api = "https://slack.com/api"
+	}
+	channel := channelOf(n.config, msg.Class)
+	if channel == "" {
+		return fmt.Errorf("slack channel config improperly")
+	}
+	headers := map[string]string{"Authorization": "Bearer " + n.config.Token}
+	if msg.File != "" {
+		return sendMultipart(n.client, api+"/files.upload", headers, map[string]string{
+			"channels":        channel,
+			"title":           msg.Title,
+			"initial_comment": msg.Content,
+		}, "file", msg.File)
+	}
+	return sendJSON(n.client, http.MethodPost, api+"/chat.postMessage", headers, map[string]interface{}{
+		"channel":     channel,
+		"attachments": []interface{}{attachment},
+	})
+}
+
+// TelegramNotifier send the message with the bot API to the chat
+type TelegramNotifier struct {
+	name   string
+	config libs.NotifierConfig
+	client *http.Client
+}
+
+// Name of the notifier
+func (n *TelegramNotifier) Name() string { return n.name }
+
+// Send the message
+func (n *TelegramNotifier) Send(msg Message) error {
+	api := strings.TrimSuffix(n.config.URL, "/")
+	if api == "" {
+		api = "https://api.telegram.org"
+	}
+	endpoint := fmt.Sprintf("%s/bot%s", api, n.config.Token)
+	chatID := channelOf(n.config, msg.Class)
+	if n.config.Token == "" || chatID == "" {
+		return fmt.Errorf("telegram config improperly")
+	}
+
+	if msg.File != "" {
+		return sendMultipart(n.client, endpoint+"/sendDocument", nil, map[string]string{
+			"chat_id": chatID,
+			"caption": msg.Text(),
+		}, "document", msg.File)
+	}
+	return sendJSON(n.client, http.MethodPost, endpoint+"/sendMessage", nil, map[string]interface{}{
+		"chat_id": chatID,
+		"text":    msg.Text(),
+	})
+}
+
+// DiscordNotifier send the message to the Discord webhook
+type DiscordNotifier struct {
+	name   string
+	config libs.NotifierConfig
+	client *http.Client
+}
+
+// Name of the notifier
+func (n *DiscordNotifier) Name() string { return n.name }
+
+// Send the message
+func (n *DiscordNotifier) Send(msg Message) error {
+	var color int64
+	fmt.Sscanf(strings.TrimPrefix(msg.Color(), "#"), "%x", &color)
+	content := msg.Content
+	// the limit of the embed description
+	if len(content) > 4000 {
+		content = content[:4000] + "\n..."
+	}
+	payload := map[string]interface{}{
+		"username": msg.Client,
+		"embeds": []interface{}{
+			map[string]interface{}{
+				"title":       msg.Title,
+				"description": content,
+				"color":       color,
+			},
+		},
+	}
+	if msg.File == "" {
+		return sendJSON(n.client, http.MethodPost, n.config.URL, nil, payload)
+	}
+	data, _ := json.Marshal(payload)
+	return sendMultipart(n.client, n.config.URL, nil, map[string]string{"payload_json": string(data)}, "file", msg.File)
+}
+
+// TeamsNotifier send the message card to the Microsoft Teams incoming webhook
+type TeamsNotifier struct {
+	name   string
+	config libs.NotifierConfig
+	client *http.Client
+}
+
+// Name of the notifier
+func (n *TeamsNotifier) Name() string { return n.name }
+
+// Send the message
+func (n *TeamsNotifier) Send(msg Message) error {
+	title := msg.Title
+	if title == "" {
+		title = msg.Class
+	}
+	return sendJSON(n.client, http.MethodPost, n.config.URL, nil, map[string]interface{}{
+		"@type":      "MessageCard",
+		"@context":   "http://schema.org/extensions",
+		"themeColor": strings.TrimPrefix(msg.Color(), "#"),
+		"summary":    title,
+		"title":      title,
+		"text":       strings.ReplaceAll(inlineFile(Message{Content: msg.Content, File: msg.File}), "\n", "<br>"),
+	})
+}
+
+// MatrixNotifier send the message to the Matrix room with the access token
+type MatrixNotifier struct {
+	name   string
+	config libs.NotifierConfig
+	client *http.Client
+}
+
+// Name of the notifier
+func (n *MatrixNotifier) Name() string { return n.name }
+
+// Send the message
+func (n *MatrixNotifier) Send(msg Message) error {
+	room := channelOf(n.config, msg.Class)
+	if n.config.URL == "" || n.config.Token == "" || room == "" {
+		return fmt.Errorf("matrix config improperly")
+	}
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimSuffix(n.config.URL, "/"), url.PathEscape(room), utils.RandomString(16))
+	return sendJSON(n.client, http.MethodPut, endpoint, map[string]string{"Authorization": "Bearer " + n.config.Token}, map[string]interface{}{
+		"msgtype": "m.text",
+		"body":    inlineFile(msg),
+	})
+}
+
+// SMTPNotifier send the message as an email, the file is attached
+type SMTPNotifier struct {
+	name   string
+	config libs.NotifierConfig
+}
+
+// Name of the notifier
+func (n *SMTPNotifier) Name() string { return n.name }
+
+// Send the message
+func (n *SMTPNotifier) Send(msg Message) error {
+	if n.config.Host == "" || n.config.From == "" || len(n.config.To) == 0 {
+		return fmt.Errorf("smtp config improperly")
+	}
+	port := n.config.Port
+	if port == 0 {
+		port = 25
+	}
+	var auth smtp.Auth
+	if n.config.Username != "" {
+		auth = smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)
+	}
+
+	subject := mailSubject(msg)
+	boundary := utils.RandomString(24)
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nMIME-Version: 1.0\r\n",
+		n.config.From, strings.Join(n.config.To, ", "), subject, time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&body, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", boundary)
+	fmt.Fprintf(&body, "--%s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n", boundary, msg.Content)
+	if msg.File != "" {
+		data, err := os.ReadFile(utils.NormalizePath(msg.File))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&body, "--%s\r\nContent-Type: application/octet-stream\r\nContent-Transfer-Encoding: base64\r\n", boundary)
+		fmt.Fprintf(&body, "Content-Disposition: attachment; filename=%q\r\n\r\n", filepath.Base(msg.File))
+		encoded := base64.StdEncoding.EncodeToString(data)
+		for len(encoded) > 76 {
+			body.WriteString(encoded[:76] + "\r\n")
+			encoded = encoded[76:]
+		}
+		body.WriteString(encoded + "\r\n")
+	}
+	fmt.Fprintf(&body, "--%s--\r\n", boundary)
+
+	return smtp.SendMail(fmt.Sprintf("%s:%d", n.config.Host, port), auth, n.config.From, n.config.To, body.Bytes())
+}
+
+// mailSubject the subject header of the message, the line breaks are removed so the title can't add the headers
+// and the non ASCII title is encoded
+func mailSubject(msg Message) string {
+	subject := msg.Title
+	if subject == "" {
+		subject = fmt.Sprintf("[%s] %s", strings.TrimPrefix(msg.Class, "#"), msg.Workspace)
+	}
+	subject = strings.Join(strings.Fields(subject), " ")
+	return mime.QEncoding.Encode("utf-8", subject)
+}
+
+// defaultWebhookTemplate the body of the generic webhook when there is no template
+const defaultWebhookTemplate = `{"class": {{ json .Class }}, "title": {{ json .Title }}, "content": {{ json .Content }}, "workspace": {{ json .Workspace }}, "module": {{ json .Module }}, "time": {{ json .Time }}}`
+
+// WebhookNotifier render the message with the template and send it to the URL
+type WebhookNotifier struct {
+	name   string
+	config libs.NotifierConfig
+	client *http.Client
+}
+
+// Name of the notifier
+func (n *WebhookNotifier) Name() string { return n.name }
+
+// Send the message
+func (n *WebhookNotifier) Send(msg Message) error {
+	raw := n.config.Template
+	if raw == "" {
+		raw = defaultWebhookTemplate
+	}
+	tpl, err := template.New(n.name).Funcs(template.FuncMap{
+		"json": func(value interface{}) string {
+			data, _ := json.Marshal(value)
+			return string(data)
+		},
+	}).Parse(raw)
+	if err != nil {
+		return err
+	}
+
+	msg.Content = inlineFile(Message{Content: msg.Content, File: msg.File})
+	var body bytes.Buffer
+	if err := tpl.Execute(&body, msg); err != nil {
+		return err
+	}
+
+	method := strings.ToUpper(n.config.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+	headers := map[string]string{"Content-Type": "application/json"}
+	for key, value := range n.config.Headers {
+		headers[key] = value
+	}
+	return sendRequest(n.client, method, n.config.URL, headers, &body)
+}
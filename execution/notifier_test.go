@@ -0,0 +1,243 @@
+package execution
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+type capturedRequest struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   string
+}
+
+func newCaptureServer(t *testing.T) (*httptest.Server, *[]capturedRequest) {
+	var mu sync.Mutex
+	var requests []capturedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, capturedRequest{Method: r.Method, Path: r.URL.Path, Header: r.Header, Body: string(body)})
+		mu.Unlock()
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestNotifiers(t *testing.T) {
+	server, requests := newCaptureServer(t)
+	report := path.Join(t.TempDir(), "vuln.txt")
+	utils.WriteToFile(report, "[critical] https://example.com/.git/config")
+
+	var opt libs.Options
+	opt.Noti.ClientName = "test-client"
+	opt.Noti.Notifiers = map[string]libs.NotifierConfig{
+		"slack-hook": {Type: "slack", URL: server.URL + "/slack"},
+		"slack-api":  {Type: "slack", URL: server.URL + "/slack-api", Token: "xoxb-token", Channels: map[string]string{"report": "C-REPORT"}},
+		"telegram":   {Type: "telegram", URL: server.URL, Token: "123:abc", Channel: "-100"},
+		"discord":    {Type: "discord", URL: server.URL + "/discord"},
+		"teams":      {Type: "teams", URL: server.URL + "/teams"},
+		"matrix":     {Type: "matrix", URL: server.URL, Token: "syt_token", Channel: "!room:example.com"},
+		"webhook": {Type: "webhook", URL: server.URL + "/hook", Method: "put", Headers: map[string]string{"X-Token": "secret"},
+			Template: `{"text": {{ json .Title }}, "ws": {{ json .Workspace }}}`},
+	}
+	opt.Scan.ROptions = map[string]string{"Workspace": "example.com"}
+
+	msg := NewMessage("#r", "Report file", "", opt)
+	msg.File = report
+	if err := Notify(opt, msg); err != nil {
+		t.Fatalf("Error Notify: %v", err)
+	}
+	if len(*requests) != 7 {
+		t.Fatalf("Error sending to all the notifiers: %v", len(*requests))
+	}
+
+	seen := make(map[string]capturedRequest)
+	for _, req := range *requests {
+		seen[req.Path] = req
+	}
+	if req := seen["/slack"]; !strings.Contains(req.Body, "example.com/.git/config") {
+		t.Errorf("Error slack webhook should inline the file: %v", req.Body)
+	}
+	if req := seen["/slack-api/files.upload"]; req.Header.Get("Authorization") != "Bearer xoxb-token" || !strings.Contains(req.Body, "C-REPORT") {
+		t.Errorf("Error slack file upload: %v", req)
+	}
+	if req := seen["/bot123:abc/sendDocument"]; !strings.Contains(req.Body, "-100") || !strings.Contains(req.Body, "vuln.txt") {
+		t.Errorf("Error telegram document: %v", req)
+	}
+	if req := seen["/discord"]; !strings.Contains(req.Header.Get("Content-Type"), "multipart/form-data") || !strings.Contains(req.Body, "payload_json") {
+		t.Errorf("Error discord file upload: %v", req)
+	}
+	if req := seen["/teams"]; !strings.Contains(req.Body, "MessageCard") {
+		t.Errorf("Error teams message card: %v", req.Body)
+	}
+	for p, req := range seen {
+		if strings.HasPrefix(p, "/_matrix/client/v3/rooms/!room:example.com/send/m.room.message/") {
+			if req.Method != http.MethodPut || req.Header.Get("Authorization") != "Bearer syt_token" {
+				t.Errorf("Error matrix message: %v", req)
+			}
+		}
+	}
+
+	req := seen["/hook"]
+	var payload map[string]string
+	if err := json.Unmarshal([]byte(req.Body), &payload); err != nil || payload["text"] != "Report file" || payload["ws"] != "example.com" {
+		t.Errorf("Error rendering the webhook template: %v -- %v", req.Body, err)
+	}
+	if req.Method != http.MethodPut || req.Header.Get("X-Token") != "secret" {
+		t.Errorf("Error webhook method or header: %v", req)
+	}
+}
+
+func TestRouteNotifiers(t *testing.T) {
+	server, requests := newCaptureServer(t)
+	var opt libs.Options
+	opt.Noti.Notifiers = map[string]libs.NotifierConfig{
+		"ops":    {Type: "discord", URL: server.URL + "/ops"},
+		"vulns":  {Type: "webhook", URL: server.URL + "/vulns"},
+		"broken": {Type: "webhook", URL: server.URL + "/broken"},
+	}
+	opt.Noti.Routes = map[string][]string{
+		"#status": {"ops"},
+		"report":  {"vulns", "ops"},
+		"#diff":   {"unknown"},
+	}
+
+	if names := len(RouteNotifiers(opt.Noti, "#reports")); names != 2 {
+		t.Errorf("Error routing the report class: %v", names)
+	}
+	Notify(opt, NewMessage("done", "Done run", "", opt))
+	if len(*requests) != 1 || (*requests)[0].Path != "/ops" {
+		t.Errorf("Error routing the status class: %v", *requests)
+	}
+	if err := Notify(opt, NewMessage(ClassDiff, "", "+new.example.com", opt)); err == nil {
+		t.Errorf("Error Notify should fail without any notifier")
+	}
+
+	opt.NoNoti = true
+	if err := Notify(opt, NewMessage(ClassStatus, "", "", opt)); err == nil {
+		t.Errorf("Error Notify should respect the no notification option")
+	}
+	if _, err := NewNotifier("x", libs.NotifierConfig{Type: "pigeon"}); err == nil {
+		t.Errorf("Error NewNotifier should reject unknown type")
+	}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		conn.Write([]byte("220 localhost ESMTP\r\n"))
+		var data strings.Builder
+		inData := false
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if inData {
+				if line == ".\r\n" {
+					inData = false
+					received <- data.String()
+					conn.Write([]byte("250 OK\r\n"))
+					continue
+				}
+				data.WriteString(line)
+				continue
+			}
+			switch strings.ToUpper(strings.Fields(line)[0]) {
+			case "EHLO", "HELO":
+				conn.Write([]byte("250 localhost\r\n"))
+			case "DATA":
+				inData = true
+				conn.Write([]byte("354 go ahead\r\n"))
+			case "QUIT":
+				conn.Write([]byte("221 bye\r\n"))
+				return
+			default:
+				conn.Write([]byte("250 OK\r\n"))
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	report := path.Join(t.TempDir(), "report.txt")
+	utils.WriteToFile(report, "a.example.com")
+	notifier, _ := NewNotifier("mail", libs.NotifierConfig{Type: "smtp", Host: host, Port: utils.StrToInt(port), From: "osm@example.com", To: []string{"team@example.com"}})
+
+	msg := Message{Class: ClassReport, Title: "New report", Content: "see attachment", File: report}
+	if err := notifier.Send(msg); err != nil {
+		t.Fatalf("Error SMTP Send: %v", err)
+	}
+	data := <-received
+	if !strings.Contains(data, "Subject: New report") || !strings.Contains(data, `filename="report.txt"`) {
+		t.Errorf("Error SMTP message: %v", data)
+	}
+}
+
+func TestMailSubject(t *testing.T) {
+	cases := []struct {
+		msg     Message
+		subject string
+	}{
+		{Message{Title: "New report"}, "New report"},
+		{Message{Class: ClassReport, Workspace: "example.com"}, "[report] example.com"},
+		{Message{Title: "scan done\r\nBcc: attacker@example.com"}, "scan done Bcc: attacker@example.com"},
+		{Message{Title: "scan\ndone\r"}, "scan done"},
+		{Message{Title: "résumé"}, "=?utf-8?q?r=C3=A9sum=C3=A9?="},
+	}
+	for _, tc := range cases {
+		if subject := mailSubject(tc.msg); subject != tc.subject {
+			t.Errorf("Error mailSubject(%q) = %q, want %q", tc.msg.Title, subject, tc.subject)
+		}
+	}
+}
+
+func TestLegacyNoti(t *testing.T) {
+	server, requests := newCaptureServer(t)
+	var opt libs.Options
+	opt.Noti.Notifiers = map[string]libs.NotifierConfig{
+		"telegram": {Type: "telegram", URL: server.URL, Token: "123:abc", Channel: "-100",
+			Channels: map[string]string{ClassSensitive: "-200"}},
+	}
+
+	if err := TeleSendMess(opt, "token found", "#sen", true); err != nil {
+		t.Fatalf("Error TeleSendMess: %v", err)
+	}
+	if err := SendAttachment("custom", "custom message", opt); err != nil {
+		t.Fatalf("Error SendAttachment: %v", err)
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("Error legacy functions sent %v requests", len(*requests))
+	}
+	if body := (*requests)[0].Body; !strings.Contains(body, `"chat_id":"-200"`) || !strings.Contains(body, "token found") {
+		t.Errorf("Error TeleSendMess should be routed to the sensitive channel: %v", body)
+	}
+	if body := (*requests)[1].Body; !strings.Contains(body, `"chat_id":"-100"`) {
+		t.Errorf("Error SendAttachment should be routed to the default channel: %v", body)
+	}
+}
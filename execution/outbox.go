@@ -0,0 +1,380 @@
+package execution
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/metrics"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// the status of the outbox entry
+const (
+	OutboxPending = "pending"
+	OutboxFailed  = "failed"
+)
+
+const (
+	// outboxMaxAttempts the entry is marked as failed after that, it could still be retried manually
+	outboxMaxAttempts = 15
+	// outboxClaimTimeout the claimed entry is released if the sender died while delivering it
+	outboxClaimTimeout = 10 * time.Minute
+	// outboxInterval how often the background sender checks the outbox
+	outboxInterval = time.Minute
+	outboxExt      = ".json"
+	outboxClaimExt = ".sending"
+)
+
+// ErrOutboxClaimed the entry is being delivered by another sender
+var ErrOutboxClaimed = errors.New("the entry is claimed by another sender")
+
+// OutboxEntry a notification waiting to be delivered to a notifier
+// only the name of the notifier is kept, its credentials are read from the config when the entry is sent
+type OutboxEntry struct {
+	ID          string    `json:"id"`
+	Notifier    string    `json:"notifier"`
+	Message     Message   `json:"message"`
+	Status      string    `json:"status"`
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"last_error,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	NextAttempt time.Time `json:"next_attempt"`
+}
+
+// Outbox the durable queue of the notifications, each entry is a JSON file in the folder
+type Outbox struct {
+	Dir string
+
+	mu         sync.Mutex
+	notifiers  map[string]libs.NotifierConfig
+	senderOnce sync.Once
+	wake       chan struct{}
+	now        func() time.Time
+}
+
+var (
+	outboxes   = make(map[string]*Outbox)
+	outboxesMu sync.Mutex
+)
+
+// NewOutbox create the outbox in the folder
+func NewOutbox(dir string) *Outbox {
+	return &Outbox{Dir: dir, wake: make(chan struct{}, 1), now: time.Now}
+}
+
+// OutboxDir the folder of the outbox under the root folder, it's empty if the root folder is not set
+func OutboxDir(options libs.Options) string {
+	if options.Env.RootFolder == "" {
+		return ""
+	}
+	return path.Join(utils.NormalizePath(options.Env.RootFolder), "outbox")
+}
+
+// GetOutbox get the outbox of the root folder, it returns nil if the root folder is not set
+// the entries are sent with the notifiers of the options
+func GetOutbox(options libs.Options) *Outbox {
+	dir := OutboxDir(options)
+	if dir == "" {
+		return nil
+	}
+	outboxesMu.Lock()
+	defer outboxesMu.Unlock()
+	outbox, ok := outboxes[dir]
+	if !ok {
+		outbox = NewOutbox(dir)
+		outboxes[dir] = outbox
+	}
+	outbox.SetNotifiers(options.Noti.Notifiers)
+	return outbox
+}
+
+// SetNotifiers set the config of the notifiers the entries are sent with
+func (o *Outbox) SetNotifiers(notifiers map[string]libs.NotifierConfig) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.notifiers = notifiers
+}
+
+// notifier create the notifier of the entry from the current config
+func (o *Outbox) notifier(name string) (Notifier, error) {
+	o.mu.Lock()
+	config, ok := o.notifiers[name]
+	o.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("notifier %v is not in the config", name)
+	}
+	return NewNotifier(name, config)
+}
+
+// newEntry the entry of the notification, due now
+func (o *Outbox) newEntry(name string, msg Message) OutboxEntry {
+	now := o.now()
+	return OutboxEntry{
+		ID:          fmt.Sprintf("%d-%s", now.UnixNano(), strings.ToLower(utils.RandomString(6))),
+		Notifier:    name,
+		Message:     msg,
+		Status:      OutboxPending,
+		CreatedAt:   now,
+		NextAttempt: now,
+	}
+}
+
+// Add write the notification to the outbox, any sender could deliver it
+func (o *Outbox) Add(name string, msg Message) (OutboxEntry, error) {
+	entry := o.newEntry(name, msg)
+	return entry, o.write(entry, outboxExt)
+}
+
+// Claim write the notification to the outbox already claimed by the caller, so no other sender could deliver it
+// until the caller sends it with Send
+func (o *Outbox) Claim(name string, msg Message) (OutboxEntry, error) {
+	entry := o.newEntry(name, msg)
+	return entry, o.write(entry, outboxExt+outboxClaimExt)
+}
+
+// save write the entry so any sender could deliver it
+func (o *Outbox) save(entry OutboxEntry) error {
+	return o.write(entry, outboxExt)
+}
+
+// write the entry atomically so a crash never leaves a truncated file
+func (o *Outbox) write(entry OutboxEntry, ext string) error {
+	if err := os.MkdirAll(o.Dir, 0750); err != nil {
+		return err
+	}
+	data, err := jsoniter.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path.Join(o.Dir, "."+entry.ID+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path.Join(o.Dir, entry.ID+ext))
+}
+
+// List get all the entries of the outbox, oldest first
+// the entries claimed by a sender which didn't finish are released first
+func (o *Outbox) List() ([]OutboxEntry, error) {
+	files, err := os.ReadDir(o.Dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), outboxClaimExt) {
+			continue
+		}
+		if info, err := file.Info(); err == nil && o.now().Sub(info.ModTime()) > outboxClaimTimeout {
+			filename := path.Join(o.Dir, file.Name())
+			os.Rename(filename, strings.TrimSuffix(filename, outboxClaimExt))
+		}
+	}
+	return ReadOutbox(o.Dir)
+}
+
+// ReadOutbox get all the entries of the outbox folder, oldest first, nothing in the folder is changed
+// the claimed entries are being sent so they're skipped
+func ReadOutbox(dir string) ([]OutboxEntry, error) {
+	var entries []OutboxEntry
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return entries, err
+	}
+
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), outboxExt) || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		filename := path.Join(dir, file.Name())
+		var entry OutboxEntry
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			continue
+		}
+		if err := jsoniter.Unmarshal(data, &entry); err != nil {
+			utils.DebugF("Error parsing outbox entry %v: %v", filename, err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries, nil
+}
+
+// Deliver claim the entry and send it, ErrOutboxClaimed is returned if another sender got it first
+func (o *Outbox) Deliver(entry OutboxEntry) error {
+	filename := path.Join(o.Dir, entry.ID+outboxExt)
+	// only one sender could claim the entry
+	if err := os.Rename(filename, filename+outboxClaimExt); err != nil {
+		return ErrOutboxClaimed
+	}
+	return o.Send(entry)
+}
+
+// Send send the entry claimed by the caller, it's removed from the outbox when delivered or rescheduled with the backoff when failed
+func (o *Outbox) Send(entry OutboxEntry) error {
+	claimed := path.Join(o.Dir, entry.ID+outboxExt+outboxClaimExt)
+	notifier, err := o.notifier(entry.Notifier)
+	if err == nil {
+		err = notifier.Send(entry.Message)
+	}
+	if err == nil {
+		os.Remove(claimed)
+		return nil
+	}
+
+	metrics.NotificationFailures.WithLabelValues(entry.Notifier).Inc()
+	entry.Attempts++
+	entry.LastError = err.Error()
+	entry.NextAttempt = o.now().Add(outboxBackOff(entry.Attempts))
+	if entry.Attempts >= outboxMaxAttempts {
+		entry.Status = OutboxFailed
+	}
+	if saveErr := o.save(entry); saveErr != nil {
+		utils.ErrorF("Error saving outbox entry %v: %v", entry.ID, saveErr)
+	}
+	os.Remove(claimed)
+	return err
+}
+
+// outboxBackOff the delay before the next attempt
+func outboxBackOff(attempts int) time.Duration {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = 10 * time.Second
+	b.Multiplier = 2.0
+	b.MaxInterval = 30 * time.Minute
+	b.MaxElapsedTime = 0
+	delay := b.InitialInterval
+	for i := 0; i < attempts; i++ {
+		delay = b.NextBackOff()
+	}
+	return delay
+}
+
+// ProcessDue deliver the pending entries which are due
+func (o *Outbox) ProcessDue() (sent int, failed int) {
+	entries, err := o.List()
+	if err != nil {
+		utils.DebugF("Error reading the outbox: %v", err)
+		return sent, failed
+	}
+	now := o.now()
+	for _, entry := range entries {
+		if entry.Status != OutboxPending || entry.NextAttempt.After(now) {
+			continue
+		}
+		err := o.Deliver(entry)
+		if errors.Is(err, ErrOutboxClaimed) {
+			continue
+		}
+		if err != nil {
+			utils.DebugF("Error delivering outbox entry %v via %v: %v", entry.ID, entry.Notifier, err)
+			failed++
+			continue
+		}
+		sent++
+	}
+	return sent, failed
+}
+
+// Retry make the entries due now and deliver them, all the entries are retried if no ID is given
+func (o *Outbox) Retry(ids ...string) (sent int, failed int, err error) {
+	entries, err := o.List()
+	if err != nil {
+		return sent, failed, err
+	}
+	for _, entry := range entries {
+		if len(ids) > 0 && !matchID(entry.ID, ids) {
+			continue
+		}
+		entry.Status = OutboxPending
+		entry.NextAttempt = o.now()
+		err := o.Deliver(entry)
+		if errors.Is(err, ErrOutboxClaimed) {
+			continue
+		}
+		if err != nil {
+			utils.WarnF("Error delivering %v via %v: %v", entry.ID, entry.Notifier, err)
+			failed++
+			continue
+		}
+		sent++
+	}
+	return sent, failed, nil
+}
+
+// Purge remove the entries from the outbox, all the entries are removed if no ID is given
+func (o *Outbox) Purge(ids ...string) (int, error) {
+	entries, err := o.List()
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, entry := range entries {
+		if len(ids) > 0 && !matchID(entry.ID, ids) {
+			continue
+		}
+		if err := os.Remove(path.Join(o.Dir, entry.ID+outboxExt)); err == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// StartSender run the background sender once per process, it checks the due entries every interval
+func (o *Outbox) StartSender(interval time.Duration) {
+	o.senderOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+				case <-o.wake:
+				}
+				o.ProcessDue()
+			}
+		}()
+	})
+}
+
+// StartOutboxSender start the background sender of the outbox under the root folder
+// the entries left by the previous processes are delivered too
+func StartOutboxSender(options libs.Options) {
+	if outbox := GetOutbox(options); outbox != nil {
+		outbox.StartSender(outboxInterval)
+		outbox.Wake()
+	}
+}
+
+// Wake ask the background sender to check the outbox now
+func (o *Outbox) Wake() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// matchID check if the ID is selected, the prefix of the ID is accepted too
+func matchID(id string, ids []string) bool {
+	for _, selected := range ids {
+		if selected != "" && strings.HasPrefix(id, selected) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package execution
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/whoamikiddie/vulnx/libs"
+)
+
+func TestOutbox(t *testing.T) {
+	// the notifier is unreachable except for the third request
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) != 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	var opt libs.Options
+	opt.Env.RootFolder = t.TempDir()
+	opt.Noti.Notifiers = map[string]libs.NotifierConfig{
+		"ops": {Type: "webhook", URL: server.URL},
+	}
+	engine := NewRuleEngine(opt.Noti)
+	if err := engine.Notify(opt, NewMessage("scan-done", "Done scan", "example.com", opt)); err == nil {
+		t.Fatalf("Error the first delivery should fail")
+	}
+
+	outbox := GetOutbox(opt)
+	entries, _ := outbox.List()
+	if len(entries) != 1 || entries[0].Attempts != 1 || entries[0].Status != OutboxPending || !entries[0].NextAttempt.After(time.Now()) {
+		t.Fatalf("Error keeping the failed notification: %+v", entries)
+	}
+
+	// not due yet
+	if sent, _ := outbox.ProcessDue(); sent != 0 {
+		t.Errorf("Error the entry should wait for the backoff")
+	}
+	outbox.now = func() time.Time { return time.Now().Add(time.Hour) }
+	if sent, failed := outbox.ProcessDue(); sent != 0 || failed != 1 {
+		t.Errorf("Error the second delivery should fail: %v %v", sent, failed)
+	}
+	if sent, failed, _ := outbox.Retry(); sent != 1 || failed != 0 {
+		t.Errorf("Error retrying the entry: %v %v", sent, failed)
+	}
+	if entries, _ := outbox.List(); len(entries) != 0 {
+		t.Errorf("Error the delivered entry should be removed: %+v", entries)
+	}
+
+	// the failed Send of the claimed entry is rescheduled with the backoff, the same as the delivery of the engine
+	outbox.now = time.Now
+	entry, err := outbox.Claim("ops", NewMessage(ClassStatus, "", "queued", opt))
+	if err != nil {
+		t.Fatalf("Error Claim: %v", err)
+	}
+	if err := outbox.Send(entry); err == nil {
+		t.Errorf("Error Send should fail")
+	}
+	if err := engine.Notify(opt, NewMessage("scan-done", "Done scan", "example.org", opt)); err == nil {
+		t.Errorf("Error the delivery of the engine should fail")
+	}
+	entries, _ = outbox.List()
+	if len(entries) != 2 {
+		t.Fatalf("Error keeping the failed notifications: %+v", entries)
+	}
+	for _, entry := range entries {
+		if entry.Attempts != 1 || entry.Status != OutboxPending || entry.LastError == "" || !entry.NextAttempt.After(time.Now()) {
+			t.Errorf("Error the failed notification should be rescheduled: %+v", entry)
+		}
+	}
+	if count, _ := outbox.Purge(entries[0].ID); count != 1 {
+		t.Errorf("Error purging the selected entry")
+	}
+	if count, _ := outbox.Purge(); count != 1 {
+		t.Errorf("Error purging all the entries")
+	}
+}
+
+func TestOutboxClaim(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	var opt libs.Options
+	opt.Env.RootFolder = t.TempDir()
+	opt.Noti.Notifiers = map[string]libs.NotifierConfig{
+		"ops": {Type: "webhook", URL: server.URL, Headers: map[string]string{"Authorization": "Bearer secret-token"}},
+	}
+	outbox := GetOutbox(opt)
+
+	// the claimed entry is invisible to the other senders until it's sent
+	entry, err := outbox.Claim("ops", NewMessage(ClassStatus, "", "claimed", opt))
+	if err != nil {
+		t.Fatalf("Error Claim: %v", err)
+	}
+	if entries, _ := outbox.List(); len(entries) != 0 {
+		t.Errorf("Error the claimed entry should not be listed: %+v", entries)
+	}
+	if err := outbox.Deliver(entry); err != ErrOutboxClaimed {
+		t.Errorf("Error the claimed entry should not be delivered twice: %v", err)
+	}
+	if err := outbox.Send(entry); err == nil || atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Error Send: %v %v", err, calls)
+	}
+
+	// the credentials are read from the config, never written to the outbox
+	files, _ := os.ReadDir(outbox.Dir)
+	for _, file := range files {
+		if data, _ := os.ReadFile(path.Join(outbox.Dir, file.Name())); strings.Contains(string(data), "secret-token") || strings.Contains(string(data), server.URL) {
+			t.Errorf("Error the notifier config should not be in the outbox: %s", data)
+		}
+	}
+
+	// the notifier removed from the config can't be sent
+	opt.Noti.Notifiers = map[string]libs.NotifierConfig{}
+	outbox = GetOutbox(opt)
+	outbox.now = func() time.Time { return time.Now().Add(time.Hour) }
+	if sent, failed := outbox.ProcessDue(); sent != 0 || failed != 1 || atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Error the entry of the unknown notifier should fail: %v %v", sent, failed)
+	}
+}
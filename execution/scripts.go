@@ -177,6 +177,63 @@ func Decompress(dest string, src string) {
 	utils.RunCmdWithOutput(cmd)
 }
 
+// ExtractArchive extract the tar.gz archive to dest without the shell
+// the entries escaping dest are rejected, only the folders and the regular files are extracted
+func ExtractArchive(dest string, src string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("invalid archive %v: %v", path.Base(src), err)
+	}
+	defer gz.Close()
+
+	dest = path.Clean(dest)
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+	tarReader := tar.NewReader(gz)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid archive %v: %v", path.Base(src), err)
+		}
+
+		target := path.Join(dest, header.Name)
+		if target != dest && !strings.HasPrefix(target, dest+"/") {
+			return fmt.Errorf("archive entry %v is outside of the destination", header.Name)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(path.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(header.Mode)&0755|0600)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(out, tarReader)
+			out.Close()
+			if err != nil {
+				return err
+			}
+		default:
+			// the links could point outside of the destination
+			utils.DebugF("Skipping the archive entry %v", header.Name)
+		}
+	}
+}
+
 func ExtractTarGz(filename string) error {
 	r, err := os.Open(utils.NormalizePath(filename))
 	if err != nil {
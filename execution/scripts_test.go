@@ -1,7 +1,13 @@
 package execution
 
 import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path"
 	"testing"
+
+	"github.com/whoamikiddie/vulnx/utils"
 )
 
 func TestChunkFile(t *testing.T) {
@@ -17,3 +23,39 @@ func TestIsWildCard(t *testing.T) {
 	IsWildCard("github.com")
 	IsWildCard("tesla.com")
 }
+
+func writeArchive(t *testing.T, names ...string) string {
+	src := path.Join(t.TempDir(), "ws.tar.gz")
+	f, err := os.Create(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, name := range names {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: 4, Typeflag: tar.TypeReg})
+		tw.Write([]byte("data"))
+	}
+	tw.Close()
+	gz.Close()
+	f.Close()
+	return src
+}
+
+func TestExtractArchive(t *testing.T) {
+	dest := path.Join(t.TempDir(), "example.com")
+	if err := ExtractArchive(dest, writeArchive(t, "./subdomain/final.txt", "runtime")); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path.Join(dest, "subdomain", "final.txt")); string(data) != "data" {
+		t.Errorf("final.txt = %q", data)
+	}
+
+	dest = path.Join(t.TempDir(), "example.com")
+	if err := ExtractArchive(dest, writeArchive(t, "../escaped.txt")); err == nil {
+		t.Errorf("the entry outside of the destination should be rejected")
+	}
+	if utils.FileExists(path.Join(path.Dir(dest), "escaped.txt")) {
+		t.Errorf("escaped.txt should not be written")
+	}
+}
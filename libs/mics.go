@@ -37,12 +37,55 @@ type Cron struct {
 	Forever  bool
 }
 
+// Schedule sub options for the cron schedules
+type Schedule struct {
+	Name       string
+	Expression string
+	Timezone   string
+	// random delay in seconds
+	Jitter int
+}
+
+// Account sub options for the users and the API tokens of the server
+type Account struct {
+	Password  string
+	Role      string
+	Disabled  bool
+	Enabled   bool
+	TokenName string
+	// number of days before the token expires
+	TokenTTL int
+}
+
+// Audit sub options to query the audit log
+type Audit struct {
+	Since  string
+	Until  string
+	Actor  string
+	Action string
+	Limit  int
+}
+
 // Remote credentials for other client
 type Remote struct {
 	MasterHost string
 	MasterCred string
 	PoolHost   string
 	PoolCred   string
+
+	// the API server of the remote commands, the session is saved by remote login
+	URL      string
+	Username string
+	Password string
+	APIToken string
+	// skip the verification of the self-signed certificate
+	Insecure bool
+
+	Status string
+	Search string
+	Sort   string
+	Limit  int
+	Follow bool
 }
 
 // Sync credentials for other client
@@ -23,4 +23,56 @@ type Notification struct {
 	SlackDiffChannel   string
 	// later then
 	DiscordToken string
+
+	// Notifiers the named notifiers, the name is used in the Routes
+	Notifiers map[string]NotifierConfig
+	// Routes map the message class (#status, #report, #diff) to the notifier names
+	// every class goes to all the notifiers when there is no route
+	Routes map[string][]string
+
+	// Rules evaluated in order before sending, the first matched rule decides the action
+	Rules []NotificationRule
+	// DedupWindow suppress the message with the same content within the window (e.g: 30m, 1h)
+	DedupWindow string
+	// RateLimit the maximum number of messages per notifier within the RateWindow, 0 means unlimited
+	RateLimit  int
+	RateWindow string
+	// DigestInterval how often the digest of the batched messages is sent, default is 1h
+	DigestInterval string
+}
+
+// NotificationRule match the messages by event, flow, module and severity, empty field matches everything
+type NotificationRule struct {
+	Name string `mapstructure:"name"`
+	// event type (start, done, scan-start, report, diff) or message class (#status, #report, #diff)
+	Events      []string `mapstructure:"events"`
+	Flows       []string `mapstructure:"flows"`
+	Modules     []string `mapstructure:"modules"`
+	MinSeverity string   `mapstructure:"min_severity"`
+	// send, drop or digest
+	Action string `mapstructure:"action"`
+}
+
+// NotifierConfig define a notifier, the fields are used depending on the type
+type NotifierConfig struct {
+	// slack, telegram, discord, teams, matrix, smtp or webhook
+	Type string `mapstructure:"type"`
+	// webhook URL, API endpoint or the Matrix homeserver
+	URL   string `mapstructure:"url"`
+	Token string `mapstructure:"token"`
+	// Slack channel, Telegram chat ID or Matrix room ID
+	Channel string `mapstructure:"channel"`
+	// override the channel per message class
+	Channels map[string]string `mapstructure:"channels"`
+	// SMTP part
+	Host     string   `mapstructure:"host"`
+	Port     int      `mapstructure:"port"`
+	Username string   `mapstructure:"username"`
+	Password string   `mapstructure:"password"`
+	From     string   `mapstructure:"from"`
+	To       []string `mapstructure:"to"`
+	// generic webhook part, the template is a Go template rendered with the message
+	Method   string            `mapstructure:"method"`
+	Headers  map[string]string `mapstructure:"headers"`
+	Template string            `mapstructure:"template"`
 }
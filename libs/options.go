@@ -43,21 +43,25 @@ type Options struct {
 	EnableBackup     bool
 	JsonOutput       bool
 
-	Client Client
-	Queue  Queue
-	Git    Git
-	Sync   Sync
-	Scan   Scan
-	Server Server
-	Env    Environment
-	Noti   Notification
-	Flow   Flow
-	Module Module
-	Tmux   TmuxOpt
-	Cron   Cron
-	Remote Remote
-	Cdn    Cdn
-	Update Update
+	Client   Client
+	Queue    Queue
+	Worker   Worker
+	Git      Git
+	Sync     Sync
+	Scan     Scan
+	Server   Server
+	Env      Environment
+	Noti     Notification
+	Flow     Flow
+	Module   Module
+	Tmux     TmuxOpt
+	Cron     Cron
+	Schedule Schedule
+	Account  Account
+	Audit    Audit
+	Remote   Remote
+	Cdn      Cdn
+	Update   Update
 
 	ThreadsHold     ThreadsHold
 	Cloud           Cloud
@@ -88,6 +92,10 @@ type Scan struct {
 	Force           bool
 	// this is true when calling from cloud scan
 	RemoteCall bool
+
+	// the summary is posted to the callback URL when the scan is finished or failed
+	CallbackURL    string
+	CallbackSecret string
 }
 
 type ThreadsHold struct {
@@ -101,6 +109,9 @@ type Report struct {
 	CustomPreFix  string
 	PublicIP      string
 	ExtractFolder string
+	Since         string
+	Format        string
+	Output        string
 	Static        bool
 	Raw           bool
 }
@@ -112,7 +123,11 @@ type Server struct {
 	PreFork                 bool
 	NoAuthen                bool
 
-	PollingTime    int
+	PollingTime int
+	// number of the queued jobs run by the server itself, 0 leaves them to the remote workers
+	Executors int
+	// serve only the metrics on this address, e.g: 127.0.0.1:9110
+	MetricsBind    string
 	Bind           string
 	Port           string
 	StaticPrefix   string
@@ -3,13 +3,40 @@ package libs
 // Queue sub options for quque
 type Queue struct {
 	QueueFolder string
-	QueueFile   string
-	RawCommand  string
+	// legacy queue file, its lines are imported into the queue database
+	QueueFile  string
+	RawCommand string
 
 	InputAsFile bool
 	Add         bool
+
+	Priority    int
+	MaxAttempts int
+	States      []string
+	Limit       int
+	// number of seconds to wait before checking the queue again
+	PollingTime int
+}
+
+// Worker sub options for the remote worker
+type Worker struct {
+	Server   string
+	Name     string
+	Username string
+	Password string
+	Token    string
+	// lease in seconds, the heartbeat is sent every third of it
+	Lease int
+	// keep the workspace on the worker after it's uploaded
+	KeepWorkspace bool
 }
 
+// the callback of the scan started as a command is passed in the environment so the secret never shows in the process list
+const (
+	CallbackURLEnv    = "VULNX_CALLBACK_URL"
+	CallbackSecretEnv = "VULNX_CALLBACK_SECRET"
+)
+
 type InputFormat struct {
 	Input       string   `json:"input"`
 	Flow        string   `json:"flow"`
@@ -19,4 +46,8 @@ type InputFormat struct {
 	Extra       string   `json:"extra"`
 	Command     string   `json:"command"`
 	InputAsFile bool     `json:"input-as-file"`
+
+	// the summary is posted to the callback URL when the scan is finished or failed
+	CallbackURL    string `json:"callback_url"`
+	CallbackSecret string `json:"callback_secret"`
 }
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace the prefix of all the metrics
+const Namespace = "vulnx"
+
+var (
+	// ScansTotal the scans finished by this process per flow and result (done, failed, cancelled)
+	ScansTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "scans_total",
+		Help:      "Number of the scans finished by this process per flow and result",
+	}, []string{"flow", "result"})
+
+	// ModuleDuration how long the modules take to run
+	ModuleDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "module_duration_seconds",
+		Help:      "Duration of the modules in seconds",
+		// from 10 seconds to about a week
+		Buckets: prometheus.ExponentialBuckets(10, 4, 9),
+	}, []string{"flow", "module"})
+
+	// StepDuration how long the steps of the modules take to run
+	StepDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "step_duration_seconds",
+		Help:      "Duration of the steps in seconds",
+		// from 1 second to about 3 days
+		Buckets: prometheus.ExponentialBuckets(1, 4, 10),
+	}, []string{"module"})
+
+	// NotificationFailures the notifications failed to be delivered
+	NotificationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "notification_failures_total",
+		Help:      "Number of the failed attempts to deliver the notifications per notifier",
+	}, []string{"notifier"})
+)
+
+// Registry the registry of all the metrics, the default one of prometheus isn't used so nothing else leaks in
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		ScansTotal,
+		ModuleDuration,
+		StepDuration,
+		NotificationFailures,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Register add the collector to the registry
+func Register(collector prometheus.Collector) error {
+	return Registry.Register(collector)
+}
+
+// Handler serve the metrics in the Prometheus format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/spf13/cast"
+	"github.com/whoamikiddie/vulnx/core"
+	"github.com/whoamikiddie/vulnx/database"
+)
+
+// auditMaxError the error of the response longer than this is truncated
+const auditMaxError = 512
+
+// isMutating the method changes something on the server
+func isMutating(method string) bool {
+	switch method {
+	case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// auditSkipped the routes polled by the remote workers, they would flood the audit log
+var auditSkipped = map[string]bool{
+	"/api/osmp/jobs/lease":         true,
+	"/api/osmp/jobs/:id/heartbeat": true,
+}
+
+// AuditTrail record every mutating request with its actor, params and result to the audit log
+func AuditTrail(c *fiber.Ctx) error {
+	if !isMutating(c.Method()) {
+		return c.Next()
+	}
+	err := c.Next()
+	if auditSkipped[c.Route().Path] {
+		return err
+	}
+
+	params := requestParams(c)
+	entry := database.AuditLog{
+		Source:    database.AuditSourceAPI,
+		SourceIP:  c.IP(),
+		Action:    fmt.Sprintf("%v %v", c.Method(), c.Route().Path),
+		Workspace: firstNonEmpty(c.Params("wsname"), cast.ToString(params["workspace"])),
+		Target:    firstNonEmpty(cast.ToString(params["target"]), c.Params("id"), c.Params("name"), c.Params("username"), cast.ToString(params["username"])),
+		Status:    c.Response().StatusCode(),
+	}
+	if user := CurrentUser(c); user != nil {
+		entry.Actor = user.Username
+	} else {
+		// the login attempts have no user yet
+		entry.Actor = cast.ToString(params["username"])
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		entry.Status = fiberErr.Code
+	} else if err != nil {
+		entry.Status = fiber.StatusInternalServerError
+	}
+	entry.Result = "success"
+	if entry.Status >= 400 {
+		entry.Result = "failed"
+		entry.Error = responseError(c, err)
+	}
+	entry.Params = core.AuditParams(params)
+	core.Audit(entry, Opt)
+	return err
+}
+
+// requestParams the query, the JSON body and the form values of the request, the uploaded files are recorded by their names
+func requestParams(c *fiber.Ctx) map[string]interface{} {
+	params := make(map[string]interface{})
+	c.Request().URI().QueryArgs().VisitAll(func(key, value []byte) {
+		params[string(key)] = string(value)
+	})
+
+	switch {
+	case strings.HasPrefix(string(c.Request().Header.ContentType()), fiber.MIMEApplicationJSON):
+		var body map[string]interface{}
+		if jsoniter.Unmarshal(c.Body(), &body) == nil {
+			for key, value := range body {
+				params[key] = value
+			}
+		}
+	case strings.HasPrefix(string(c.Request().Header.ContentType()), fiber.MIMEMultipartForm):
+		if form, err := c.MultipartForm(); err == nil {
+			for key, values := range form.Value {
+				params[key] = strings.Join(values, ",")
+			}
+			for key, files := range form.File {
+				var names []string
+				for _, file := range files {
+					names = append(names, file.Filename)
+				}
+				params[key] = strings.Join(names, ",")
+			}
+		}
+	default:
+		c.Request().PostArgs().VisitAll(func(key, value []byte) {
+			params[string(key)] = string(value)
+		})
+	}
+	return params
+}
+
+// responseError the error message of the failed request
+func responseError(c *fiber.Ctx, err error) string {
+	message := ""
+	if err != nil {
+		message = err.Error()
+	} else {
+		var body map[string]interface{}
+		if jsoniter.Unmarshal(c.Response().Body(), &body) == nil {
+			message = firstNonEmpty(cast.ToString(body["error"]), cast.ToString(body["message"]))
+		}
+		if message == "" {
+			message = string(c.Response().Body())
+		}
+	}
+	if len(message) > auditMaxError {
+		message = message[:auditMaxError]
+	}
+	return message
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// ListAudit query the audit log, e.g: /audit?since=2024-01-02T00:00:00Z&until=...&actor=alice&limit=100
+// the time could be RFC3339 or a duration like 24h which means that long ago
+func ListAudit(c *fiber.Ctx) error {
+	filter := database.AuditFilter{
+		Actor:  c.Query("actor"),
+		Action: c.Query("action"),
+		Limit:  cast.ToInt(c.Query("limit", "100")),
+		Offset: cast.ToInt(c.Query("offset")),
+	}
+	var err error
+	if filter.Since, err = core.ParseAuditTime(c.Query("since")); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if filter.Until, err = core.ParseAuditTime(c.Query("until")); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	entries, total, err := core.ReadAudit(filter, Opt)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(ResponseHTTP{
+		Status:  200,
+		Data:    entries,
+		Type:    "audit",
+		Total:   int(total),
+		Message: "List the audit log",
+	})
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/whoamikiddie/vulnx/database"
+)
+
+func TestAuditTrail(t *testing.T) {
+	initTestServer(t)
+	app := fiber.New()
+	osmp := app.Group("/api/osmp", AuditTrail)
+	osmp.Post("/jobs/lease", LeaseJob)
+	osmp.Post("/jobs/:id/heartbeat", HeartbeatJob)
+	osmp.Post("/jobs/:id/finish", FinishJob)
+
+	postJSON(t, app, "/api/osmp/jobs/lease", `{"worker":"worker-1"}`)
+	postJSON(t, app, "/api/osmp/jobs/1/heartbeat", `{"worker":"worker-1"}`)
+	postJSON(t, app, "/api/osmp/jobs/1/finish", `{"worker":"worker-1"}`)
+
+	entries, total, err := database.QueryAudit(database.AuditFilter{})
+	if err != nil || total != 1 {
+		t.Fatalf("Error only the finish request should be recorded: %v %v", entries, err)
+	}
+	if entries[0].Action != "POST /api/osmp/jobs/:id/finish" || entries[0].Result != "failed" {
+		t.Errorf("Error the audit entry: %+v", entries[0])
+	}
+}
@@ -1,46 +1,81 @@
 package server
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
-	// jwtware "github.com/gofiber/jwt/v2"
-
 	"github.com/dgrijalva/jwt-go"
+	"github.com/fatih/color"
 	"github.com/gofiber/fiber/v2"
+	"github.com/whoamikiddie/vulnx/api"
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/utils"
 )
 
 // authen stuff
 
-func Login(c *fiber.Ctx) error {
-	type LoginInput struct {
-		Username string `json:"username"`
-		Password string `json:"password"`
+// AuthUser the user of the request, it's stored in the locals of the context
+type AuthUser = api.AuthUser
+
+const authUserKey = "auth-user"
+
+// EnsureAdminUser create the admin user from the credentials of the config file when there is no user yet
+// so the existing login keeps working after the upgrade
+func EnsureAdminUser() {
+	if database.DB == nil || database.CountUsers() > 0 {
+		return
+	}
+	if Opt.Client.Username == "" || Opt.Client.Password == "" {
+		return
+	}
+	if _, err := database.CreateUser(Opt.Client.Username, Opt.Client.Password, database.RoleAdmin); err != nil {
+		utils.ErrorF("Error creating the admin user: %v", err)
+		return
 	}
-	var input LoginInput
+	utils.InforF("Created the admin user %v from the config file", color.HiCyanString(Opt.Client.Username))
+}
+
+// authenticate check the password against the user store, the credentials of the config file are used if there is no user
+func authenticate(username string, password string) (*AuthUser, error) {
+	if database.DB != nil && database.CountUsers() > 0 {
+		user, err := database.AuthenticateUser(username, password)
+		if err != nil {
+			return nil, err
+		}
+		return &AuthUser{ID: user.ID, Username: user.Username, Role: user.Role}, nil
+	}
+	if Opt.Client.Username == "" || username != Opt.Client.Username || password != Opt.Client.Password {
+		return nil, database.ErrInvalidCredentials
+	}
+	return &AuthUser{Username: username, Role: database.RoleAdmin}, nil
+}
+
+func Login(c *fiber.Ctx) error {
+	var input api.LoginRequest
 	if err := c.BodyParser(&input); err != nil {
 		return c.SendStatus(fiber.StatusUnauthorized)
 	}
-	user := input.Username
-	pass := input.Password
-
-	dbUser := Opt.Client.Username
-	dbPass := Opt.Client.Password
 
+	var user *AuthUser
 	// auto pass if -A is specify
 	if Opt.Server.NoAuthen {
-		user = dbUser
-		pass = dbPass
-	}
-
-	// Throws Unauthorized error
-	if dbUser != user || dbPass != pass {
-		return c.SendStatus(fiber.StatusUnauthorized)
+		user = &AuthUser{Username: Opt.Client.Username, Role: database.RoleAdmin}
+	} else {
+		var err error
+		// Throws Unauthorized error
+		if user, err = authenticate(input.Username, input.Password); err != nil {
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
 	}
 
 	// Create the Claims
 	claims := jwt.MapClaims{
-		"name":  "Osmdeus Default",
-		"admin": true,
+		"uid":   user.ID,
+		"name":  user.Username,
+		"role":  user.Role,
+		"admin": user.Role == database.RoleAdmin,
 		"exp":   time.Now().Add(time.Hour * 24 * 30).Unix(),
 	}
 
@@ -52,17 +87,118 @@ func Login(c *fiber.Ctx) error {
 	if err != nil {
 		return c.SendStatus(fiber.StatusInternalServerError)
 	}
-	return c.JSON(fiber.Map{"status": "success", "message": "Successfully login", "token": t})
+	return c.JSON(api.LoginResponse{Status: "success", Message: "Successfully login", Token: t, Role: user.Role})
+
+}
 
+// parseJWT get the user from the claims of the token issued by Login
+func parseJWT(raw string) (*AuthUser, error) {
+	token, err := jwt.Parse(raw, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return []byte(Opt.Server.JWTSecret), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, errors.New("Invalid or expired JWT")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("Invalid or expired JWT")
+	}
+	user := &AuthUser{}
+	user.Username, _ = claims["name"].(string)
+	user.Role, _ = claims["role"].(string)
+	if uid, ok := claims["uid"].(float64); ok {
+		user.ID = uint(uid)
+	}
+	// the tokens issued before the roles were added are admin tokens
+	if user.Role == "" {
+		if admin, _ := claims["admin"].(bool); admin {
+			user.Role = database.RoleAdmin
+		}
+	}
+
+	if database.DB != nil {
+		// the token of the config file credentials has no user, it's no longer valid once the users are created
+		if user.ID == 0 {
+			if database.CountUsers() > 0 {
+				return nil, errors.New("Invalid or expired JWT")
+			}
+			return user, nil
+		}
+		// the user could be disabled, deleted or lose the role after the token was issued
+		dbUser, err := database.GetUser(user.Username)
+		if err != nil || dbUser.ID != user.ID || dbUser.Disabled {
+			return nil, errors.New("Invalid or expired JWT")
+		}
+		user.Role = dbUser.Role
+	}
+	return user, nil
+}
+
+// Authenticate check the JWT or the API token in the Authorization header
+// the header is "Osmedeus <token>" but the Bearer scheme is accepted too
+func Authenticate(c *fiber.Ctx) error {
+	if Opt.Server.NoAuthen {
+		c.Locals(authUserKey, &AuthUser{Username: Opt.Client.Username, Role: database.RoleAdmin})
+		return c.Next()
+	}
+
+	header := strings.TrimSpace(c.Get(fiber.HeaderAuthorization))
+	var raw string
+	for _, scheme := range []string{"Osmedeus ", "Bearer "} {
+		if strings.HasPrefix(header, scheme) {
+			raw = strings.TrimSpace(strings.TrimPrefix(header, scheme))
+		}
+	}
+	if raw == "" {
+		return jwtError(c, errors.New("Missing or malformed JWT"))
+	}
+
+	if strings.HasPrefix(raw, database.APITokenPrefix) {
+		user, err := database.VerifyAPIToken(raw)
+		if err != nil {
+			return jwtError(c, errors.New("Invalid or revoked API token"))
+		}
+		c.Locals(authUserKey, &AuthUser{ID: user.ID, Username: user.Username, Role: user.Role})
+		return c.Next()
+	}
+
+	user, err := parseJWT(raw)
+	if err != nil {
+		return jwtError(c, err)
+	}
+	c.Locals(authUserKey, user)
+	return c.Next()
+}
+
+// CurrentUser the user of the request, nil if the request is not authenticated
+func CurrentUser(c *fiber.Ctx) *AuthUser {
+	user, _ := c.Locals(authUserKey).(*AuthUser)
+	return user
+}
+
+// RequireRole only let the users with the role or a higher one through
+func RequireRole(role string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user := CurrentUser(c)
+		if user == nil || !database.RoleAllowed(user.Role, role) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": fmt.Sprintf("the %v role is required", role),
+			})
+		}
+		return c.Next()
+	}
 }
 
 func jwtError(c *fiber.Ctx, err error) error {
 	if err.Error() == "Missing or malformed JWT" {
 		c.Status(fiber.StatusBadRequest)
-		return c.JSON(fiber.Map{"status": "error", "message": "Missing or malformed JWT", "data": nil})
+		return c.JSON(api.AuthError{Status: "error", Message: "Missing or malformed JWT"})
 
 	} else {
 		c.Status(fiber.StatusUnauthorized)
-		return c.JSON(fiber.Map{"status": "error", "message": "Invalid or expired JWT", "data": nil})
+		return c.JSON(api.AuthError{Status: "error", Message: err.Error()})
 	}
 }
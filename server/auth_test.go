@@ -0,0 +1,125 @@
+package server
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gofiber/fiber/v2"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/whoamikiddie/vulnx/api"
+	"github.com/whoamikiddie/vulnx/database"
+)
+
+// authRequest send the request with the token and return the status
+func authRequest(t *testing.T, app *fiber.App, method string, url string, token string) int {
+	req := httptest.NewRequest(method, url, strings.NewReader(`{"worker":"worker-1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Osmedeus "+token)
+	}
+	status, _ := doRequest(t, app, req)
+	return status
+}
+
+func loginToken(t *testing.T, app *fiber.App, username string, password string) string {
+	status, body := postJSON(t, app, "/api/login", `{"username":"`+username+`","password":"`+password+`"}`)
+	var response api.LoginResponse
+	jsoniter.UnmarshalFromString(body, &response)
+	if status != fiber.StatusOK || response.Token == "" {
+		t.Fatalf("Error login as %v: %v %v", username, status, body)
+	}
+	return response.Token
+}
+
+func TestAuthenticate(t *testing.T) {
+	initTestServer(t)
+	Opt.Server.JWTSecret = "jwt-secret"
+	Opt.Client.Username = "osmedeus"
+	Opt.Client.Password = "config-password"
+	app := fiber.New()
+	SetupRoutes(app)
+
+	if status := authRequest(t, app, fiber.MethodGet, "/api/osmp/me", ""); status != fiber.StatusBadRequest {
+		t.Errorf("Error the request without the token should be rejected: %v", status)
+	}
+	if status := authRequest(t, app, fiber.MethodGet, "/api/osmp/me", "not-a-jwt"); status != fiber.StatusUnauthorized {
+		t.Errorf("Error the invalid token should be rejected: %v", status)
+	}
+	forged, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"name": "osmedeus", "role": database.RoleAdmin, "exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("other-secret"))
+	if status := authRequest(t, app, fiber.MethodGet, "/api/osmp/me", forged); status != fiber.StatusUnauthorized {
+		t.Errorf("Error the token signed with another secret should be rejected: %v", status)
+	}
+
+	// the config file credentials are used until the first user is created
+	legacy := loginToken(t, app, "osmedeus", "config-password")
+	if status := authRequest(t, app, fiber.MethodGet, "/api/osmp/users", legacy); status != fiber.StatusOK {
+		t.Errorf("Error the token of the config file credentials should be admin: %v", status)
+	}
+
+	database.CreateUser("alice", "alice-password", database.RoleAdmin)
+	if status := authRequest(t, app, fiber.MethodGet, "/api/osmp/me", legacy); status != fiber.StatusUnauthorized {
+		t.Errorf("Error the token without user should be rejected once the users exist: %v", status)
+	}
+
+	token := loginToken(t, app, "alice", "alice-password")
+	if status := authRequest(t, app, fiber.MethodGet, "/api/osmp/me", token); status != fiber.StatusOK {
+		t.Errorf("Error Authenticate: %v", status)
+	}
+	disabled := true
+	database.UpdateUser("alice", "", "", &disabled)
+	if status := authRequest(t, app, fiber.MethodGet, "/api/osmp/me", token); status != fiber.StatusUnauthorized {
+		t.Errorf("Error the token of the disabled user should be rejected: %v", status)
+	}
+
+	database.CreateUser("bob", "bob-password", database.RoleViewer)
+	apiToken, _, err := database.CreateAPIToken("bob", "ci", 0)
+	if err != nil {
+		t.Fatalf("Error CreateAPIToken: %v", err)
+	}
+	if status := authRequest(t, app, fiber.MethodGet, "/api/osmp/me", apiToken); status != fiber.StatusOK {
+		t.Errorf("Error the API token should be accepted: %v", status)
+	}
+	if status := authRequest(t, app, fiber.MethodGet, "/api/osmp/me", apiToken+"x"); status != fiber.StatusUnauthorized {
+		t.Errorf("Error the unknown API token should be rejected: %v", status)
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	initTestServer(t)
+	Opt.Server.JWTSecret = "jwt-secret"
+	app := fiber.New()
+	SetupRoutes(app)
+
+	tokens := make(map[string]string)
+	for _, role := range []string{database.RoleViewer, database.RoleOperator, database.RoleAdmin} {
+		database.CreateUser(role, role+"-password", role)
+		tokens[role] = loginToken(t, app, role, role+"-password")
+	}
+
+	routes := []struct {
+		method string
+		url    string
+		role   string
+	}{
+		{fiber.MethodGet, "/api/osmp/me", database.RoleViewer},
+		{fiber.MethodGet, "/api/osmp/jobs", database.RoleViewer},
+		{fiber.MethodPost, "/api/osmp/jobs/lease", database.RoleOperator},
+		{fiber.MethodGet, "/api/osmp/schedules", database.RoleViewer},
+		{fiber.MethodGet, "/api/osmp/users", database.RoleAdmin},
+		{fiber.MethodGet, "/api/osmp/audit", database.RoleAdmin},
+	}
+	for _, route := range routes {
+		for role, token := range tokens {
+			status := authRequest(t, app, route.method, route.url, token)
+			allowed := database.RoleAllowed(role, route.role)
+			if allowed && status == fiber.StatusForbidden || !allowed && status != fiber.StatusForbidden {
+				t.Errorf("Error %v %v as %v: %v", route.method, route.url, role, status)
+			}
+		}
+	}
+}
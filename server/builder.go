@@ -7,15 +7,14 @@ import (
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/whoamikiddie/vulnx/api"
+	"github.com/whoamikiddie/vulnx/core"
 	"github.com/whoamikiddie/vulnx/libs"
 	"github.com/whoamikiddie/vulnx/utils"
 )
 
-// UploadData data required in json form
-type UploadData struct {
-	Data     string `json:"data"`
-	Filename string `json:"filename"`
-}
+// UploadData data required in json form, see api.UploadRequest
+type UploadData = api.UploadRequest
 
 // Upload testing authenticated connection
 func Upload(c *fiber.Ctx) error {
@@ -32,7 +31,7 @@ func Upload(c *fiber.Ctx) error {
 		uploadData.Filename = utils.RandomString(6)
 	}
 	tmpFile := path.Base(utils.NormalizePath(uploadData.Filename))
-	baseDir := fmt.Sprintf("/tmp/%v-input/", libs.BINARY)
+	baseDir := core.InputFolder()
 	if !utils.FolderExists(baseDir) {
 		os.MkdirAll(baseDir, 0755)
 	}
@@ -44,8 +43,8 @@ func Upload(c *fiber.Ctx) error {
 
 	return c.JSON(ResponseHTTP{
 		Status: 200,
-		Data: fiber.Map{
-			"filepath": filename,
+		Data: api.UploadResult{
+			FilePath: filename,
 		},
 		Type:    "upload",
 		Message: "New Data Uploaded",
@@ -53,22 +52,6 @@ func Upload(c *fiber.Ctx) error {
 
 }
 
-// SaveTargets save upload data to /tmp/osm-input/data-osm-xxx.txt
-func SaveTargets(targets []string) string {
-	tmpFile := fmt.Sprintf("data-%v-%v-%v.txt", utils.GetTS(), libs.BINARY, utils.RandomString(6))
-	baseDir := fmt.Sprintf("/tmp/%v-input/", libs.BINARY)
-	if !utils.FolderExists(baseDir) {
-		os.MkdirAll(baseDir, 0755)
-	}
-	targetFile := path.Join(baseDir, tmpFile)
-	data := strings.Join(targets, "\n")
-	filename, err := utils.WriteToFile(targetFile, data)
-	if err != nil {
-		return ""
-	}
-	return filename
-}
-
 // CommandBuilder build core command from API
 func CommandBuilder(taskData *TaskData) string {
 	binary := fmt.Sprintf("%s scan", libs.BINARY)
@@ -87,7 +70,7 @@ func CommandBuilder(taskData *TaskData) string {
 	var workspace, concurrency, timeout, params, workflow, plugin, scanID string
 
 	if len(taskData.TargetsList) > 0 {
-		taskData.TargetsFile = SaveTargets(taskData.TargetsList)
+		taskData.TargetsFile = core.SaveTargets(taskData.TargetsList)
 		utils.DebugF("Save targets list to: %v", taskData.TargetsFile)
 	}
 
@@ -97,10 +80,6 @@ func CommandBuilder(taskData *TaskData) string {
 		workspace = fmt.Sprintf(" -w '%v'", taskData.Workspace)
 	}
 
-	if taskData.Binary != "" {
-		binary = taskData.Binary
-	}
-
 	//if taskData.RawName {
 	//	binary = binary + " --rt "
 	//}
@@ -0,0 +1,272 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/gofiber/fiber/v2"
+	"github.com/spf13/cast"
+	"github.com/thoas/go-funk"
+	"github.com/whoamikiddie/vulnx/api"
+	"github.com/whoamikiddie/vulnx/core"
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/execution"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+const (
+	// defaultJobLease the lease of the job if the worker doesn't ask for one
+	defaultJobLease = 2 * time.Minute
+	minJobLease     = 30 * time.Second
+	maxJobLease     = 30 * time.Minute
+	// jobReaperInterval how often the expired leases are checked
+	jobReaperInterval = 30 * time.Second
+)
+
+// WorkerRequest the body sent by the remote worker, see api.WorkerRequest
+type WorkerRequest = api.WorkerRequest
+
+// leaseDuration the lease asked by the worker within the allowed range
+func leaseDuration(w WorkerRequest) time.Duration {
+	lease := time.Duration(w.Lease) * time.Second
+	if lease <= 0 {
+		return defaultJobLease
+	}
+	if lease < minJobLease {
+		return minJobLease
+	}
+	if lease > maxJobLease {
+		return maxJobLease
+	}
+	return lease
+}
+
+// StartJobReaper put the jobs of the dead workers back to the queue in the background
+func StartJobReaper() {
+	if database.DB == nil {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(jobReaperInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			requeued, err := database.RequeueExpiredJobs()
+			if err != nil {
+				utils.ErrorF("Error checking the expired jobs: %v", err)
+				continue
+			}
+			if requeued > 0 {
+				utils.InforF("Re-queued %v jobs of the dead workers", color.HiMagentaString("%v", requeued))
+			}
+		}
+	}()
+}
+
+// ListJobs list the jobs of the queue, filtered by the state query
+func ListJobs(c *fiber.Ctx) error {
+	var states []string
+	if raw := c.Query("state"); raw != "" {
+		states = strings.Split(raw, ",")
+	}
+	jobs, err := database.ListJobs(states, cast.ToInt(c.Query("limit")))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(ResponseHTTP{
+		Status:  200,
+		Data:    jobs,
+		Type:    "jobs",
+		Total:   len(jobs),
+		Message: "List the jobs of the queue",
+	})
+}
+
+// JobDetail get the job by its ID
+func JobDetail(c *fiber.Ctx) error {
+	job, err := database.GetJob(cast.ToUint(c.Params("id")))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(ResponseHTTP{
+		Status:  200,
+		Data:    job,
+		Type:    "job",
+		Message: "Job detail",
+	})
+}
+
+// LeaseJob hand the next pending job to the remote worker
+func LeaseJob(c *fiber.Ctx) error {
+	var req WorkerRequest
+	if err := c.BodyParser(&req); err != nil || req.Worker == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "worker name is required",
+		})
+	}
+	job, err := database.ClaimJob(req.Worker, req.Hostname, 0, leaseDuration(req))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if job == nil {
+		return c.JSON(ResponseHTTP{
+			Status:  200,
+			Type:    "job",
+			Message: "No pending job",
+		})
+	}
+	utils.InforF("Leased the job %v to the worker %v", color.HiMagentaString("#%v", job.ID), color.HiCyanString(req.Worker))
+	leased := core.LeasedJob{Job: *job, CallbackSecret: job.CallbackSecret}
+	if job.InputAsFile {
+		leased.Inputs = utils.ReadingFileUnique(job.Input)
+	}
+	return c.JSON(ResponseHTTP{
+		Status:  200,
+		Data:    leased,
+		Type:    "job",
+		Message: "Job leased",
+	})
+}
+
+// HeartbeatJob extend the lease of the job, the worker should stop the job if it's no longer leased
+func HeartbeatJob(c *fiber.Ctx) error {
+	var req WorkerRequest
+	if err := c.BodyParser(&req); err != nil || req.Worker == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "worker name is required",
+		})
+	}
+	if err := database.HeartbeatJob(cast.ToUint(c.Params("id")), req.Worker, leaseDuration(req)); err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, database.ErrJobLost) {
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(ResponseHTTP{
+		Status:  200,
+		Type:    "job",
+		Message: "Lease extended",
+	})
+}
+
+// FinishJob mark the job of the worker as done or failed
+func FinishJob(c *fiber.Ctx) error {
+	var req WorkerRequest
+	if err := c.BodyParser(&req); err != nil || req.Worker == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "worker name is required",
+		})
+	}
+	job, err := database.GetJob(cast.ToUint(c.Params("id")))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if job.State != database.JobRunning || job.Worker != req.Worker {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": database.ErrJobLost.Error(),
+		})
+	}
+
+	var jobErr error
+	if req.Error != "" {
+		jobErr = errors.New(req.Error)
+	}
+	if err := database.FinishJob(job.ID, jobErr); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(ResponseHTTP{
+		Status:  200,
+		Type:    "job",
+		Message: "Job finished",
+	})
+}
+
+// UploadWorkspace store the workspace archive sent by the worker and import it to the database
+func UploadWorkspace(c *fiber.Ctx) error {
+	job, err := database.GetJob(cast.ToUint(c.Params("id")))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	// the same as FinishJob, the finished or cancelled job can't overwrite the workspace anymore
+	if job.State != database.JobRunning || c.FormValue("worker") != job.Worker {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": database.ErrJobLost.Error(),
+		})
+	}
+
+	workspace := c.FormValue("workspace")
+	if !utils.IsSafeName(workspace) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid workspace name",
+		})
+	}
+	var inputs []string
+	if job.InputAsFile {
+		inputs = utils.ReadingFileUnique(job.Input)
+	}
+	if !funk.ContainsString(core.JobWorkspaces(*job, inputs, Opt), workspace) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": fmt.Sprintf("workspace %v doesn't belong to the job", workspace),
+		})
+	}
+	file, err := c.FormFile("archive")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "archive file is required",
+		})
+	}
+
+	tmpDir, err := os.MkdirTemp("", fmt.Sprintf("%v-upload-", libs.BINARY))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	defer os.RemoveAll(tmpDir)
+	archive := path.Join(tmpDir, workspace+".tar.gz")
+	if err := c.SaveFile(file, archive); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	dest := path.Join(utils.NormalizePath(Opt.Env.WorkspacesFolder), workspace)
+	if err := execution.ExtractArchive(dest, archive); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	imported := database.ImportWorkspaces(Opt, workspace)
+	utils.InforF("Received the workspace %v of the job %v from the worker %v", color.HiCyanString(workspace),
+		color.HiMagentaString("#%v", job.ID), color.HiCyanString(job.Worker))
+
+	return c.JSON(ResponseHTTP{
+		Status: 200,
+		Data: api.WorkspaceUploaded{
+			Workspace: workspace,
+			Imported:  imported,
+		},
+		Type:    "upload",
+		Message: "Workspace uploaded",
+	})
+}
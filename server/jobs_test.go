@@ -0,0 +1,185 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/whoamikiddie/vulnx/api"
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+func initTestServer(t *testing.T) *fiber.App {
+	var options libs.Options
+	options.Server.DBPath = path.Join(t.TempDir(), "sqlite.db")
+	options.Env.WorkspacesFolder = t.TempDir()
+	if _, err := database.InitDB(options); err != nil {
+		t.Fatalf("Error InitDB: %v", err)
+	}
+	t.Cleanup(database.CloseDB)
+	saved := Opt
+	Opt = options
+	t.Cleanup(func() { Opt = saved })
+
+	app := fiber.New()
+	app.Post("/jobs/lease", LeaseJob)
+	app.Post("/jobs/:id/heartbeat", HeartbeatJob)
+	app.Post("/jobs/:id/finish", FinishJob)
+	app.Post("/jobs/:id/upload", UploadWorkspace)
+	return app
+}
+
+// postJSON send the body and return the status and the response
+func postJSON(t *testing.T, app *fiber.App, url string, body string) (int, string) {
+	req := httptest.NewRequest(fiber.MethodPost, url, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return doRequest(t, app, req)
+}
+
+func httptestGet(url string) *http.Request {
+	return httptest.NewRequest(fiber.MethodGet, url, nil)
+}
+
+func doRequest(t *testing.T, app *fiber.App, req *http.Request) (int, string) {
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Error sending %v: %v", req.URL, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data)
+}
+
+// testArchive the tar.gz archive with the files
+func testArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func uploadRequest(t *testing.T, id uint, worker string, workspace string, archive []byte) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	writer.WriteField("worker", worker)
+	writer.WriteField("workspace", workspace)
+	part, _ := writer.CreateFormFile("archive", "workspace.tar.gz")
+	part.Write(archive)
+	writer.Close()
+	req := httptest.NewRequest(fiber.MethodPost, fmt.Sprintf("/jobs/%v/upload", id), &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestLeaseJob(t *testing.T) {
+	app := initTestServer(t)
+
+	if status, _ := postJSON(t, app, "/jobs/lease", `{}`); status != fiber.StatusBadRequest {
+		t.Errorf("Error the worker name should be required: %v", status)
+	}
+	if status, body := postJSON(t, app, "/jobs/lease", `{"worker":"worker-1"}`); status != fiber.StatusOK || strings.Contains(body, `"input"`) {
+		t.Errorf("Error no job should be leased from the empty queue: %v %v", status, body)
+	}
+
+	job := database.Job{Input: "example.com", Flow: "general", CallbackSecret: "secret"}
+	database.EnqueueJob(&job)
+	status, body := postJSON(t, app, "/jobs/lease", `{"worker":"worker-1","lease":60}`)
+	var response struct {
+		Data api.LeasedJob `json:"data"`
+	}
+	jsoniter.UnmarshalFromString(body, &response)
+	if status != fiber.StatusOK || response.Data.ID != job.ID || response.Data.CallbackSecret != "secret" {
+		t.Fatalf("Error LeaseJob: %v %v", status, body)
+	}
+	if leased, _ := database.GetJob(job.ID); leased.State != database.JobRunning || leased.Worker != "worker-1" {
+		t.Errorf("Error the job should be running on the worker: %+v", leased)
+	}
+
+	url := fmt.Sprintf("/jobs/%v/heartbeat", job.ID)
+	if status, _ := postJSON(t, app, url, `{"worker":"worker-1"}`); status != fiber.StatusOK {
+		t.Errorf("Error HeartbeatJob: %v", status)
+	}
+	if status, _ := postJSON(t, app, url, `{"worker":"worker-2"}`); status != fiber.StatusConflict {
+		t.Errorf("Error the heartbeat of another worker should be rejected: %v", status)
+	}
+
+	url = fmt.Sprintf("/jobs/%v/finish", job.ID)
+	if status, _ := postJSON(t, app, url, `{"worker":"worker-2"}`); status != fiber.StatusConflict {
+		t.Errorf("Error another worker should not finish the job: %v", status)
+	}
+	if status, _ := postJSON(t, app, url, `{"worker":"worker-1"}`); status != fiber.StatusOK {
+		t.Errorf("Error FinishJob: %v", status)
+	}
+	if finished, _ := database.GetJob(job.ID); finished.State != database.JobDone {
+		t.Errorf("Error the job should be done: %v", finished.State)
+	}
+	if status, _ := postJSON(t, app, fmt.Sprintf("/jobs/%v/heartbeat", job.ID), `{"worker":"worker-1"}`); status != fiber.StatusConflict {
+		t.Errorf("Error the finished job should no longer be leased: %v", status)
+	}
+}
+
+func TestUploadWorkspace(t *testing.T) {
+	app := initTestServer(t)
+	job := database.Job{Input: "example.com", Flow: "general"}
+	database.EnqueueJob(&job)
+	database.ClaimJob("worker-1", "host-a", 0, 0)
+
+	good := testArchive(t, map[string]string{"probing/http.txt": "https://example.com"})
+	rejected := []struct {
+		name      string
+		worker    string
+		workspace string
+		archive   []byte
+		status    int
+	}{
+		{"another worker", "worker-2", "example.com", good, fiber.StatusConflict},
+		{"workspace with the shell characters", "worker-1", "example.com;id", good, fiber.StatusBadRequest},
+		{"workspace path", "worker-1", "../example.com", good, fiber.StatusBadRequest},
+		{"workspace of another job", "worker-1", "other.com", good, fiber.StatusForbidden},
+		{"entry outside of the workspace", "worker-1", "example.com", testArchive(t, map[string]string{"../other.com/x.txt": "x"}), fiber.StatusBadRequest},
+		{"not an archive", "worker-1", "example.com", []byte("plain text"), fiber.StatusBadRequest},
+	}
+	for _, tc := range rejected {
+		if status, body := doRequest(t, app, uploadRequest(t, job.ID, tc.worker, tc.workspace, tc.archive)); status != tc.status {
+			t.Errorf("Error uploading the %v: %v %v", tc.name, status, body)
+		}
+	}
+	if utils.FileExists(path.Join(Opt.Env.WorkspacesFolder, "other.com", "x.txt")) {
+		t.Errorf("Error the entry outside of the workspace should not be extracted")
+	}
+
+	if status, body := doRequest(t, app, uploadRequest(t, job.ID, "worker-1", "example.com", good)); status != fiber.StatusOK {
+		t.Fatalf("Error UploadWorkspace: %v %v", status, body)
+	}
+	if content := utils.GetFileContent(path.Join(Opt.Env.WorkspacesFolder, "example.com", "probing", "http.txt")); content != "https://example.com" {
+		t.Errorf("Error the workspace should be extracted: %v", content)
+	}
+
+	// the finished job can't overwrite the workspace
+	database.FinishJob(job.ID, nil)
+	overwrite := testArchive(t, map[string]string{"probing/http.txt": "https://overwritten.com"})
+	if status, body := doRequest(t, app, uploadRequest(t, job.ID, "worker-1", "example.com", overwrite)); status != fiber.StatusConflict {
+		t.Errorf("Error the upload of the finished job should be rejected: %v %v", status, body)
+	}
+	if content := utils.GetFileContent(path.Join(Opt.Env.WorkspacesFolder, "example.com", "probing", "http.txt")); content != "https://example.com" {
+		t.Errorf("Error the workspace should not be overwritten: %v", content)
+	}
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/distribute"
+	"github.com/whoamikiddie/vulnx/execution"
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/metrics"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// diskUsageTTL walking the workspaces folder is slow so the size is cached this long
+const diskUsageTTL = 5 * time.Minute
+
+var (
+	scansDesc = prometheus.NewDesc(metrics.Namespace+"_scans",
+		"Number of the scans in the database per flow and status", []string{"flow", "status"}, nil)
+	queueDesc = prometheus.NewDesc(metrics.Namespace+"_queue_jobs",
+		"Number of the jobs in the queue per state", []string{"state"}, nil)
+	outboxDesc = prometheus.NewDesc(metrics.Namespace+"_notification_outbox",
+		"Number of the notifications waiting in the outbox per notifier and status", []string{"notifier", "status"}, nil)
+	instancesDesc = prometheus.NewDesc(metrics.Namespace+"_cloud_instances",
+		"Number of the cloud instances alive per provider", []string{"provider"}, nil)
+	diskDesc = prometheus.NewDesc(metrics.Namespace+"_workspaces_disk_bytes",
+		"Disk usage of the workspaces folder in bytes", nil, nil)
+)
+
+// stateCollector read the state shared by all the processes (database, queue, outbox, instances, workspaces) on every scrape
+type stateCollector struct {
+	options libs.Options
+
+	mu          sync.Mutex
+	diskUsage   int64
+	diskUpdated time.Time
+}
+
+func (s *stateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- scansDesc
+	ch <- queueDesc
+	ch <- outboxDesc
+	ch <- instancesDesc
+	ch <- diskDesc
+}
+
+func (s *stateCollector) Collect(ch chan<- prometheus.Metric) {
+	if database.DB != nil {
+		if counts, err := database.CountScans(); err == nil {
+			for _, count := range counts {
+				ch <- prometheus.MustNewConstMetric(scansDesc, prometheus.GaugeValue, float64(count.Total), count.Flow, count.Status)
+			}
+		}
+		if counts, err := database.CountJobs(); err == nil {
+			for _, state := range []string{database.JobPending, database.JobRunning, database.JobDone, database.JobFailed, database.JobCancelled} {
+				ch <- prometheus.MustNewConstMetric(queueDesc, prometheus.GaugeValue, float64(counts[state]), state)
+			}
+		}
+	}
+
+	// the outbox is only read, GetOutbox would replace the notifiers of the outbox of the process
+	if dir := execution.OutboxDir(s.options); dir != "" {
+		if entries, err := execution.ReadOutbox(dir); err == nil {
+			counts := make(map[[2]string]int)
+			for _, entry := range entries {
+				counts[[2]string{entry.Notifier, entry.Status}]++
+			}
+			for key, total := range counts {
+				ch <- prometheus.MustNewConstMetric(outboxDesc, prometheus.GaugeValue, float64(total), key[0], key[1])
+			}
+		}
+	}
+
+	for provider, total := range distribute.CountInstances(s.options) {
+		ch <- prometheus.MustNewConstMetric(instancesDesc, prometheus.GaugeValue, float64(total), provider)
+	}
+
+	ch <- prometheus.MustNewConstMetric(diskDesc, prometheus.GaugeValue, float64(s.workspacesSize()))
+}
+
+// workspacesSize the size of the workspaces folder, it's computed again once the cached value is too old
+func (s *stateCollector) workspacesSize() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if time.Since(s.diskUpdated) < diskUsageTTL {
+		return s.diskUsage
+	}
+	var total int64
+	filepath.WalkDir(utils.NormalizePath(s.options.Env.WorkspacesFolder), func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			total += info.Size()
+		}
+		return nil
+	})
+	s.diskUsage = total
+	s.diskUpdated = time.Now()
+	return total
+}
+
+var registerOnce sync.Once
+
+// RegisterMetrics add the collector of the server state to the metrics registry
+func RegisterMetrics(options libs.Options) {
+	registerOnce.Do(func() {
+		if err := metrics.Register(&stateCollector{options: options}); err != nil {
+			utils.ErrorF("Error registering the metrics: %v", err)
+		}
+	})
+}
+
+// StartMetricsServer only serve the metrics on the bind address, without the authentication and the rest of the API
+func StartMetricsServer(bind string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	utils.InforF("Metrics available at: http://%v/metrics", bind)
+	if err := http.ListenAndServe(bind, mux); err != nil {
+		utils.ErrorF("Error starting the metrics server: %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/whoamikiddie/vulnx/execution"
+	"github.com/whoamikiddie/vulnx/libs"
+)
+
+func TestStateCollectorOutbox(t *testing.T) {
+	notifier := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer notifier.Close()
+
+	var opt libs.Options
+	opt.Env.RootFolder = t.TempDir()
+	opt.Env.WorkspacesFolder = t.TempDir()
+	opt.Noti.Notifiers = map[string]libs.NotifierConfig{
+		"ops": {Type: "webhook", URL: notifier.URL},
+	}
+	outbox := execution.GetOutbox(opt)
+	outbox.Add("ops", execution.NewMessage(execution.ClassStatus, "", "pending", opt))
+	entry, err := outbox.Claim("ops", execution.NewMessage(execution.ClassStatus, "", "claimed", opt))
+	if err != nil {
+		t.Fatalf("Error Claim: %v", err)
+	}
+
+	// the options of the server don't have the notifiers of the process
+	scrapeOpt := opt
+	scrapeOpt.Noti.Notifiers = nil
+	collector := &stateCollector{options: scrapeOpt}
+	ch := make(chan prometheus.Metric, 100)
+	collector.Collect(ch)
+	close(ch)
+	// one metric for the pending entry of ops, the claimed entry is being sent
+	var outboxMetrics int
+	for metric := range ch {
+		if metric.Desc() == outboxDesc {
+			outboxMetrics++
+		}
+	}
+	if outboxMetrics != 1 {
+		t.Errorf("Error the outbox metrics: %v", outboxMetrics)
+	}
+
+	if err := outbox.Send(entry); err != nil {
+		t.Errorf("Error the scrape should not change the notifiers of the outbox: %v", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/whoamikiddie/vulnx/api"
 	"github.com/whoamikiddie/vulnx/core"
 	"github.com/whoamikiddie/vulnx/execution"
 	"github.com/whoamikiddie/vulnx/libs"
@@ -26,10 +27,10 @@ func Process(c *fiber.Ctx) error {
 func RawWorkspace(c *fiber.Ctx) error {
 	return c.JSON(ResponseHTTP{
 		Status: 200,
-		Data: fiber.Map{
-			"storages":   fmt.Sprintf("/%s/storages/", Opt.Server.StaticPrefix),
-			"workspaces": fmt.Sprintf("/%s/workspaces/", Opt.Server.StaticPrefix),
-			"logs":       fmt.Sprintf("/%s/logs/", Opt.Server.StaticPrefix),
+		Data: api.RawFolders{
+			Storages:   fmt.Sprintf("/%s/storages/", Opt.Server.StaticPrefix),
+			Workspaces: fmt.Sprintf("/%s/workspaces/", Opt.Server.StaticPrefix),
+			Logs:       fmt.Sprintf("/%s/logs/", Opt.Server.StaticPrefix),
 		},
 		Type:    "raw",
 		Message: "Raw directory",
@@ -44,15 +45,15 @@ func ListFlows(c *fiber.Ctx) error {
 		})
 	}
 
-	var result []map[string]string
+	var result []api.Flow
 
 	for _, flow := range flows {
 		if flow != "" {
-			item := make(map[string]string)
-			item["name"] = strings.TrimSuffix(filepath.Base(flow), ".yaml")
+			var item api.Flow
+			item.Name = strings.TrimSuffix(filepath.Base(flow), ".yaml")
 
 			// get modules
-			Opt.Flow.Type = strings.TrimSuffix(item["name"], path.Ext(item["name"]))
+			Opt.Flow.Type = strings.TrimSuffix(item.Name, path.Ext(item.Name))
 			rawModules := core.ListModules(Opt)
 			var modules []string
 			for _, module := range rawModules {
@@ -61,13 +62,12 @@ func ListFlows(c *fiber.Ctx) error {
 				}
 			}
 
-			item["desc"] = ""
 			parsedFlow, err := core.ParseFlow(flow)
 			if err == nil {
-				item["desc"] = parsedFlow.Desc
+				item.Desc = parsedFlow.Desc
 			}
 
-			item["modules"] = strings.Join(modules, ",")
+			item.Modules = strings.Join(modules, ",")
 			result = append(result, item)
 
 		}
@@ -89,10 +89,10 @@ func HelperMessage(c *fiber.Ctx) error {
 
 	return c.JSON(ResponseHTTP{
 		Status: 200,
-		Data: fiber.Map{
-			"version": libs.VERSION,
-			"doc":     libs.DOCS,
-			"message": message,
+		Data: api.Help{
+			Version: libs.VERSION,
+			Doc:     libs.DOCS,
+			Message: message,
 		},
 		Type:    "helper",
 		Message: "Helper message",
@@ -0,0 +1,24 @@
+package server
+
+import (
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/whoamikiddie/vulnx/api"
+	"github.com/whoamikiddie/vulnx/libs"
+)
+
+var (
+	openAPIOnce sync.Once
+	openAPIDoc  []byte
+)
+
+// OpenAPI serve the OpenAPI document of the API, the keys are sorted so the document is stable
+func OpenAPI(c *fiber.Ctx) error {
+	openAPIOnce.Do(func() {
+		openAPIDoc, _ = jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(api.OpenAPI(libs.VERSION))
+	})
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(openAPIDoc)
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/whoamikiddie/vulnx/api"
 	"github.com/whoamikiddie/vulnx/libs"
 )
 
@@ -33,8 +34,8 @@ func Ping(c *fiber.Ctx) error {
 func Health(c *fiber.Ctx) error {
 	return c.JSON(ResponseHTTP{
 		Status: 200,
-		Data: fiber.Map{
-			"version": libs.VERSION,
+		Data: api.Version{
+			Version: libs.VERSION,
 		},
 		Message: "server is up",
 	})
@@ -11,9 +11,12 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	jwtware "github.com/gofiber/jwt/v2"
+	"github.com/whoamikiddie/vulnx/core"
+	"github.com/whoamikiddie/vulnx/database"
 	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/metrics"
 	"github.com/whoamikiddie/vulnx/utils"
 
 	"github.com/gofiber/fiber/v2/middleware/filesystem"
@@ -31,11 +34,36 @@ func StartServer(options libs.Options) {
 		fmt.Fprintf(os.Stderr, color.RedString("[Critical] The server is currently being executed %v mechanism enabled.\n", color.HiYellowString("WITHOUT ANY AUTHENTICATION")))
 	}
 
+	// first start with the database, import the existing workspaces so the UI isn't empty
+	if database.DB != nil {
+		var total int64
+		database.DB.Model(&database.Target{}).Count(&total)
+		if total == 0 {
+			imported := database.ImportWorkspaces(options)
+			utils.InforF("Imported %v existing workspaces to the database", color.HiMagentaString("%v", imported))
+		}
+		EnsureAdminUser()
+	}
+	// the workspaces are listed from memory instead of reading every runtime file on each request
+	database.StartWorkspaceIndex(options)
+
 	app := fiber.New(fiber.Config{
 		Prefork: options.Server.PreFork,
+		// the remote workers upload the whole workspace archive
+		BodyLimit: 1024 * 1024 * 1024,
 	})
 	app.Use(cors.New())
 	SetupRoutes(app)
+	StartJobReaper()
+	core.StartScheduler(options)
+	// the scans of the API are run in this process, the prefork children only serve the requests
+	if options.Server.Executors > 0 && !fiber.IsChild() {
+		go core.RunJobPool(options, options.Server.Executors)
+	}
+	RegisterMetrics(options)
+	if options.Server.MetricsBind != "" && !fiber.IsChild() {
+		go StartMetricsServer(options.Server.MetricsBind)
+	}
 
 	// mean enable SSL
 	var enableSSL bool
@@ -93,40 +121,46 @@ func SetupRoutes(app *fiber.App) {
 	}))
 
 	app.Get("/ping", Ping)
-	api := app.Group("/api", logger.New())
+	// Prometheus metrics, the scraper could use the API token as the bearer token
+	app.Get("/metrics", Authenticate, RequireRole(database.RoleViewer), adaptor.HTTPHandler(metrics.Handler()))
+	api := app.Group("/api", logger.New(), AuditTrail)
 	api.Post("/login", Login)
+	// OpenAPI document of the routes below, built from the route table of the api package
+	api.Get("/openapi.json", OpenAPI)
 
-	// disable JWT Middleware when -A is set
-	if !Opt.Server.NoAuthen {
-		app.Use(jwtware.New(jwtware.Config{
-			SigningKey:     []byte(Opt.Server.JWTSecret),
-			Filter:         nil,
-			SuccessHandler: nil,
-			ErrorHandler:   jwtError,
-			SigningKeys:    nil,
-			SigningMethod:  "",
-			ContextKey:     "",
-			Claims:         nil,
-			TokenLookup:    "",
-			AuthScheme:     "Osmedeus",
-		}))
-	}
+	// every route of the group needs the JWT or the API token, the role is checked per route
+	// the authentication is skipped when -A is set
+	osmp := api.Group("/osmp", Authenticate)
+	viewer := RequireRole(database.RoleViewer)
+	operator := RequireRole(database.RoleOperator)
+	admin := RequireRole(database.RoleAdmin)
 
-	// Middleware
-	osmp := api.Group("/osmp")
 	// /api/osmp/health
-	osmp.Get("/health", Health)
+	osmp.Get("/health", viewer, Health)
 
 	// core API e.g: /api/osmp/workspaces
-	osmp.Get("/workspaces", ListWorkspaces)
-	osmp.Get("/workspace/:wsname/", WorkspaceDetail)
-	osmp.Get("/scans", ListAllScan)
-	osmp.Delete("/delete/:wsname/", DeleteWorkspace)
-
-	osmp.Get("/ps", Process)
-	osmp.Get("/raw", RawWorkspace)
-	osmp.Get("/flows", ListFlows)
-	osmp.Get("/help", HelperMessage)
+	osmp.Get("/workspaces", viewer, ListWorkspaces)
+	osmp.Get("/workspace/:wsname/", viewer, WorkspaceDetail)
+	osmp.Get("/workspace/:wsname/changes", viewer, WorkspaceChanges)
+	osmp.Get("/workspace/:wsname/archive", viewer, WorkspaceArchive)
+	osmp.Get("/scans", viewer, ListAllScan)
+	osmp.Delete("/delete/:wsname/", admin, DeleteWorkspace)
+
+	osmp.Get("/ps", viewer, Process)
+	osmp.Get("/raw", viewer, RawWorkspace)
+	osmp.Get("/flows", viewer, ListFlows)
+	osmp.Get("/help", viewer, HelperMessage)
+
+	// users and API tokens
+	osmp.Get("/me", viewer, Me)
+	osmp.Get("/tokens", viewer, ListTokens)
+	osmp.Post("/tokens", viewer, CreateToken)
+	osmp.Delete("/tokens/:id", viewer, RevokeToken)
+	osmp.Get("/users", admin, ListUsers)
+	osmp.Post("/users", admin, AddUser)
+	osmp.Put("/users/:username", admin, UpdateUser)
+	osmp.Delete("/users/:username", admin, DeleteUser)
+	osmp.Get("/audit", admin, ListAudit)
 
 	//api.Use(basicauth.New(basicauth.Config{
 	//	Users: map[string]string{
@@ -140,6 +174,27 @@ func SetupRoutes(app *fiber.App) {
 	//
 
 	// execute endpoints
-	osmp.Post("/execute", NewScan)
-	osmp.Post("/upload", Upload)
+	osmp.Post("/execute", operator, NewScan)
+	osmp.Post("/upload", operator, Upload)
+	osmp.Post("/scans/:id/cancel", operator, ControlScan(core.ScanCancel))
+	osmp.Post("/scans/:id/pause", operator, ControlScan(core.ScanPause))
+	osmp.Post("/scans/:id/resume", operator, ControlScan(core.ScanResume))
+	// live events of the scan, Server-Sent Events or WebSocket
+	osmp.Get("/scans/:id/stream", viewer, StreamScan)
+	osmp.Get("/scans/:id/ws", viewer, StreamScanUpgrade, StreamScanWS)
+
+	// queue endpoints, the remote workers lease the jobs from here
+	osmp.Get("/jobs", viewer, ListJobs)
+	osmp.Get("/jobs/:id", viewer, JobDetail)
+	osmp.Post("/jobs/lease", operator, LeaseJob)
+	osmp.Post("/jobs/:id/heartbeat", operator, HeartbeatJob)
+	osmp.Post("/jobs/:id/finish", operator, FinishJob)
+	osmp.Post("/jobs/:id/upload", operator, UploadWorkspace)
+
+	// cron schedules, the due runs are added to the queue by the scheduler of the server
+	osmp.Get("/schedules", viewer, ListSchedules)
+	osmp.Post("/schedules", operator, AddSchedule)
+	osmp.Post("/schedules/:name/pause", operator, PauseSchedule)
+	osmp.Post("/schedules/:name/resume", operator, ResumeSchedule)
+	osmp.Delete("/schedules/:name", operator, DeleteSchedule)
 }
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/whoamikiddie/vulnx/api"
+)
+
+// TestRoutesDocumented every route of /api should be in the route table of the OpenAPI document and the other way around
+func TestRoutesDocumented(t *testing.T) {
+	app := fiber.New()
+	SetupRoutes(app)
+
+	registered := make(map[string]bool)
+	for _, route := range app.GetRoutes(true) {
+		if !strings.HasPrefix(route.Path, "/api/") || route.Method == fiber.MethodHead || route.Method == "USE" {
+			continue
+		}
+		registered[route.Method+" "+route.Path] = true
+		if api.FindRoute(route.Method, route.Path) == nil {
+			t.Errorf("Error the route %v %v is missing in api.Routes", route.Method, route.Path)
+		}
+	}
+	for _, route := range api.Routes {
+		if !registered[route.Method+" "+route.Path] {
+			t.Errorf("Error the route %v %v of api.Routes is not registered", route.Method, route.Path)
+		}
+	}
+}
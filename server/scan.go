@@ -1,92 +1,119 @@
 package server
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
 	"github.com/gofiber/fiber/v2"
+	"github.com/whoamikiddie/vulnx/api"
+	"github.com/whoamikiddie/vulnx/core"
+	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/execution"
+	"github.com/whoamikiddie/vulnx/libs"
 	"github.com/whoamikiddie/vulnx/utils"
 )
 
-// TaskData data required in json form
-type TaskData struct {
-	MasterPassword string `json:"password"`
-	Binary         string `json:"binary"`
-	// override everything below
-	Command string `json:"command"`
-
-	// these two not be blank when run with plugins
-	WorkFlow   string `json:"workflow"`
-	PluginName string `json:"plugin"`
-	ScanID     string `json:"scan_id"`
-
-	// for select scan + task
-	Workspace   string   `json:"workspace"`
-	Target      string   `json:"target"`
-	TargetsList []string `json:"targets"`
-	TargetsFile string   `json:"targets_file"`
-
-	AliveAssets bool `json:"alive_assets"` // skip targets part and select the assets from DB
-	AllAssets   bool `json:"all_assets"`   // skip targets part and select the assets from DB
-
-	// just more mics info for custom command later
-	Params      []string `json:"params"`
-	Timeout     string   `json:"timeout"`
-	Concurrency int      `json:"concurrency"`
-
-	// enable distributed scan
-	Distributed bool `json:"distributed"`
-
-	// for chunk mode only
-	Threads      int  `json:"threads"`
-	Chunk        bool `json:"chunk"`
-	TargetAsFile bool `json:"as_file"`
-
-	// only select record not run the command
-	RawName  bool `json:"RawName"`
-	WildCard bool `json:"wildcard"`
-	ViewOnly bool `json:"view_only"`
-	Debug    bool `json:"debug"`
-	Test     bool `json:"test"`
-}
+// TaskData data required in json form, see api.ScanRequest
+type TaskData = api.ScanRequest
 
-// NewScan new scan
+// NewScan validate the scan and add it to the queue, the job pool of the server runs it in-process
 func NewScan(c *fiber.Ctx) error {
 	var taskData TaskData
-	var invalid bool
-
-	err := c.BodyParser(&taskData)
-	if err != nil {
-		invalid = true
-	}
-
-	if invalid {
+	if err := c.BodyParser(&taskData); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Cannot parse JSON",
 		})
 	}
 
-	taskData.Command = CommandBuilder(&taskData)
-	// get workspace if we didn't have one
-	if taskData.Workspace == "" {
-		taskData.Workspace = utils.CleanPath(taskData.Target)
+	job, err := scanJob(&taskData, Opt)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
 	}
-	utils.InforF("Running command: %v", taskData.Command)
-
-	if !taskData.Test {
-		go func() {
-			utils.RunOSCommand(taskData.Command)
-			return
-		}()
+	if taskData.Test {
+		return c.JSON(ResponseHTTP{
+			Status:  200,
+			Data:    job,
+			Type:    "new-scan",
+			Message: "Valid scan",
+		})
 	}
 
+	created, err := database.EnqueueJob(&job)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	message := "New Scan Queued"
+	if !created {
+		message = "The same scan is already in the queue"
+	}
+	utils.InforF("Queued the job %v: %v", color.HiMagentaString("#%v", job.ID), color.HiCyanString(job.Input))
 	return c.JSON(ResponseHTTP{
 		Status: 200,
-		Data: fiber.Map{
-			"command": taskData.Command,
+		Data: api.ScanQueued{
+			JobID:     job.ID,
+			State:     job.State,
+			Input:     job.Input,
+			Workspace: job.Workspace,
 		},
 		Type:    "new-scan",
-		Message: "New Scan Imported",
+		Message: message,
 	})
 }
 
+// scanJob convert the request to the job of the queue, see core.ValidateJob
+func scanJob(taskData *TaskData, options libs.Options) (database.Job, error) {
+	var job database.Job
+	if taskData.Command != "" {
+		return job, fmt.Errorf("raw command is not allowed, please use the workflow or plugin field")
+	}
+	if taskData.Distributed {
+		return job, fmt.Errorf("distributed scan is not supported by this endpoint")
+	}
+
+	job.Input = taskData.Target
+	switch {
+	case len(taskData.TargetsList) > 0:
+		if err := core.CheckTargets(taskData.TargetsList); err != nil {
+			return job, err
+		}
+		job.Input = core.SaveTargets(taskData.TargetsList)
+		job.InputAsFile = true
+	case taskData.TargetsFile != "" || taskData.TargetAsFile:
+		if taskData.TargetsFile != "" {
+			job.Input = taskData.TargetsFile
+		}
+		// only the files sent by the upload endpoint are accepted
+		job.InputAsFile = true
+	}
+	job.Params = taskData.Params
+	job.Workspace = taskData.Workspace
+
+	if taskData.PluginName != "" {
+		for _, module := range strings.Split(taskData.PluginName, ",") {
+			if module = strings.TrimSpace(module); module != "" {
+				job.Modules = append(job.Modules, module)
+			}
+		}
+	} else {
+		job.Flow = strings.TrimSpace(taskData.WorkFlow)
+		if job.Flow == "" {
+			job.Flow = "general"
+		}
+	}
+	if err := core.ValidateJob(&job, options); err != nil {
+		return job, err
+	}
+
+	job.CallbackURL = taskData.CallbackURL
+	job.CallbackSecret = taskData.CallbackSecret
+	return job, nil
+}
+
 // NewScanCloud new scan
 func NewScanCloud(c *fiber.Ctx) error {
 	var taskData TaskData
@@ -112,7 +139,7 @@ func NewScanCloud(c *fiber.Ctx) error {
 
 	if !taskData.Test {
 		go func() {
-			utils.RunOSCommand(taskData.Command)
+			utils.RunOSCommandEnv(taskData.Command, execution.CallbackEnv(taskData.CallbackURL, taskData.CallbackSecret))
 			return
 		}()
 	}
@@ -128,3 +155,22 @@ func NewScanCloud(c *fiber.Ctx) error {
 		Message: "New Scan Imported",
 	})
 }
+
+// ControlScan cancel, pause or resume the running scan, the id could be the workspace or the scan ID
+func ControlScan(action string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		scan, err := core.ControlScan(c.Params("id"), action, Opt)
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		scan.Target = database.Target{}
+		return c.JSON(ResponseHTTP{
+			Status:  200,
+			Data:    scan,
+			Type:    "scan",
+			Message: fmt.Sprintf("The %v action has been sent to the scan", action),
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"path"
+	"testing"
+
+	"github.com/whoamikiddie/vulnx/libs"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+func TestScanJob(t *testing.T) {
+	var options libs.Options
+	options.Env.WorkFlowsFolder = t.TempDir()
+	utils.WriteToFile(path.Join(options.Env.WorkFlowsFolder, "general.yaml"), "name: general")
+	utils.MakeDir(path.Join(options.Env.WorkFlowsFolder, "default-modules"))
+	utils.WriteToFile(path.Join(options.Env.WorkFlowsFolder, "default-modules", "probing.yaml"), "name: probing")
+	// the module sent by the upload endpoint
+	uploaded := path.Join(t.TempDir(), "x.yaml")
+	utils.WriteToFile(uploaded, "name: x")
+
+	rejected := []struct {
+		name string
+		task TaskData
+	}{
+		{"raw command", TaskData{Target: "example.com", Command: "id"}},
+		{"distributed", TaskData{Target: "example.com", Distributed: true}},
+		{"no target", TaskData{}},
+		{"and", TaskData{Target: "example.com&&id"}},
+		{"subshell", TaskData{Target: "$(id)"}},
+		{"parentheses", TaskData{Target: "example.com(id)"}},
+		{"braces", TaskData{Target: "{a,b}.example.com"}},
+		{"glob", TaskData{Target: "*.example.com"}},
+		{"space", TaskData{Target: "example.com id"}},
+		{"option", TaskData{Target: "--help"}},
+		{"targets list", TaskData{TargetsList: []string{"example.com", "a.com;id"}}},
+		{"targets file outside of the uploads", TaskData{TargetsFile: "/etc/passwd"}},
+		{"workspace", TaskData{Target: "example.com", Workspace: "ws$(id)"}},
+		{"workspace path", TaskData{Target: "example.com", Workspace: "../ws"}},
+		{"param without value", TaskData{Target: "example.com", Params: []string{"threads"}}},
+		{"param", TaskData{Target: "example.com", Params: []string{"threads=10&id"}}},
+		{"param key", TaskData{Target: "example.com", Params: []string{"a b=1"}}},
+		{"unknown flow", TaskData{Target: "example.com", WorkFlow: "not-exist"}},
+		{"unknown module", TaskData{Target: "example.com", PluginName: "probing,not-exist"}},
+		{"plugin path", TaskData{Target: "example.com", PluginName: uploaded}},
+		{"workflow path", TaskData{Target: "example.com", WorkFlow: uploaded}},
+	}
+	for _, tc := range rejected {
+		task := tc.task
+		if _, err := scanJob(&task, options); err == nil {
+			t.Errorf("Error scanJob should reject the %v: %+v", tc.name, tc.task)
+		}
+	}
+
+	accepted := []TaskData{
+		{Target: "example.com"},
+		{Target: "https://example.com:8443/login", Workspace: "example.com_login", Params: []string{"threads=10", "severity=critical,high"}},
+		{Target: "10.0.0.0/24", PluginName: "probing"},
+	}
+	for _, task := range accepted {
+		job, err := scanJob(&task, options)
+		if err != nil {
+			t.Errorf("Error scanJob %+v: %v", task, err)
+			continue
+		}
+		if job.Input != task.Target || job.Workspace != task.Workspace {
+			t.Errorf("Error scanJob job = %+v", job)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/whoamikiddie/vulnx/core"
+	"github.com/whoamikiddie/vulnx/database"
+)
+
+// ListSchedules list all the schedules
+func ListSchedules(c *fiber.Ctx) error {
+	schedules, err := database.ListSchedules()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(ResponseHTTP{
+		Status:  200,
+		Data:    schedules,
+		Type:    "schedules",
+		Total:   len(schedules),
+		Message: "List the schedules",
+	})
+}
+
+// AddSchedule create a new schedule from the JSON body
+func AddSchedule(c *fiber.Ctx) error {
+	var schedule database.Schedule
+	if err := c.BodyParser(&schedule); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	// only the settings of the schedule are taken from the body
+	schedule.ID = 0
+	schedule.Paused = false
+	schedule.LastRun = nil
+	schedule.LastJobID = 0
+	schedule.LastStatus = ""
+	if err := core.AddSchedule(&schedule, Opt); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(ResponseHTTP{
+		Status:  200,
+		Data:    schedule,
+		Type:    "schedule",
+		Message: "Schedule created",
+	})
+}
+
+// PauseSchedule pause the schedule by its name
+func PauseSchedule(c *fiber.Ctx) error {
+	return setSchedulePaused(c, true)
+}
+
+// ResumeSchedule resume the schedule by its name
+func ResumeSchedule(c *fiber.Ctx) error {
+	return setSchedulePaused(c, false)
+}
+
+func setSchedulePaused(c *fiber.Ctx, paused bool) error {
+	schedule, err := core.PauseSchedule(c.Params("name"), paused)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	message := "Schedule resumed"
+	if paused {
+		message = "Schedule paused"
+	}
+	return c.JSON(ResponseHTTP{
+		Status:  200,
+		Data:    schedule,
+		Type:    "schedule",
+		Message: message,
+	})
+}
+
+// DeleteSchedule delete the schedule by its name
+func DeleteSchedule(c *fiber.Ctx) error {
+	if err := database.DeleteSchedule(c.Params("name")); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(ResponseHTTP{
+		Status:  200,
+		Type:    "schedule",
+		Message: "Schedule deleted",
+	})
+}
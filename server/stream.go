@@ -0,0 +1,129 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/spf13/cast"
+	"github.com/whoamikiddie/vulnx/core"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+// streamKeepAlive how often the comment is sent to the idle stream so the dead clients are noticed
+const streamKeepAlive = 15 * time.Second
+
+// errBacklogSent stop the stream once the existing events are sent, with the backlog query
+var errBacklogSent = errors.New("backlog sent")
+
+// streamWorkspace the workspace folder of the scan in the id param
+func streamWorkspace(c *fiber.Ctx) (string, error) {
+	workspace, _, err := core.ResolveScan(c.Params("id"))
+	if err != nil {
+		return "", err
+	}
+	workspaceFolder := path.Join(utils.NormalizePath(Opt.Env.WorkspacesFolder), workspace)
+	if !utils.FolderExists(workspaceFolder) {
+		return "", fmt.Errorf("workspace %v not found", workspace)
+	}
+	return workspaceFolder, nil
+}
+
+// StreamScan push the events of the scan with Server-Sent Events, the backlog is replayed first
+// the Last-Event-ID header or the since query skips the events the client already got
+func StreamScan(c *fiber.Ctx) error {
+	workspaceFolder, err := streamWorkspace(c)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	since := cast.ToInt(c.Get("Last-Event-ID"))
+	if raw := c.Query("since"); raw != "" {
+		since = cast.ToInt(raw)
+	}
+	backlog := c.QueryBool("backlog")
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		lastWrite := time.Now()
+		send := func(event core.ScanEvent) error {
+			data, err := jsoniter.MarshalToString(event)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "id: %v\nevent: %v\ndata: %v\n\n", event.ID, event.Type, data)
+			lastWrite = time.Now()
+			return w.Flush()
+		}
+		idle := func() error {
+			if backlog {
+				return errBacklogSent
+			}
+			if time.Since(lastWrite) < streamKeepAlive {
+				return nil
+			}
+			lastWrite = time.Now()
+			fmt.Fprint(w, ": keep-alive\n\n")
+			return w.Flush()
+		}
+		if err := core.FollowEvents(workspaceFolder, since, nil, send, idle); err != nil && err != errBacklogSent {
+			utils.DebugF("Scan stream closed: %v", err)
+		}
+	})
+	return nil
+}
+
+// StreamScanUpgrade only let the WebSocket requests through to StreamScanWS
+func StreamScanUpgrade(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return fiber.ErrUpgradeRequired
+	}
+	workspaceFolder, err := streamWorkspace(c)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	c.Locals("workspaceFolder", workspaceFolder)
+	return c.Next()
+}
+
+// StreamScanWS push the same events as StreamScan as JSON messages over the WebSocket
+var StreamScanWS = websocket.New(func(conn *websocket.Conn) {
+	workspaceFolder := cast.ToString(conn.Locals("workspaceFolder"))
+	since := cast.ToInt(conn.Query("since"))
+	var idle func() error
+	if cast.ToBool(conn.Query("backlog")) {
+		idle = func() error {
+			return errBacklogSent
+		}
+	}
+
+	// the client is gone once the read fails
+	stop := make(chan struct{})
+	go func() {
+		defer close(stop)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(event core.ScanEvent) error {
+		return conn.WriteJSON(event)
+	}
+	if err := core.FollowEvents(workspaceFolder, since, stop, send, idle); err != nil && err != errBacklogSent {
+		utils.DebugF("Scan stream closed: %v", err)
+	}
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+})
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/spf13/cast"
+	"github.com/whoamikiddie/vulnx/api"
+	"github.com/whoamikiddie/vulnx/database"
+)
+
+// Me the user of the request
+func Me(c *fiber.Ctx) error {
+	return c.JSON(ResponseHTTP{
+		Status:  200,
+		Data:    CurrentUser(c),
+		Type:    "user",
+		Message: "Current user",
+	})
+}
+
+// ListUsers list all the users
+func ListUsers(c *fiber.Ctx) error {
+	users, err := database.ListUsers()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(ResponseHTTP{
+		Status:  200,
+		Data:    users,
+		Type:    "users",
+		Total:   len(users),
+		Message: "List the users",
+	})
+}
+
+// UserInput the body to create or update the user, see api.UserRequest
+type UserInput = api.UserRequest
+
+// AddUser create the user from the JSON body
+func AddUser(c *fiber.Ctx) error {
+	var input UserInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	user, err := database.CreateUser(input.Username, input.Password, input.Role)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(ResponseHTTP{
+		Status:  200,
+		Data:    user,
+		Type:    "user",
+		Message: "User created",
+	})
+}
+
+// UpdateUser change the password, the role or the disabled flag of the user
+func UpdateUser(c *fiber.Ctx) error {
+	var input UserInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	username := c.Params("username")
+	// the admin shouldn't lock themselves out
+	if current := CurrentUser(c); current != nil && current.Username == username {
+		if (input.Role != "" && input.Role != database.RoleAdmin) || (input.Disabled != nil && *input.Disabled) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "can't demote or disable your own account",
+			})
+		}
+	}
+	user, err := database.UpdateUser(username, input.Password, input.Role, input.Disabled)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(ResponseHTTP{
+		Status:  200,
+		Data:    user,
+		Type:    "user",
+		Message: "User updated",
+	})
+}
+
+// DeleteUser remove the user and its tokens
+func DeleteUser(c *fiber.Ctx) error {
+	username := c.Params("username")
+	if current := CurrentUser(c); current != nil && current.Username == username {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "can't delete your own account",
+		})
+	}
+	if err := database.DeleteUser(username); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(ResponseHTTP{
+		Status:  200,
+		Data:    username,
+		Type:    "user",
+		Message: "User deleted",
+	})
+}
+
+// ListTokens list the API tokens of the user, the admin could list all of them with ?all=true
+func ListTokens(c *fiber.Ctx) error {
+	user := CurrentUser(c)
+	username := user.Username
+	if user.Role == database.RoleAdmin && cast.ToBool(c.Query("all")) {
+		username = ""
+	}
+	tokens, err := database.ListAPITokens(username)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(ResponseHTTP{
+		Status:  200,
+		Data:    tokens,
+		Type:    "tokens",
+		Total:   len(tokens),
+		Message: "List the API tokens",
+	})
+}
+
+// CreateToken create the API token of the user, the raw token is only shown in this response
+func CreateToken(c *fiber.Ctx) error {
+	var input api.TokenRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	raw, token, err := database.CreateAPIToken(CurrentUser(c).Username, input.Name, time.Duration(input.TTL)*24*time.Hour)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(ResponseHTTP{
+		Status: 200,
+		Data: api.TokenCreated{
+			Token: raw,
+			Info:  *token,
+		},
+		Type:    "token",
+		Message: "API token created, it won't be shown again",
+	})
+}
+
+// RevokeToken revoke the API token of the user, the admin could revoke any token
+func RevokeToken(c *fiber.Ctx) error {
+	user := CurrentUser(c)
+	username := user.Username
+	if user.Role == database.RoleAdmin {
+		username = ""
+	}
+	if err := database.RevokeAPIToken(cast.ToUint(c.Params("id")), username); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(ResponseHTTP{
+		Status:  200,
+		Data:    c.Params("id"),
+		Type:    "token",
+		Message: "API token revoked",
+	})
+}
@@ -5,21 +5,19 @@ import (
 	"os"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/thoas/go-funk"
+	"github.com/whoamikiddie/vulnx/api"
 	"github.com/whoamikiddie/vulnx/database"
+	"github.com/whoamikiddie/vulnx/execution"
+	"github.com/whoamikiddie/vulnx/libs"
 	"github.com/whoamikiddie/vulnx/utils"
 )
 
-// ResponseHTTP represents response body of this API
-type ResponseHTTP struct {
-	Status  int         `json:"status"`
-	Data    interface{} `json:"data"`
-	Type    string      `json:"type,omitempty"`
-	Total   int         `json:"total,omitempty"`
-	Message string      `json:"message"`
-}
+// ResponseHTTP represents response body of this API, see api.Response
+type ResponseHTTP = api.Response
 
 // Workspace is a function to get all books data from database
 // @Summary Get all books
@@ -30,16 +28,52 @@ type ResponseHTTP struct {
 // @Success 200 {object} ResponseHTTP{}
 // @Router /v1/workspaces [get]
 func ListWorkspaces(c *fiber.Ctx) error {
-	workspaces := database.GetAllScan(Opt)
+	workspaces, total, err := queryWorkspaces(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 	return c.JSON(ResponseHTTP{
 		Status:  200,
 		Data:    workspaces,
 		Type:    "workspaces",
-		Total:   len(workspaces),
+		Total:   total,
 		Message: "List all of Workspaces",
 	})
 }
 
+// queryWorkspaces the workspaces matching the query of the request, the total is counted before the pagination
+// e.g: ?flow=general&status=running&q=example&sort=findings&order=desc&page=2&limit=50
+func queryWorkspaces(c *fiber.Ctx) ([]database.Scan, int, error) {
+	params := api.WorkspaceQuery{Sort: "date", Order: "desc"}
+	if err := c.QueryParser(&params); err != nil {
+		return nil, 0, err
+	}
+	query := database.WorkspaceQuery{
+		Flow:   params.Flow,
+		Status: params.Status,
+		Search: params.Search,
+		Sort:   params.Sort,
+		Desc:   params.Order != "asc",
+		Offset: params.Offset,
+		Limit:  params.Limit,
+	}
+	if !funk.ContainsString([]string{"date", "name", "status", "findings"}, query.Sort) {
+		return nil, 0, fmt.Errorf("invalid sort %v, it should be date, name, status or findings", query.Sort)
+	}
+	if params.Page > 1 && query.Limit > 0 {
+		query.Offset = (params.Page - 1) * query.Limit
+	}
+
+	if index := database.CurrentWorkspaceIndex(); index != nil {
+		scans, total := index.Query(query)
+		return scans, total, nil
+	}
+	scans, total := query.Apply(database.GetAllScan(Opt))
+	return scans, total, nil
+}
+
 func WorkspaceDetail(c *fiber.Ctx) error {
 	wsname := c.Params("wsname")
 	workspace := database.GetSingleScan(wsname, Opt)
@@ -77,9 +111,9 @@ func WorkspaceDetail(c *fiber.Ctx) error {
 
 	return c.JSON(ResponseHTTP{
 		Status: 200,
-		Data: fiber.Map{
-			"workspace": workspace,
-			"reports":   reports,
+		Data: api.WorkspaceDetail{
+			Workspace: workspace,
+			Reports:   reports,
 		},
 		Type:    "workspace",
 		Message: "Workspace Detail ",
@@ -87,12 +121,21 @@ func WorkspaceDetail(c *fiber.Ctx) error {
 }
 
 func ListAllScan(c *fiber.Ctx) error {
-	scan := database.GetScanProgress(Opt)
+	scans, total, err := queryWorkspaces(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	// only the progress of the scans is needed here
+	for i := range scans {
+		scans[i].Target = database.Target{}
+	}
 	return c.JSON(ResponseHTTP{
 		Status:  200,
-		Data:    scan,
+		Data:    scans,
 		Type:    "scans",
-		Total:   len(scan),
+		Total:   total,
 		Message: "List all the scan process",
 	})
 }
@@ -110,9 +153,89 @@ func DeleteWorkspace(c *fiber.Ctx) error {
 	}
 
 	os.RemoveAll(wsDir)
+	if index := database.CurrentWorkspaceIndex(); index != nil {
+		index.Remove(path.Base(wsDir))
+	}
 	return c.JSON(ResponseHTTP{
 		Status:  200,
 		Type:    "delete",
 		Message: "Workspace Deleted",
 	})
 }
+
+// WorkspaceChanges summary of the assets appeared or vanished in the workspace
+// since and until query accept a date or a relative time, default is the changes of the latest scan
+func WorkspaceChanges(c *fiber.Ctx) error {
+	wsname := c.Params("wsname")
+
+	var err error
+	var since, until time.Time
+	if raw := c.Query("since"); raw != "" {
+		if since, err = utils.ParseTime(raw); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	}
+	if raw := c.Query("until"); raw != "" {
+		if until, err = utils.ParseTime(raw); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	changes, err := database.GetChanges(wsname, since, until)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(ResponseHTTP{
+		Status:  200,
+		Data:    changes,
+		Type:    "changes",
+		Total:   changes.Total(),
+		Message: "Asset changes of the workspace",
+	})
+}
+
+// WorkspaceArchive send the workspace folder as the tar.gz archive, it's used by the remote pull
+func WorkspaceArchive(c *fiber.Ctx) error {
+	// the workspace is compressed in the shell so only the plain names are accepted
+	workspace := path.Base(c.Params("wsname"))
+	wsDir := path.Join(utils.NormalizePath(Opt.Env.WorkspacesFolder), workspace)
+	if !utils.IsSafeName(workspace) || utils.FolderLength(wsDir) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "workspace didn't exist",
+		})
+	}
+
+	tmpDir, err := os.MkdirTemp("", fmt.Sprintf("%v-archive-", libs.BINARY))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	archive := path.Join(tmpDir, workspace+".tar.gz")
+	execution.Compress(archive, wsDir)
+	f, err := os.Open(archive)
+	// the open archive is still readable until it's sent
+	os.RemoveAll(tmpDir)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": fmt.Sprintf("error compressing the workspace %v", workspace),
+		})
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	c.Attachment(workspace + ".tar.gz")
+	c.Set(fiber.HeaderContentType, "application/gzip")
+	return c.SendStream(f, int(info.Size()))
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/whoamikiddie/vulnx/execution"
+	"github.com/whoamikiddie/vulnx/utils"
+)
+
+func TestWorkspaceArchive(t *testing.T) {
+	initTestServer(t)
+	app := fiber.New()
+	app.Get("/workspace/:wsname/archive", WorkspaceArchive)
+	for _, workspace := range []string{"example.com", "example.com;id"} {
+		utils.MakeDir(path.Join(Opt.Env.WorkspacesFolder, workspace))
+		utils.WriteToFile(path.Join(Opt.Env.WorkspacesFolder, workspace, "http.txt"), "https://example.com")
+	}
+
+	if status, _ := doRequest(t, app, httptestGet("/workspace/example.com;id/archive")); status != fiber.StatusNotFound {
+		t.Errorf("Error the workspace with the shell characters should be rejected: %v", status)
+	}
+	status, body := doRequest(t, app, httptestGet("/workspace/example.com/archive"))
+	if status != fiber.StatusOK {
+		t.Fatalf("Error WorkspaceArchive: %v", status)
+	}
+	archive := path.Join(t.TempDir(), "example.com.tar.gz")
+	os.WriteFile(archive, []byte(body), 0644)
+	dest := path.Join(t.TempDir(), "example.com")
+	if err := execution.ExtractArchive(dest, archive); err != nil {
+		t.Fatalf("Error extracting the archive: %v", err)
+	}
+	if content := strings.TrimSpace(utils.GetFileContent(path.Join(dest, "http.txt"))); content != "https://example.com" {
+		t.Errorf("Error the archive should contain the workspace: %v", content)
+	}
+}
@@ -0,0 +1,231 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// the kind of the diff line
+const (
+	DiffEqual   = " "
+	DiffAdded   = "+"
+	DiffRemoved = "-"
+)
+
+// diffEditLimit the maximum edit distance before falling back to the set based diff
+// this keeps the memory of the Myers trace bounded on the huge result files
+const diffEditLimit = 2000
+
+// DiffLine a line of the diff, OldLine and NewLine are the 1-based line numbers or 0 if the line doesn't exist there
+type DiffLine struct {
+	Kind    string `json:"kind"`
+	OldLine int    `json:"old_line,omitempty"`
+	NewLine int    `json:"new_line,omitempty"`
+	Text    string `json:"text"`
+}
+
+// DiffLines compute the line diff between two files without relying on git
+func DiffLines(a []string, b []string) []DiffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var lines []DiffLine
+	for i := 0; i < prefix; i++ {
+		lines = append(lines, DiffLine{Kind: DiffEqual, OldLine: i + 1, NewLine: i + 1, Text: a[i]})
+	}
+	lines = append(lines, myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], prefix)...)
+	for i := 0; i < suffix; i++ {
+		oldIndex, newIndex := len(a)-suffix+i, len(b)-suffix+i
+		lines = append(lines, DiffLine{Kind: DiffEqual, OldLine: oldIndex + 1, NewLine: newIndex + 1, Text: a[oldIndex]})
+	}
+	return lines
+}
+
+// DiffStat count the added and removed lines of the diff
+func DiffStat(lines []DiffLine) (added int, removed int) {
+	for _, line := range lines {
+		switch line.Kind {
+		case DiffAdded:
+			added++
+		case DiffRemoved:
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// myersDiff the Myers O(ND) diff, offset is the number of lines skipped before a and b
+func myersDiff(a []string, b []string, offset int) []DiffLine {
+	n, m := len(a), len(b)
+	total := n + m
+	if total == 0 {
+		return nil
+	}
+
+	v := make([]int, 2*total+2)
+	var trace [][]int
+	for d := 0; d <= total; d++ {
+		if d > diffEditLimit {
+			return setDiff(a, b, offset)
+		}
+		// keep the furthest reaching paths of the previous round to backtrack later
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[total-d:total+d+1])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[total+k-1] < v[total+k+1]) {
+				x = v[total+k+1]
+			} else {
+				x = v[total+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[total+k] = x
+			if x >= n && y >= m {
+				return backtrackDiff(trace, a, b, offset)
+			}
+		}
+	}
+	return setDiff(a, b, offset)
+}
+
+func backtrackDiff(trace [][]int, a []string, b []string, offset int) []DiffLine {
+	x, y := len(a), len(b)
+	var reversed []DiffLine
+	equal := func() {
+		reversed = append(reversed, DiffLine{Kind: DiffEqual, OldLine: offset + x, NewLine: offset + y, Text: a[x-1]})
+		x--
+		y--
+	}
+
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[k-1+d] < v[k+1+d]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[prevK+d]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			equal()
+		}
+		if x == prevX {
+			reversed = append(reversed, DiffLine{Kind: DiffAdded, NewLine: offset + y, Text: b[y-1]})
+			y--
+		} else {
+			reversed = append(reversed, DiffLine{Kind: DiffRemoved, OldLine: offset + x, Text: a[x-1]})
+			x--
+		}
+	}
+	for x > 0 && y > 0 {
+		equal()
+	}
+
+	lines := make([]DiffLine, len(reversed))
+	for i, line := range reversed {
+		lines[len(reversed)-1-i] = line
+	}
+	return lines
+}
+
+// setDiff compare the lines as a multiset, good enough for the unordered result files
+func setDiff(a []string, b []string, offset int) []DiffLine {
+	counter := make(map[string]int)
+	for _, line := range b {
+		counter[line]++
+	}
+	var lines []DiffLine
+	for i, line := range a {
+		if counter[line] > 0 {
+			counter[line]--
+			continue
+		}
+		lines = append(lines, DiffLine{Kind: DiffRemoved, OldLine: offset + i + 1, Text: line})
+	}
+
+	counter = make(map[string]int)
+	for _, line := range a {
+		counter[line]++
+	}
+	for i, line := range b {
+		if counter[line] > 0 {
+			counter[line]--
+			continue
+		}
+		lines = append(lines, DiffLine{Kind: DiffAdded, NewLine: offset + i + 1, Text: line})
+	}
+	return lines
+}
+
+// UnifiedDiff render the diff as unified hunks with the number of context lines around the changes
+func UnifiedDiff(lines []DiffLine, context int) string {
+	var changes []int
+	for i, line := range lines {
+		if line.Kind != DiffEqual {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(changes); {
+		start := changes[i] - context
+		if start < 0 {
+			start = 0
+		}
+		last := changes[i]
+		for i < len(changes) && changes[i]-last <= 2*context+1 {
+			last = changes[i]
+			i++
+		}
+		end := last + context + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		oldStart, newStart, oldCount, newCount := 0, 0, 0, 0
+		for _, line := range lines[:start] {
+			if line.Kind != DiffAdded {
+				oldStart++
+			}
+			if line.Kind != DiffRemoved {
+				newStart++
+			}
+		}
+		var body strings.Builder
+		for _, line := range lines[start:end] {
+			if line.Kind != DiffAdded {
+				oldCount++
+			}
+			if line.Kind != DiffRemoved {
+				newCount++
+			}
+			body.WriteString(line.Kind + line.Text + "\n")
+		}
+		if oldCount > 0 {
+			oldStart++
+		}
+		if newCount > 0 {
+			newStart++
+		}
+		out.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount))
+		out.WriteString(body.String())
+	}
+	return out.String()
+}
@@ -18,6 +18,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 
 	//"syscall"
 	"text/template"
@@ -56,6 +58,27 @@ func CalcTimeout(raw string) int {
 	return timeout * multiply
 }
 
+// ParseTime parse a date or a relative time like 7d, 12h (meaning 7 days ago, 12 hours ago)
+func ParseTime(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	layouts := []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, raw, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	if strings.HasSuffix(raw, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(raw, "d")); err == nil {
+			return time.Now().AddDate(0, 0, -days), nil
+		}
+	}
+	if seconds := CalcTimeout(raw); seconds > 0 {
+		return time.Now().Add(-time.Duration(seconds) * time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time format: %v", raw)
+}
+
 // GetDomain get domain from the URL
 func GetDomain(raw string) (string, error) {
 	u, err := url.Parse(raw)
@@ -373,7 +396,7 @@ func RandomString(n int) string {
 }
 
 // runCmdWithOutput just run os command
-func runCmdWithOutput(cmd string) string {
+func runCmdWithOutput(cmd string, group *ProcessGroup) string {
 	DebugF("Execute: %s", cmd)
 	command := []string{
 		"bash",
@@ -382,14 +405,20 @@ func runCmdWithOutput(cmd string) string {
 	}
 	realCmd := exec.Command(command[0], command[1:]...)
 	// output command output to std too
-	output, _ := realCmd.CombinedOutput()
-	return string(output)
+	var output bytes.Buffer
+	realCmd.Stdout = &output
+	realCmd.Stderr = &output
+	if err := group.start(realCmd); err != nil {
+		return output.String()
+	}
+	group.wait(realCmd)
+	return output.String()
 }
 
 // RunCmdWithOutput run command with timeout
 func RunCmdWithOutput(command string, timeoutRaw ...string) string {
 	if len(timeoutRaw) == 0 {
-		return runCmdWithOutput(command)
+		return runCmdWithOutput(command, nil)
 	}
 
 	timeout := CalcTimeout(timeoutRaw[0])
@@ -479,6 +508,10 @@ func RunCommandWithErr(command string, timeoutRaw ...string) (string, error) {
 }
 
 func RunCommandSteamOutput(cmd string) (string, error) {
+	return runCommandSteamOutput(cmd, nil)
+}
+
+func runCommandSteamOutput(cmd string, group *ProcessGroup) (string, error) {
 	DebugF("Execute: %s", cmd)
 	command := []string{
 		"bash",
@@ -505,16 +538,25 @@ func RunCommandSteamOutput(cmd string) (string, error) {
 			DebugF(errScanner.Text())
 		}
 	}()
-	if err := realCmd.Start(); err != nil {
+	if err := group.start(realCmd); err != nil {
 		return output, err
 	}
-	if err := realCmd.Wait(); err != nil {
+	if err := group.wait(realCmd); err != nil {
 		return output, err
 	}
 	return output, nil
 }
 
 func RunOSCommand(cmd string) (string, error) {
+	return runOSCommand(cmd, nil, nil)
+}
+
+// RunOSCommandEnv same as RunOSCommand but the command gets the extra environment variables in the form of key=value
+func RunOSCommandEnv(cmd string, env []string) (string, error) {
+	return runOSCommand(cmd, env, nil)
+}
+
+func runOSCommand(cmd string, env []string, group *ProcessGroup) (string, error) {
 	DebugF("Execute: %s", cmd)
 	command := []string{
 		"bash",
@@ -523,34 +565,49 @@ func RunOSCommand(cmd string) (string, error) {
 	}
 	var output string
 	realCmd := exec.Command(command[0], command[1:]...)
+	if len(env) > 0 {
+		realCmd.Env = append(os.Environ(), env...)
+	}
 
 	// output command output to std too
 	cmdReader, _ := realCmd.StdoutPipe()
 	errReader, _ := realCmd.StderrPipe()
 	scanner := bufio.NewScanner(cmdReader)
 	errScanner := bufio.NewScanner(errReader)
+	var readers sync.WaitGroup
+	readers.Add(2)
 	go func() {
+		defer readers.Done()
 		for scanner.Scan() {
 			out := scanner.Text()
 			DebugF(out)
+			group.emit(out)
 			output += out
 		}
 	}()
 	go func() {
+		defer readers.Done()
 		for errScanner.Scan() {
 			DebugF(errScanner.Text())
+			group.emit(errScanner.Text())
 		}
 	}()
-	if err := realCmd.Start(); err != nil {
+	if err := group.start(realCmd); err != nil {
 		return output, err
 	}
-	if err := realCmd.Wait(); err != nil {
+	// Wait closes the pipes so every line has to be read before it
+	readers.Wait()
+	if err := group.wait(realCmd); err != nil {
 		return output, err
 	}
 	return output, nil
 }
 
 func RunOSCommandStream(cmd, std string) error {
+	return runOSCommandStream(cmd, std, nil)
+}
+
+func runOSCommandStream(cmd, std string, group *ProcessGroup) error {
 	DebugF("Execute: %s", cmd)
 	command := []string{
 		"bash",
@@ -564,17 +621,33 @@ func RunOSCommandStream(cmd, std string) error {
 	// output command output to std too
 	realCmd.Stdout = file
 	realCmd.Stderr = file
-	if err := realCmd.Start(); err != nil {
+	if err := group.start(realCmd); err != nil {
 		return err
 	}
-	if err := realCmd.Wait(); err != nil {
+	if err := group.wait(realCmd); err != nil {
 		return err
 	}
 	return nil
 }
 
+// IsProcessAlive check if the process is still running on this machine
+func IsProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
+
 // RunCommandWithoutOutput Run a command
 func RunCommandWithoutOutput(cmd string) error {
+	return runCommandWithoutOutput(cmd, nil)
+}
+
+func runCommandWithoutOutput(cmd string, group *ProcessGroup) error {
 	command := []string{
 		"bash",
 		"-c",
@@ -586,25 +659,40 @@ func RunCommandWithoutOutput(cmd string) error {
 	errReader, _ := realCmd.StderrPipe()
 	scanner := bufio.NewScanner(cmdReader)
 	errScanner := bufio.NewScanner(errReader)
+	var readers sync.WaitGroup
+	readers.Add(2)
 	go func() {
+		defer readers.Done()
 		for scanner.Scan() {
 			InforF(scanner.Text())
+			group.emit(scanner.Text())
 		}
 	}()
 	go func() {
+		defer readers.Done()
 		for errScanner.Scan() {
 			DebugF(errScanner.Text())
+			group.emit(errScanner.Text())
 		}
 	}()
-	if err := realCmd.Start(); err != nil {
+	if err := group.start(realCmd); err != nil {
 		return err
 	}
-	if err := realCmd.Wait(); err != nil {
+	// Wait closes the pipes so every line has to be read before it
+	readers.Wait()
+	if err := group.wait(realCmd); err != nil {
 		return err
 	}
 	return nil
 }
 
+var safeNameRegex = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// IsSafeName the name only has letters, digits, dots, underscores and dashes so it's safe as a folder name and in a command
+func IsSafeName(raw string) bool {
+	return safeNameRegex.MatchString(raw) && raw != "." && raw != ".."
+}
+
 // StripPath just Base64 Encode
 func StripPath(raw string) string {
 	raw = NormalizePath(raw)
@@ -753,6 +841,7 @@ func ImageAsBase64(src string) string {
 		ErrorF("File not found: %v", src)
 		return ""
 	}
+	defer f.Close()
 
 	// Read entire JPG into byte slice.
 	reader := bufio.NewReader(f)
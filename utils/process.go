@@ -0,0 +1,204 @@
+package utils
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+
+	gops "github.com/mitchellh/go-ps"
+)
+
+// ErrProcessGroupKilled the command is not started since the scan has been cancelled
+var ErrProcessGroupKilled = errors.New("the scan has been cancelled")
+
+// ProcessGroup track the commands started by the same scan
+// so the whole process tree of them could be stopped, continued or killed together
+type ProcessGroup struct {
+	mu     sync.Mutex
+	pids   map[int]struct{}
+	paused bool
+	killed bool
+	// called with every output line of the commands
+	output func(line string)
+}
+
+// NewProcessGroup create an empty process group
+func NewProcessGroup() *ProcessGroup {
+	return &ProcessGroup{pids: make(map[int]struct{})}
+}
+
+// start the command and keep track of it, the command is stopped right away if the group is paused
+// a nil group just starts the command
+func (g *ProcessGroup) start(realCmd *exec.Cmd) error {
+	if g == nil {
+		return realCmd.Start()
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.killed {
+		return ErrProcessGroupKilled
+	}
+	if err := realCmd.Start(); err != nil {
+		return err
+	}
+	pid := realCmd.Process.Pid
+	g.pids[pid] = struct{}{}
+	if g.paused {
+		SignalProcessTree(pid, syscall.SIGSTOP)
+	}
+	return nil
+}
+
+// wait for the command and stop tracking it
+func (g *ProcessGroup) wait(realCmd *exec.Cmd) error {
+	err := realCmd.Wait()
+	if g != nil {
+		g.mu.Lock()
+		delete(g.pids, realCmd.Process.Pid)
+		g.mu.Unlock()
+	}
+	return err
+}
+
+func (g *ProcessGroup) signal(sig syscall.Signal) {
+	for pid := range g.pids {
+		SignalProcessTree(pid, sig)
+	}
+}
+
+// Pause stop all the running commands
+func (g *ProcessGroup) Pause() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.paused = true
+	g.signal(syscall.SIGSTOP)
+}
+
+// Resume continue all the stopped commands
+func (g *ProcessGroup) Resume() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.paused = false
+	g.signal(syscall.SIGCONT)
+}
+
+// Kill kill all the running commands, no command could be started after that
+func (g *ProcessGroup) Kill() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.killed = true
+	g.signal(syscall.SIGKILL)
+}
+
+// IsPaused the group is paused, a nil group is never paused
+func (g *ProcessGroup) IsPaused() bool {
+	if g == nil {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.paused
+}
+
+// IsKilled the group has been killed, a nil group is never killed
+func (g *ProcessGroup) IsKilled() bool {
+	if g == nil {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.killed
+}
+
+// SetOutput receive every stdout and stderr line of the commands of the group
+func (g *ProcessGroup) SetOutput(output func(line string)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.output = output
+}
+
+// emit send the output line to the output function, a nil group ignores it
+func (g *ProcessGroup) emit(line string) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	output := g.output
+	g.mu.Unlock()
+	if output != nil && strings.TrimSpace(line) != "" {
+		output(line)
+	}
+}
+
+// Len number of the running commands
+func (g *ProcessGroup) Len() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.pids)
+}
+
+// RunOSCommand same as RunOSCommand but the command belongs to the group
+func (g *ProcessGroup) RunOSCommand(cmd string) (string, error) {
+	return runOSCommand(cmd, nil, g)
+}
+
+// RunOSCommandEnv same as RunOSCommandEnv but the command belongs to the group
+func (g *ProcessGroup) RunOSCommandEnv(cmd string, env []string) (string, error) {
+	return runOSCommand(cmd, env, g)
+}
+
+// RunOSCommandStream same as RunOSCommandStream but the command belongs to the group
+func (g *ProcessGroup) RunOSCommandStream(cmd, std string) error {
+	return runOSCommandStream(cmd, std, g)
+}
+
+// RunCommandWithoutOutput same as RunCommandWithoutOutput but the command belongs to the group
+func (g *ProcessGroup) RunCommandWithoutOutput(cmd string) error {
+	return runCommandWithoutOutput(cmd, g)
+}
+
+// RunCmdWithOutput same as RunCmdWithOutput without the timeout but the command belongs to the group
+func (g *ProcessGroup) RunCmdWithOutput(cmd string) string {
+	return runCmdWithOutput(cmd, g)
+}
+
+// RunCommandSteamOutput same as RunCommandSteamOutput but the command belongs to the group
+func (g *ProcessGroup) RunCommandSteamOutput(cmd string) (string, error) {
+	return runCommandSteamOutput(cmd, g)
+}
+
+// ProcessTree the process and all of its descendants, the parent always comes before its children
+func ProcessTree(pid int) []int {
+	tree := []int{pid}
+	processes, err := gops.Processes()
+	if err != nil {
+		return tree
+	}
+	children := make(map[int][]int)
+	for _, ps := range processes {
+		children[ps.PPid()] = append(children[ps.PPid()], ps.Pid())
+	}
+	for i := 0; i < len(tree); i++ {
+		tree = append(tree, children[tree[i]]...)
+	}
+	return tree
+}
+
+// SignalProcessTree send the signal to the process and all of its descendants
+func SignalProcessTree(pid int, sig syscall.Signal) {
+	if pid <= 0 {
+		return
+	}
+	tree := ProcessTree(pid)
+	// stop the whole tree first so the parents can't spawn or reap anything in the meantime
+	if sig == syscall.SIGKILL {
+		for _, child := range tree {
+			syscall.Kill(child, syscall.SIGSTOP)
+		}
+	}
+	for _, child := range tree {
+		syscall.Kill(child, sig)
+	}
+}